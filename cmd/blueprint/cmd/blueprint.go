@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
 	"github.com/xebialabs/blueprint-cli/pkg/models"
 	"github.com/xebialabs/blueprint-cli/pkg/util"
 	"github.com/xebialabs/blueprint-cli/pkg/xl"
+	"golang.org/x/term"
 )
 
+// exitCodeMissingAnswers is the exit code when parameters have no answer in non-interactive mode
+const exitCodeMissingAnswers = 3
+
 var blueprintCmd = &cobra.Command{
 	Use:   "blueprint",
 	Short: "(default) Create a Blueprint",
@@ -28,6 +38,13 @@ var blueprintCmd = &cobra.Command{
 
 var localRepoPath string
 var params = blueprint.BlueprintParams{}
+var setAnswers []string
+var setFileAnswers []string
+var saveAnswersFile string
+var includeSecretAnswers bool
+var outputFormat string
+var stdoutOutput bool
+var nonInteractive bool
 
 // DoBlueprint creates blueprint templates
 func DoBlueprint(context *xl.Context) {
@@ -41,12 +58,133 @@ func DoBlueprint(context *xl.Context) {
 		}
 	}
 
+	if len(setAnswers) > 0 || len(setFileAnswers) > 0 {
+		params.AnswersMap, err = blueprint.GetValuesFromSetFlags(setAnswers, setFileAnswers)
+		if err != nil {
+			util.Fatal("Error while reading answers: %s\n", err)
+		}
+	}
+
+	params.NonInteractive = isNonInteractive(nonInteractive)
+	// the YAML stream is not an output filesystem, it is handled before the output format is validated
+	if stdoutOutput || outputFormat == blueprint.OutputFormatStdout {
+		// keep stdout clean for the output, questions are asked on stderr
+		util.IsQuiet = true
+		preparedData, err := blueprint.WriteBlueprintStream(os.Stdout, params, blueprintContext, nil, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))
+		if err != nil {
+			exitOnMissingAnswers("creating Blueprint", err)
+			util.Fatal("Error while creating Blueprint: %s\n", err)
+		}
+		saveAnswers(preparedData)
+		return
+	}
+	if err := blueprint.ValidateOutputFormat(outputFormat); err != nil {
+		util.Fatal("Error while creating Blueprint: %s\n", err)
+	}
+
 	generatedBlueprint := &blueprint.GeneratedBlueprint{OutputDir: models.BlueprintOutputDir}
-	_, _, err = blueprint.InstantiateBlueprint(params, blueprintContext, generatedBlueprint, nil)
+	var archiveFS *blueprint.ArchiveFS
+	var archiveFile *os.File
+	var surveyOpts []survey.AskOpt
+	if outputFormat != "" && outputFormat != blueprint.OutputFormatDir {
+		// files are kept in memory and written at once to the archive file, or to stdout when no output is given
+		var out io.Writer = os.Stdout
+		if params.OutputRoot != "" {
+			archiveFile, err = os.Create(params.OutputRoot)
+			if err != nil {
+				util.Fatal("Error while creating Blueprint: %s\n", err)
+			}
+			out = archiveFile
+		} else {
+			// keep stdout clean for the output, questions are asked on stderr
+			util.IsQuiet = true
+			surveyOpts = append(surveyOpts, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))
+		}
+		params.OutputRoot = ""
+		archiveFS, err = blueprint.NewArchiveFS(outputFormat, out)
+		if err != nil {
+			util.Fatal("Error while creating Blueprint: %s\n", err)
+		}
+		generatedBlueprint.FS = archiveFS
+	}
+
+	preparedData, _, err := blueprint.InstantiateBlueprint(params, blueprintContext, generatedBlueprint, nil, surveyOpts...)
 	if err != nil {
-		generatedBlueprint.Cleanup() // Cleanup the partially generated blueprint
+		// output files are staged and rolled back on failure, so there is nothing to clean up in the output tree
+		if archiveFile != nil {
+			archiveFile.Close()
+			os.Remove(archiveFile.Name())
+		}
+		exitOnMissingAnswers("creating Blueprint", err)
 		util.Fatal("Error while creating Blueprint: %s\n", err)
 	}
+	if archiveFS != nil {
+		err = archiveFS.Close()
+		if archiveFile != nil {
+			if closeErr := archiveFile.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			util.Fatal("Error while writing Blueprint output: %s\n", err)
+		}
+	}
+	saveAnswers(preparedData)
+}
+
+// saveAnswers saves the given answers to the save-answers file if set
+func saveAnswers(preparedData *blueprint.PreparedData) {
+	if saveAnswersFile != "" {
+		if err := blueprint.SaveAnswersFile(saveAnswersFile, preparedData, includeSecretAnswers); err != nil {
+			util.Fatal("Error while saving answers: %s\n", err)
+		}
+		util.Info("Answers are saved to file %s\n", saveAnswersFile)
+	}
+}
+
+// isNonInteractive returns whether nothing can be asked, because the flag is set or stdin is not a terminal
+func isNonInteractive(flag bool) bool {
+	return flag || !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// applyOutputFormat exits on an unknown output format. Structured formats are meant to be parsed by other tools,
+// so informational messages are kept out of the output for them
+func applyOutputFormat(format string) {
+	if err := util.ValidateOutputFormat(format); err != nil {
+		util.Fatal("%s\n", err)
+	}
+	if util.IsStructuredOutputFormat(format) {
+		util.IsQuiet = true
+	}
+}
+
+// localBlueprintContext returns the context of the local repository when a local repository path is given, otherwise
+// the given context. When no template path is given and the local path is a single blueprint directory, the parent
+// directory is used as repository and that blueprint is selected
+func localBlueprintContext(blueprintContext *blueprint.BlueprintContext, localRepoPath string, templatePath string) (*blueprint.BlueprintContext, string) {
+	if localRepoPath == "" {
+		return blueprintContext, templatePath
+	}
+	repoPath := localRepoPath
+	if templatePath == "" {
+		if parentPath, blueprintPath, ok := blueprint.SplitLocalBlueprintDir(localRepoPath); ok {
+			repoPath, templatePath = parentPath, blueprintPath
+		}
+	}
+	localContext, err := blueprint.ConstructLocalBlueprintContext(repoPath)
+	if err != nil {
+		util.Fatal("Error creating local blueprint context: %s\n", err)
+	}
+	return localContext, templatePath
+}
+
+// exitOnMissingAnswers exits with exitCodeMissingAnswers when parameters have no answer in non-interactive mode
+func exitOnMissingAnswers(action string, err error) {
+	var missingAnswersErr *blueprint.MissingAnswersError
+	if errors.As(err, &missingAnswersErr) {
+		util.Error(util.FatalColor("Error while %s: %s\n"), action, err)
+		os.Exit(exitCodeMissingAnswers)
+	}
 }
 
 func init() {
@@ -56,6 +194,17 @@ func init() {
 	blueprintFlags.StringVarP(&params.TemplatePath, "blueprint", "b", "", "Blueprint path to use, relative to the active repository")
 	blueprintFlags.StringVarP(&localRepoPath, "local-repo", "l", "", "Local repository directory to use (bypasses active repository)")
 	blueprintFlags.StringVarP(&params.AnswersFile, "answers", "a", "", "The file containing answers for blueprint questions")
+	blueprintFlags.StringArrayVar(&setAnswers, "set", []string{}, "Answer for a blueprint question as key=value, can be repeated and takes precedence over the answers file")
+	blueprintFlags.StringVar(&params.AnswersEnvPrefix, "answers-env-prefix", blueprint.DefaultAnswersEnvPrefix, "Prefix of the environment variables holding answers for blueprint questions, as in XL_BP_ANSWER_AppName=my-app, set it to empty to ignore environment variables")
+	blueprintFlags.StringArrayVar(&setFileAnswers, "set-file", []string{}, "Answer for a blueprint question read from a file as key=path, can be repeated and takes precedence over the answers file")
+	blueprintFlags.StringVar(&saveAnswersFile, "save-answers", "", "The file to save the given answers in, to be used later with the answers flag")
+	blueprintFlags.BoolVar(&includeSecretAnswers, "include-secrets", false, "If flag is set, answers for secret parameters are also saved in the save-answers file")
 	blueprintFlags.BoolVarP(&params.StrictAnswers, "strict-answers", "s", false, "If flag is set, answers file will be expected to have all the variable values")
 	blueprintFlags.BoolVarP(&params.UseDefaultsAsValue, "use-defaults", "d", false, "If flag is set, default values for variables will be treated as value fields")
+	blueprintFlags.StringVarP(&params.OutputRoot, "output", "o", "", "Directory to generate the blueprint files in, defaults to the current working directory. With zip or tar.gz output format, the archive file to write, defaults to stdout")
+	blueprintFlags.StringVar(&outputFormat, "output-format", blueprint.OutputFormatDir, "Format to generate the blueprint files in, one of: "+strings.Join(blueprint.OutputFormats, ", ")+", or "+blueprint.OutputFormatStdout+" which is the same as the stdout flag")
+	blueprintFlags.BoolVar(&stdoutOutput, "stdout", false, "If flag is set, the rendered YAML files are written to stdout as one multi-document YAML stream instead of to disk, same as --output-format stdout")
+	blueprintFlags.StringVar(&params.OnConflict, "on-conflict", blueprint.OnConflictOverwrite, "What to do with output files that already exist, one of: "+strings.Join(blueprint.OnConflictPolicies, ", "))
+	blueprintFlags.BoolVar(&params.DryRun, "dry-run", false, "If flag is set, the files that would be created, overwritten, renamed or skipped are reported without writing anything")
+	blueprintFlags.BoolVar(&nonInteractive, "non-interactive", false, "If flag is set, nothing is asked and all parameters without an answer are reported at once, this is the default when stdin is not a terminal")
 }
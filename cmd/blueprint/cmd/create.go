@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create DIR",
+	Short: "Create a new blueprint",
+	Long: `Create a new xl/v2 blueprint in the given directory, which must not exist or be empty.
+The blueprint definition, sample template files, an override-defaults.yaml file and a test case with its answers file
+are created. Values not given with flags are asked, unless --non-interactive is set or the input is not a terminal.
+Parameters are given as NAME[:TYPE[:OPTION,OPTION...]], sample parameters are used when none are given`,
+	Example: `  xl-blueprint create ./my-blueprint
+  xl-blueprint create ./my-blueprint --name "My Blueprint" -p AppName -p Region:Select:eu-west-1,us-east-1 -p Public:Confirm`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		DoCreate(args[0])
+	},
+}
+
+var createScaffold blueprint.BlueprintScaffold
+var createParameters []string
+var createNonInteractive bool
+
+// DoCreate creates a new blueprint in the given directory
+func DoCreate(dir string) {
+	scaffold := createScaffold
+	for _, value := range createParameters {
+		parameter, err := blueprint.ParseScaffoldParameter(value)
+		if err != nil {
+			util.Fatal("Error while creating blueprint: %s\n", err)
+		}
+		scaffold.Parameters = append(scaffold.Parameters, parameter)
+	}
+
+	if !isNonInteractive(createNonInteractive) {
+		if err := blueprint.AskBlueprintScaffold(blueprint.NewSurveyAnswerProvider(), &scaffold); err != nil {
+			util.Fatal("Error while creating blueprint: %s\n", err)
+		}
+	}
+	if scaffold.Name == "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			util.Fatal("Error while creating blueprint: %s\n", err)
+		}
+		scaffold.Name = filepath.Base(absDir)
+	}
+
+	files, err := blueprint.CreateBlueprint(dir, scaffold)
+	if err != nil {
+		util.Fatal("Error while creating blueprint: %s\n", err)
+	}
+	for _, file := range files {
+		util.Info("Created %s\n", file)
+	}
+	util.Info("%s", util.Green("\nBlueprint created successfully, run its test case with: xl-blueprint test -l "+dir+"\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+
+	createFlags := createCmd.Flags()
+	createFlags.StringVar(&createScaffold.Name, "name", "", "Name of the blueprint (default: the name of the directory)")
+	createFlags.StringVar(&createScaffold.Description, "description", "", "Description of the blueprint")
+	createFlags.StringVar(&createScaffold.Author, "author", "", "Author of the blueprint")
+	createFlags.StringVar(&createScaffold.Version, "version", "1.0.0", "Version of the blueprint")
+	createFlags.StringArrayVarP(&createParameters, "parameter", "p", []string{}, "Parameter of the blueprint as NAME[:TYPE[:OPTION,OPTION...]], can be given multiple times")
+	createFlags.BoolVar(&createNonInteractive, "non-interactive", false, "Do not ask for values that are not given with flags")
+}
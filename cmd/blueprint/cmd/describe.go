@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe the parameters and files of a blueprint",
+	Long: `Describe the parameters a blueprint will ask and the files it will write, without generating anything.
+Included blueprints are resolved and the composed blueprint each parameter and file comes from is shown`,
+	Example: `  xl-blueprint describe -b aws/monolith
+  xl-blueprint describe -l ./my-repository -b aws/monolith -f json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		DoDescribe(context)
+	},
+}
+
+var describeTemplatePath string
+var describeLocalRepoPath string
+var describeOutputFormat string
+
+// DoDescribe prints the parameter and file contract of the selected blueprint
+func DoDescribe(context *xl.Context) {
+	applyOutputFormat(describeOutputFormat)
+
+	blueprintContext, templatePath := localBlueprintContext(context.BlueprintContext, describeLocalRepoPath, describeTemplatePath)
+	if templatePath == "" {
+		util.Fatal("Blueprint path is required, please provide it with the -b flag\n")
+	}
+
+	description, err := blueprintContext.DescribeBlueprint(templatePath)
+	if err != nil {
+		util.Fatal("Error while describing blueprint: %s\n", err)
+	}
+
+	err = util.WriteFormatted(os.Stdout, describeOutputFormat, description, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Blueprint:\t%s\n", description.Path)
+		fmt.Fprintf(tw, "Name:\t%s\n", description.Name)
+		fmt.Fprintf(tw, "Description:\t%s\n", util.TableCell(description.Description, 120))
+		fmt.Fprintf(tw, "Author:\t%s\n", description.Author)
+		fmt.Fprintf(tw, "Version:\t%s\n", description.Version)
+		fmt.Fprintf(tw, "Composed of:\t%s\n", strings.Join(description.ComposedBlueprints, ", "))
+
+		fmt.Fprintln(tw, "\nPARAMETERS")
+		fmt.Fprintln(tw, "NAME\tTYPE\tVALUE\tDEFAULT\tOPTIONS\tVALIDATE\tPROMPT IF\tBLUEPRINT")
+		for _, parameter := range description.Parameters {
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				parameter.Name, parameter.Type, util.TableCell(parameter.Value, 30), util.TableCell(parameter.Default, 30),
+				util.TableCell(strings.Join(parameter.Options, ", "), 40), util.TableCell(parameter.Validate, 40),
+				util.TableCell(parameter.PromptIf, 30), parameter.Blueprint,
+			)
+		}
+
+		fmt.Fprintln(tw, "\nFILES")
+		fmt.Fprintln(tw, "PATH\tWRITE IF\tRENAME TO\tBLUEPRINT")
+		for _, file := range description.Files {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", file.Path, util.TableCell(file.WriteIf, 40), file.RenameTo, file.Blueprint)
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing blueprint description: %s\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+
+	describeFlags := describeCmd.Flags()
+	describeFlags.StringVarP(&describeTemplatePath, "blueprint", "b", "", "The blueprint to describe, relative to the repository")
+	describeFlags.StringVarP(&describeLocalRepoPath, "local-repo", "l", "", "Local repository or blueprint directory to use (bypasses defined repositories)")
+	describeFlags.StringVarP(&describeOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+}
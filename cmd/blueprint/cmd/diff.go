@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the current project with a fresh rendering of a blueprint",
+	Long: `Render the blueprint in memory and print a unified diff against the files currently on disk, without writing anything.
+Files that would be added are listed along with files that were generated before, according to the blueprint manifest,
+but are not produced by the blueprint anymore`,
+	Example: `  xl-blueprint diff -b aws/monolith -a answers.yaml
+  xl-blueprint diff -l ./my-repository -b my-blueprint -a answers.yaml -o ./my-project`,
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		DoDiff(context)
+	},
+}
+
+var diffLocalRepoPath string
+var diffParams = blueprint.BlueprintParams{}
+var diffSetAnswers []string
+var diffSetFileAnswers []string
+var diffNonInteractive bool
+
+// DoDiff prints the difference between the project files and a fresh rendering of the blueprint
+func DoDiff(context *xl.Context) {
+	var err error
+	blueprintContext := context.BlueprintContext
+	if diffLocalRepoPath != "" {
+		blueprintContext, err = blueprint.ConstructLocalBlueprintContext(diffLocalRepoPath)
+		if err != nil {
+			util.Fatal("Error creating local blueprint context: %s\n", err)
+		}
+	}
+
+	if len(diffSetAnswers) > 0 || len(diffSetFileAnswers) > 0 {
+		diffParams.AnswersMap, err = blueprint.GetValuesFromSetFlags(diffSetAnswers, diffSetFileAnswers)
+		if err != nil {
+			util.Fatal("Error while reading answers: %s\n", err)
+		}
+	}
+
+	diffParams.NonInteractive = isNonInteractive(diffNonInteractive)
+	blueprintDiff, err := blueprint.DiffBlueprint(diffParams, blueprintContext, nil)
+	if err != nil {
+		exitOnMissingAnswers("comparing blueprint", err)
+		util.Fatal("Error while comparing blueprint: %s\n", err)
+	}
+
+	if !blueprintDiff.HasChanges() {
+		util.Print("%s", util.Green("No differences with blueprint ["+blueprintDiff.Blueprint+"]\n"))
+		return
+	}
+	if err := blueprintDiff.WriteDiff(os.Stdout); err != nil {
+		util.Fatal("Error while printing diff: %s\n", err)
+	}
+	util.Print("\n")
+	err = util.WriteFormatted(os.Stdout, util.OutputFormatTable, blueprintDiff, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "STATUS\tPATH")
+		for _, file := range blueprintDiff.Files {
+			if file.Status != blueprint.DiffStatusUnchanged {
+				fmt.Fprintf(tw, "%s\t%s\n", file.Status, file.Path)
+			}
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing diff: %s\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffFlags := diffCmd.Flags()
+	diffFlags.StringVarP(&diffParams.TemplatePath, "blueprint", "b", "", "Blueprint path to use, relative to the active repository")
+	diffFlags.StringVarP(&diffLocalRepoPath, "local-repo", "l", "", "Local repository directory to use (bypasses active repository)")
+	diffFlags.StringVarP(&diffParams.AnswersFile, "answers", "a", "", "The file containing answers for blueprint questions")
+	diffFlags.StringArrayVar(&diffSetAnswers, "set", []string{}, "Answer for a blueprint question as key=value, can be repeated and takes precedence over the answers file")
+	diffFlags.StringVar(&diffParams.AnswersEnvPrefix, "answers-env-prefix", blueprint.DefaultAnswersEnvPrefix, "Prefix of the environment variables holding answers for blueprint questions, as in XL_BP_ANSWER_AppName=my-app, set it to empty to ignore environment variables")
+	diffFlags.StringArrayVar(&diffSetFileAnswers, "set-file", []string{}, "Answer for a blueprint question read from a file as key=path, can be repeated and takes precedence over the answers file")
+	diffFlags.BoolVarP(&diffParams.StrictAnswers, "strict-answers", "s", false, "If flag is set, answers file will be expected to have all the variable values")
+	diffFlags.BoolVarP(&diffParams.UseDefaultsAsValue, "use-defaults", "d", false, "If flag is set, default values for variables will be treated as value fields")
+	diffFlags.StringVarP(&diffParams.OutputRoot, "output", "o", "", "Directory of the project to compare with, defaults to the current working directory")
+	diffFlags.BoolVar(&diffNonInteractive, "non-interactive", false, "If flag is set, nothing is asked and all parameters without an answer are reported at once, this is the default when stdin is not a terminal")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract DIR",
+	Short: "Create a blueprint from an existing project",
+	Long: `Create a blueprint from the files of an existing project directory.
+Every literal given with --literal is replaced as a whole token by the placeholder of its parameter in .tmpl copies of the text files
+it is found in, all other files, binary files included, are copied untouched. The parameters are inferred from the values: a name given more than
+once becomes a Select parameter, a true or false value a Confirm parameter, only replaced where it is the whole value of a YAML key, and any other value an Input parameter`,
+	Example: `  xl-blueprint extract ./my-project --out ./my-repository/my-app --literal AppName=my-app --literal Env=dev --literal Env=prod`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		DoExtract(args[0])
+	},
+}
+
+var extractOptions blueprint.ExtractOptions
+var extractLiterals []string
+var extractOutputFormat string
+
+// DoExtract creates a blueprint from the project in the given directory
+func DoExtract(dir string) {
+	applyOutputFormat(extractOutputFormat)
+
+	var err error
+	options := extractOptions
+	options.SourceDir = dir
+	if options.Literals, err = blueprint.ParseExtractLiterals(extractLiterals); err != nil {
+		util.Fatal("Error while extracting blueprint: %s\n", err)
+	}
+	if options.Name == "" {
+		absDir, err := filepath.Abs(options.OutputDir)
+		if err != nil {
+			util.Fatal("Error while extracting blueprint: %s\n", err)
+		}
+		options.Name = filepath.Base(absDir)
+	}
+
+	result, err := blueprint.ExtractBlueprint(options)
+	if err != nil {
+		util.Fatal("Error while extracting blueprint: %s\n", err)
+	}
+	err = util.WriteFormatted(os.Stdout, extractOutputFormat, result, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "KIND\tPATH\tREPLACEMENTS")
+		for _, file := range result.Files {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", file.Kind, file.Path, file.Replacements)
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing extract result: %s\n", err)
+	}
+	if len(result.UnusedLiterals) > 0 {
+		util.Info("%s", util.Yellow(fmt.Sprintf("\nLiterals not found in any file: %s\n", strings.Join(result.UnusedLiterals, ", "))))
+	}
+	util.Info("%s", util.Green(fmt.Sprintf("\nBlueprint extracted to %s\n", options.OutputDir)))
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+
+	extractFlags := extractCmd.Flags()
+	extractFlags.StringVarP(&extractOptions.OutputDir, "out", "o", "", "Directory to write the blueprint to, it must not exist or be empty")
+	extractFlags.StringArrayVarP(&extractLiterals, "literal", "L", []string{}, "Literal to replace as name=value, can be repeated and a name given more than once becomes a Select parameter")
+	extractFlags.StringVar(&extractOptions.Name, "name", "", "Name of the blueprint (default: the name of the output directory)")
+	extractFlags.StringSliceVar(&extractOptions.IgnoredDirs, "ignored-dirs", []string{".git"}, "Directories of the project that are not extracted")
+	extractFlags.StringVarP(&extractOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+	extractCmd.MarkFlagRequired("out")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List blueprints in the active repository",
+	Long:  "List blueprints in the active repository along with their name, description, author and version",
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		DoList(context)
+	},
+}
+
+var listRepoName string
+var listLocalRepoPath string
+var listOutputFormat string
+
+// DoList prints the blueprints of the selected repository
+func DoList(context *xl.Context) {
+	applyOutputFormat(listOutputFormat)
+
+	var err error
+	blueprintContext := context.BlueprintContext
+	if listLocalRepoPath != "" {
+		blueprintContext, err = blueprint.ConstructLocalBlueprintContext(listLocalRepoPath)
+		if err != nil {
+			util.Fatal("Error creating local blueprint context: %s\n", err)
+		}
+	} else if listRepoName != "" {
+		blueprintContext, err = blueprintContext.WithActiveRepo(listRepoName)
+		if err != nil {
+			util.Fatal("Error while selecting repository: %s\n", err)
+		}
+	}
+
+	infos, err := blueprintContext.ListBlueprints()
+	if err != nil {
+		util.Fatal("Error while listing blueprints: %s\n", err)
+	}
+
+	err = util.WriteFormatted(os.Stdout, listOutputFormat, infos, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "PATH\tNAME\tVERSION\tAUTHOR\tDESCRIPTION")
+		for _, info := range infos {
+			description := info.Description
+			if info.Error != "" {
+				description = "invalid blueprint: " + info.Error
+			}
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\t%s\n",
+				info.Path, util.TableCell(info.Name, 40), info.Version, util.TableCell(info.Author, 20), util.TableCell(description, 80),
+			)
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing blueprints: %s\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listFlags := listCmd.Flags()
+	listFlags.StringVarP(&listRepoName, "repo", "r", "", "Name of the defined repository to list (default: current repository)")
+	listFlags.StringVarP(&listLocalRepoPath, "local-repo", "l", "", "Local repository directory to list (bypasses defined repositories)")
+	listFlags.StringVarP(&listOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate FILE",
+	Short: "Migrate an xl/v1 blueprint definition to xl/v2",
+	Long: `Rewrite an xl/v1 blueprint definition file as an equivalent xl/v2 document, keeping comments and key order.
+The file is rewritten in place unless --output is given. Anything that could not be migrated automatically is reported
+and the command exits with a non-zero code, the reported parts have to be changed by hand`,
+	Example: `  xl-blueprint migrate ./my-blueprint/blueprint.yaml
+  xl-blueprint migrate ./my-blueprint/blueprint.yaml -o ./my-blueprint/blueprint-v2.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		DoMigrate(args[0])
+	},
+}
+
+var migrateOutputPath string
+
+// DoMigrate migrates the given xl/v1 blueprint definition file and exits with a non-zero code when parts could not be migrated
+func DoMigrate(definitionPath string) {
+	content, err := ioutil.ReadFile(definitionPath)
+	if err != nil {
+		util.Fatal("Error while reading blueprint definition: %s\n", err)
+	}
+	absPath, err := filepath.Abs(definitionPath)
+	if err != nil {
+		util.Fatal("Error while reading blueprint definition: %s\n", err)
+	}
+	migrated, problems, err := blueprint.MigrateBlueprintV1(content, filepath.Base(filepath.Dir(absPath)))
+	if err != nil {
+		util.Fatal("Error while migrating blueprint definition: %s\n", err)
+	}
+
+	outputPath := definitionPath
+	if migrateOutputPath != "" {
+		outputPath = migrateOutputPath
+	}
+	if err := ioutil.WriteFile(outputPath, migrated, 0640); err != nil {
+		util.Fatal("Error while writing migrated blueprint definition: %s\n", err)
+	}
+	util.Info("Migrated blueprint definition written to %s\n", outputPath)
+
+	if len(problems) > 0 {
+		util.Print("\nThe following parts could not be migrated automatically:\n\n")
+		err = util.WriteFormatted(os.Stdout, util.OutputFormatTable, problems, func(tw *tabwriter.Writer) {
+			fmt.Fprintln(tw, "LINE\tMESSAGE")
+			for _, problem := range problems {
+				line := "-"
+				if problem.Line > 0 {
+					line = fmt.Sprint(problem.Line)
+				}
+				fmt.Fprintf(tw, "%s\t%s\n", line, util.TableCell(problem.Message, 120))
+			}
+		})
+		if err != nil {
+			util.Fatal("Error while printing migration problems: %s\n", err)
+		}
+		os.Exit(1)
+	}
+	util.Info("%s", util.Green("Blueprint definition migrated successfully\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateFlags := migrateCmd.Flags()
+	migrateFlags.StringVarP(&migrateOutputPath, "output", "o", "", "File to write the migrated definition to (default: the given file is rewritten)")
+}
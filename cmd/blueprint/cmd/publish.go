@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish REPO_DIR",
+	Short: "Publish a local blueprint repository",
+	Long: `Validate the blueprints of a local repository and publish them to an output directory, which must not exist or be empty.
+The blueprint files are copied next to an index.json file listing the metadata, files and SHA-256 digests of every blueprint,
+so the output directory can be served as an HTTP repository. The same files are written to a blueprints-VERSION.zip archive
+that can be used as a zip repository. Nothing is published when a blueprint has validation errors`,
+	Example: `  xl-blueprint publish ./my-repository --out ./dist/blueprints/9.7.0
+  xl-blueprint publish ./my-repository --out ./dist --version 1.2.0 --ignored-files .DS_Store`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		DoPublish(args[0])
+	},
+}
+
+var publishOptions blueprint.PublishOptions
+var publishOutputFormat string
+
+// DoPublish publishes the blueprints of the local repository in the given directory
+func DoPublish(repoDir string) {
+	applyOutputFormat(publishOutputFormat)
+
+	options := publishOptions
+	options.RepoDir = repoDir
+	if options.Version == "" {
+		options.Version = CliVersion
+	}
+
+	result, err := blueprint.PublishBlueprints(options)
+	if result != nil && len(result.Problems) > 0 && !util.IsStructuredOutputFormat(publishOutputFormat) {
+		printErr := util.WriteFormatted(os.Stdout, publishOutputFormat, result.Problems, func(tw *tabwriter.Writer) {
+			fmt.Fprintln(tw, "SEVERITY\tBLUEPRINT\tFILE\tMESSAGE")
+			for _, problem := range result.Problems {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", problem.Severity, problem.Blueprint, problem.File, util.TableCell(problem.Message, 120))
+			}
+		})
+		if printErr != nil {
+			util.Fatal("Error while printing validation problems: %s\n", printErr)
+		}
+		util.Print("\n")
+	}
+	if err != nil {
+		if result != nil && util.IsStructuredOutputFormat(publishOutputFormat) {
+			// still report the validation problems in the requested format
+			util.WriteFormatted(os.Stdout, publishOutputFormat, result, nil)
+		}
+		util.Fatal("Error while publishing blueprints: %s\n", err)
+	}
+
+	err = util.WriteFormatted(os.Stdout, publishOutputFormat, result, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "PATH\tNAME\tVERSION\tFILES")
+		for _, entry := range result.Index.Blueprints {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", entry.Path, entry.Metadata.Name, entry.Metadata.Version, len(entry.Files))
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing publish result: %s\n", err)
+	}
+	util.Info("\nIndex written to %s\nArchive written to %s\n", result.IndexPath, result.ArchivePath)
+	util.Info("%s", util.Green(fmt.Sprintf("\nRepository version %s published to %s\n", result.Index.Version, options.OutputDir)))
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+
+	publishFlags := publishCmd.Flags()
+	publishFlags.StringVarP(&publishOptions.OutputDir, "out", "o", "", "Directory to publish the repository to, it must not exist or be empty")
+	publishFlags.StringVar(&publishOptions.Version, "version", "", "Version of the published repository, used in the archive name (default: the version of the CLI)")
+	publishFlags.StringSliceVar(&publishOptions.IgnoredDirs, "ignored-dirs", []string{".git"}, "Directories of the repository that are not published")
+	publishFlags.StringSliceVar(&publishOptions.IgnoredFiles, "ignored-files", []string{}, "Files of the repository that are not published")
+	publishFlags.StringVarP(&publishOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+	publishCmd.MarkFlagRequired("out")
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage blueprint repositories",
+	Long:  "Add, remove, switch, list and show the blueprint repositories defined in the configuration file",
+}
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a blueprint repository",
+	Long:  "Add a blueprint repository to the configuration file, provider specific fields are validated before saving",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repoDefinition := blueprint.ConfMap{"name": args[0], "type": repoType}
+		for _, field := range blueprint.RepositoryDefinitionFields {
+			if cmd.Flags().Changed(field) {
+				repoDefinition[field], _ = cmd.Flags().GetString(field)
+			}
+		}
+		updateRepoConfig(func(confData *blueprint.ConfData) error {
+			if err := confData.AddRepository(repoDefinition, CliVersion); err != nil {
+				return err
+			}
+			if repoUse {
+				return confData.UseRepository(args[0])
+			}
+			return nil
+		})
+		util.Info("Repository '%s' added\n", args[0])
+	},
+}
+
+var repoRemoveCmd = &cobra.Command{
+	Use:     "remove NAME",
+	Aliases: []string{"rm"},
+	Short:   "Remove a blueprint repository",
+	Long:    "Remove a blueprint repository from the configuration file",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateRepoConfig(func(confData *blueprint.ConfData) error {
+			return confData.RemoveRepository(args[0])
+		})
+		util.Info("Repository '%s' removed\n", args[0])
+	},
+}
+
+var repoUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Set the current blueprint repository",
+	Long:  "Set the current blueprint repository in the configuration file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateRepoConfig(func(confData *blueprint.ConfData) error {
+			return confData.UseRepository(args[0])
+		})
+		util.Info("Using repository '%s'\n", args[0])
+	},
+}
+
+var repoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List blueprint repositories",
+	Long:  "List the blueprint repositories defined in the configuration file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		confData := readRepoConfig()
+		repos := make([]repoListItem, 0, len(confData.Repositories))
+		for _, repoDefinition := range confData.Repositories {
+			repos = append(repos, repoListItem{
+				Name:    repoDefinition["name"],
+				Type:    repoDefinition["type"],
+				Current: repoDefinition["name"] == confData.CurrentRepo,
+			})
+		}
+		err := util.WriteFormatted(os.Stdout, repoOutputFormat, repos, func(tw *tabwriter.Writer) {
+			fmt.Fprintln(tw, "CURRENT\tNAME\tTYPE")
+			for _, repo := range repos {
+				current := ""
+				if repo.Current {
+					current = "*"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", current, repo.Name, repo.Type)
+			}
+		})
+		if err != nil {
+			util.Fatal("Error while printing repositories: %s\n", err)
+		}
+	},
+}
+
+var repoShowCmd = &cobra.Command{
+	Use:   "show [NAME]",
+	Short: "Show a blueprint repository definition",
+	Long:  "Show the definition of a blueprint repository, the current repository is shown when no name is given. Secret fields are masked",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		confData := readRepoConfig()
+		name := confData.CurrentRepo
+		if len(args) > 0 {
+			name = args[0]
+		}
+		repoDefinition, err := confData.GetRepository(name)
+		if err != nil {
+			util.Fatal("Error while reading repository: %s\n", err)
+		}
+		repoDefinition = blueprint.MaskSecretFields(repoDefinition)
+		err = util.WriteFormatted(os.Stdout, repoOutputFormat, repoDefinition, func(tw *tabwriter.Writer) {
+			var keys []string
+			for k := range repoDefinition {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(tw, "%s:\t%s\n", k, repoDefinition[k])
+			}
+		})
+		if err != nil {
+			util.Fatal("Error while printing repository: %s\n", err)
+		}
+	},
+}
+
+type repoListItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"`
+	Current bool   `json:"current" yaml:"current"`
+}
+
+var repoType string
+var repoUse bool
+var repoOutputFormat string
+
+func getRepoConfigPath() string {
+	if configPath := viper.ConfigFileUsed(); configPath != "" {
+		return configPath
+	}
+	configPath, err := util.DefaultConfigfilePath()
+	if err != nil {
+		util.Fatal("Could not get config file location:\n%s", err)
+	}
+	return configPath
+}
+
+func readRepoConfig() blueprint.ConfData {
+	applyOutputFormat(repoOutputFormat)
+	confData, err := blueprint.ReadBlueprintConfData(getRepoConfigPath())
+	if err != nil {
+		util.Fatal("Error while reading configuration: %s\n", err)
+	}
+	return confData
+}
+
+func updateRepoConfig(update func(confData *blueprint.ConfData) error) {
+	configPath := getRepoConfigPath()
+	confData, err := blueprint.ReadBlueprintConfData(configPath)
+	if err != nil {
+		util.Fatal("Error while reading configuration: %s\n", err)
+	}
+	if err := update(&confData); err != nil {
+		util.Fatal("Error while updating repositories: %s\n", err)
+	}
+	if err := blueprint.WriteBlueprintConfData(confData, configPath); err != nil {
+		util.Fatal("Error while writing configuration %s: %s\n", configPath, err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoAddCmd, repoRemoveCmd, repoUseCmd, repoListCmd, repoShowCmd)
+
+	addFlags := repoAddCmd.Flags()
+	addFlags.StringVarP(&repoType, "type", "t", "", "Repository provider type, one of: local, github, gitlab, bitbucket, bitbucketserver, http, zip")
+	addFlags.BoolVar(&repoUse, "use", false, "Set the added repository as the current repository")
+	for _, field := range blueprint.RepositoryDefinitionFields {
+		addFlags.String(field, "", fmt.Sprintf("Repository '%s' field, see repository type documentation for the required fields", field))
+	}
+	repoAddCmd.MarkFlagRequired("type")
+
+	repoListCmd.Flags().StringVarP(&repoOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+	repoShowCmd.Flags().StringVarP(&repoOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+}
@@ -31,10 +31,12 @@ var rootCmd = &cobra.Command{
 }
 
 func hasSubCommand(args []string) bool {
-	argsString := strings.Join(args, " ")
 	for _, cmd := range rootCmd.Commands() {
-		if strings.Contains(argsString, cmd.Use) {
-			return true
+		for _, arg := range args {
+			// compare whole arguments, so that flag values like blueprint paths are not matched as commands
+			if arg == cmd.Name() || cmd.HasAlias(arg) {
+				return true
+			}
 		}
 	}
 	return false
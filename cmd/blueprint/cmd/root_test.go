@@ -37,6 +37,16 @@ func Test_addDefaultCommandIfNeeded(t *testing.T) {
 			[]string{"xl-bp", "-v", "version", "-h"},
 			[]string{"xl-bp", "-v", "version", "-h"},
 		},
+		{
+			"get args as is when list subcommand is included with flags",
+			[]string{"xl-bp", "list", "-f", "json"},
+			[]string{"xl-bp", "list", "-f", "json"},
+		},
+		{
+			"get default when a flag value contains a subcommand name",
+			[]string{"xl-bp", "-b", "k8s/list-app"},
+			[]string{"xl-bp", "blueprint", "-b", "k8s/list-app"},
+		},
 		{
 			"get default when command with flags",
 			[]string{"xl-bp", "-v"},
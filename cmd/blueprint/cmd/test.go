@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run the test cases shipped with blueprints",
+	Long: `Run the __test__/test-case-*.yaml test cases shipped with blueprints.
+Each test case generates the blueprint in a temporary directory using its answers file in strict mode,
+and the expected files, values and secrets are verified. The command exits with a non-zero code when any test case fails`,
+	Example: `  xl-blueprint test -l ./my-repository -b aws/monolith
+  xl-blueprint test -l ./my-blueprint --junit-report report.xml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		DoTest(context)
+	},
+}
+
+var testTemplatePath string
+var testLocalRepoPath string
+var testJUnitReportPath string
+
+// DoTest runs the blueprint test cases and exits with a non-zero code on failures
+func DoTest(context *xl.Context) {
+	blueprintContext, templatePath := localBlueprintContext(context.BlueprintContext, testLocalRepoPath, testTemplatePath)
+
+	var templatePaths []string
+	if templatePath != "" {
+		templatePaths = append(templatePaths, templatePath)
+	}
+	results, err := blueprintContext.RunBlueprintTests(templatePaths...)
+	if err != nil {
+		util.Fatal("Error while running blueprint tests: %s\n", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		status := util.Green("PASS")
+		if !result.Passed() {
+			status = util.Red("FAIL")
+			failed++
+		}
+		util.Print("%s %s/%s (%.2fs)\n", status, result.Blueprint, result.TestCase, result.Duration.Seconds())
+		if result.Error != "" {
+			util.Print("    error: %s\n", result.Error)
+		}
+		for _, failure := range result.Failures {
+			util.Print("    %s\n", failure)
+		}
+	}
+	util.Print("\n%d test cases, %d passed, %d failed\n", len(results), len(results)-failed, failed)
+
+	if testJUnitReportPath != "" {
+		if err := writeJUnitReportFile(testJUnitReportPath, results); err != nil {
+			util.Fatal("Error while writing JUnit report: %s\n", err)
+		}
+		util.Verbose("JUnit report written to %s\n", testJUnitReportPath)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeJUnitReportFile(reportPath string, results []blueprint.BlueprintTestResult) error {
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	if err := blueprint.WriteJUnitReport(file, results); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testFlags := testCmd.Flags()
+	testFlags.StringVarP(&testTemplatePath, "blueprint", "b", "", "The blueprint to test (default: all blueprints of the repository with test cases)")
+	testFlags.StringVarP(&testLocalRepoPath, "local-repo", "l", "", "Local repository or blueprint directory to test (bypasses defined repositories)")
+	testFlags.StringVar(&testJUnitReportPath, "junit-report", "", "Write the results as JUnit XML to the given file")
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [project-dir]",
+	Short: "Upgrade a generated project to the current version of its blueprint",
+	Long: `Generate the blueprint recorded in the manifest of the project directory again with the current version of the blueprint.
+Recorded answers are used and only new parameters are asked. Each generated file is three-way merged with the previously
+generated version and the version on disk, conflicting changes are marked with conflict markers.
+The blueprint is read from the repository it was generated from, upgrading from another repository has to be confirmed.
+In non-interactive mode, which is the default when stdin is not a terminal, nothing is asked and the upgrade fails instead`,
+	Example: `  xl-blueprint upgrade
+  xl-blueprint upgrade ./my-project -l ./my-repository`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		projectDir := "."
+		if len(args) > 0 {
+			projectDir = args[0]
+		}
+		DoUpgrade(context, projectDir)
+	},
+}
+
+var upgradeLocalRepoPath string
+var upgradeNonInteractive bool
+
+// DoUpgrade upgrades the project generated in the given directory and exits with an error when there are conflicts
+func DoUpgrade(context *xl.Context, projectDir string) {
+	var err error
+	blueprintContext := context.BlueprintContext
+	if upgradeLocalRepoPath != "" {
+		blueprintContext, err = blueprint.ConstructLocalBlueprintContext(upgradeLocalRepoPath)
+		if err != nil {
+			util.Fatal("Error creating local blueprint context: %s\n", err)
+		}
+	}
+
+	upgrade, err := blueprintContext.UpgradeBlueprint(projectDir, isNonInteractive(upgradeNonInteractive), nil)
+	if err != nil {
+		exitOnMissingAnswers("upgrading blueprint", err)
+		util.Fatal("Error while upgrading blueprint: %s\n", err)
+	}
+
+	util.Print("Upgraded blueprint [%s] from version [%s] to version [%s]:\n\n", upgrade.Blueprint, upgrade.FromVersion, upgrade.ToVersion)
+	err = util.WriteFormatted(os.Stdout, util.OutputFormatTable, upgrade, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "STATUS\tPATH\tREASON")
+		for _, file := range upgrade.Files {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", file.Status, file.Path, file.Reason)
+		}
+	})
+	if err != nil {
+		util.Fatal("Error while printing upgrade result: %s\n", err)
+	}
+	if upgrade.HasConflicts() {
+		util.Fatal("\nUpgrade finished with conflicts, please resolve the conflict markers in the files listed as conflict\n")
+	}
+	util.Print("%s", util.Green("\nUpgrade finished successfully\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeFlags := upgradeCmd.Flags()
+	upgradeFlags.StringVarP(&upgradeLocalRepoPath, "local-repo", "l", "", "Local repository directory to use (bypasses active repository)")
+	upgradeFlags.BoolVar(&upgradeNonInteractive, "non-interactive", false, "If flag is set, nothing is asked and all parameters without an answer are reported at once, this is the default when stdin is not a terminal")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/blueprint-cli/pkg/xl"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate blueprints without generating any files",
+	Long: `Validate blueprint definitions, included blueprints and template files without generating any files.
+All problems found are reported, the command exits with a non-zero code when any error is found`,
+	Example: `  xl-blueprint validate -l ./my-blueprint
+  xl-blueprint validate -l ./my-repository -b aws/monolith
+  xl-blueprint validate -b aws/monolith -f json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		context, err := xl.BuildContext(viper.GetViper(), CliVersion)
+		if err != nil {
+			util.Fatal("Error while reading configuration: %s\n", err)
+		}
+		if util.IsVerbose {
+			context.PrintConfiguration()
+		}
+
+		DoValidate(context)
+	},
+}
+
+var validateTemplatePath string
+var validateLocalRepoPath string
+var validateOutputFormat string
+
+// DoValidate validates the selected blueprints and exits with a non-zero code on errors
+func DoValidate(context *xl.Context) {
+	applyOutputFormat(validateOutputFormat)
+
+	blueprintContext, templatePath := localBlueprintContext(context.BlueprintContext, validateLocalRepoPath, validateTemplatePath)
+
+	var templatePaths []string
+	if templatePath != "" {
+		templatePaths = append(templatePaths, templatePath)
+	}
+	problems, err := blueprintContext.ValidateBlueprints(templatePaths...)
+	if err != nil {
+		util.Fatal("Error while validating blueprints: %s\n", err)
+	}
+
+	if len(problems) > 0 || util.IsStructuredOutputFormat(validateOutputFormat) {
+		err = util.WriteFormatted(os.Stdout, validateOutputFormat, problems, func(tw *tabwriter.Writer) {
+			fmt.Fprintln(tw, "SEVERITY\tBLUEPRINT\tFILE\tMESSAGE")
+			for _, problem := range problems {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", problem.Severity, problem.Blueprint, problem.File, util.TableCell(problem.Message, 120))
+			}
+		})
+		if err != nil {
+			util.Fatal("Error while printing validation problems: %s\n", err)
+		}
+	}
+
+	if blueprint.HasValidationErrors(problems) {
+		os.Exit(1)
+	}
+	util.Info("%s", util.Green("Blueprint validation completed without errors\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateFlags := validateCmd.Flags()
+	validateFlags.StringVarP(&validateTemplatePath, "blueprint", "b", "", "The blueprint to validate (default: all blueprints of the repository)")
+	validateFlags.StringVarP(&validateLocalRepoPath, "local-repo", "l", "", "Local repository or blueprint directory to validate (bypasses defined repositories)")
+	validateFlags.StringVarP(&validateOutputFormat, "format", "f", util.OutputFormatTable, "Output format, one of: table, json, yaml")
+}
@@ -0,0 +1,83 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Policies for output files that already exist on disk
+const (
+	OnConflictPrompt    = "prompt"
+	OnConflictOverwrite = "overwrite"
+	OnConflictSkip      = "skip"
+	OnConflictFail      = "fail"
+	OnConflictBackup    = "backup"
+)
+
+// OnConflictPolicies are the supported policies for output files that already exist on disk
+var OnConflictPolicies = []string{OnConflictPrompt, OnConflictOverwrite, OnConflictSkip, OnConflictFail, OnConflictBackup}
+
+const backupExtension = ".orig"
+
+// ValidateOnConflictPolicy returns an error when the policy is not supported, an empty policy means overwrite
+func ValidateOnConflictPolicy(onConflict string) error {
+	if onConflict != "" && !util.IsStringInSlice(onConflict, OnConflictPolicies) {
+		return fmt.Errorf("unsupported on-conflict policy [%s], must be one of: %s", onConflict, strings.Join(OnConflictPolicies, ", "))
+	}
+	return nil
+}
+
+// resolveOutputConflict checks whether the planned file already exists on disk, and decides based on the policy
+// whether it should be written. Files generated earlier in the same run are not conflicts
+func resolveOutputConflict(s *session, generatedBlueprint *GeneratedBlueprint, plannedFile PlannedFile, onConflict string) (bool, error) {
+	fileName := generatedBlueprint.outputPath(plannedFile.Path)
+	if util.IsStringInSlice(fileName, generatedBlueprint.GeneratedFiles) || !generatedBlueprint.pathExists(fileName, false) {
+		return true, nil
+	}
+
+	existingContent, err := generatedBlueprint.fs().ReadFile(fileName)
+	if err != nil {
+		return false, err
+	}
+	if string(existingContent) == plannedFile.Content {
+		s.logger.Verbose("[file] Existing file %s has the same content\n", fileName)
+		return true, nil
+	}
+
+	if onConflict == OnConflictPrompt {
+		diff, err := util.UnifiedDiff(string(existingContent), plannedFile.Content, fileName+" (existing)", fileName+" (blueprint)")
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(s.out, "\n%s\n", diff)
+		onConflict, err = askString(s.answers, Question{
+			Type:    TypeSelect,
+			Message: fmt.Sprintf("File %s already exists, what should be done?", fileName),
+			Options: []string{OnConflictOverwrite, OnConflictBackup, OnConflictSkip},
+			Default: OnConflictBackup,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	switch onConflict {
+	case "", OnConflictOverwrite:
+		s.logger.Verbose("[file] Overwriting existing file %s\n", fileName)
+		return true, nil
+	case OnConflictSkip:
+		s.logger.Info("[file] Skipping existing file '%s'\n", fileName)
+		return false, nil
+	case OnConflictBackup:
+		s.logger.Info("[file] Backing up existing file '%s' to '%s'\n", fileName, fileName+backupExtension)
+		// the backup is written when the output file is promoted, so that it is rolled back with it
+		generatedBlueprint.backupFiles = append(generatedBlueprint.backupFiles, fileName)
+		return true, nil
+	case OnConflictFail:
+		return false, fmt.Errorf("output file %s already exists", fileName)
+	default:
+		return false, ValidateOnConflictPolicy(onConflict)
+	}
+}
@@ -0,0 +1,98 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstantiateBlueprint_OnConflict(t *testing.T) {
+	SkipFinalPrompt = true
+
+	tests := []struct {
+		name            string
+		onConflict      string
+		wantErr         string
+		wantContent     string
+		wantBackup      bool
+		wantNewFileKept bool
+	}{
+		{"should overwrite existing file", OnConflictOverwrite, "", "blueprint", false, true},
+		{"should overwrite existing file with empty policy", "", "", "blueprint", false, true},
+		{"should skip existing file", OnConflictSkip, "", "existing", false, true},
+		{"should backup existing file", OnConflictBackup, "", "blueprint", true, true},
+		{"should fail on existing file and keep it on cleanup", OnConflictFail, "already exists", "existing", false, false},
+		{"should fail on unknown policy", "merge", "unsupported on-conflict policy [merge]", "existing", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputRoot, err := ioutil.TempDir("", "onConflict")
+			require.Nil(t, err)
+			defer os.RemoveAll(outputRoot)
+			existingFile := filepath.Join(outputRoot, "xlr-pipeline-4.yml")
+			require.Nil(t, ioutil.WriteFile(existingFile, []byte("existing"), 0640))
+
+			gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+			_, _, err = InstantiateBlueprint(
+				BlueprintParams{
+					TemplatePath:       "composed",
+					UseDefaultsAsValue: true,
+					OutputRoot:         outputRoot,
+					OnConflict:         tt.onConflict,
+				},
+				getLocalTestBlueprintContext(t),
+				gb, nil,
+			)
+			if tt.wantErr != "" {
+				require.NotNil(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				require.Nil(t, gb.Cleanup())
+			} else {
+				require.Nil(t, err)
+			}
+
+			content := GetFileContent(existingFile)
+			if tt.wantContent == "existing" {
+				assert.Equal(t, "existing", content)
+			} else {
+				assert.NotEqual(t, "existing", content)
+			}
+			if tt.wantBackup {
+				assert.Equal(t, "existing", GetFileContent(existingFile+backupExtension))
+			} else {
+				assert.False(t, fileExists(existingFile+backupExtension))
+			}
+			assert.Equal(t, tt.wantNewFileKept, fileExists(filepath.Join(outputRoot, "xld-infrastructure.yml")))
+		})
+	}
+
+	t.Run("should never remove pre-existing files on cleanup", func(t *testing.T) {
+		outputRoot, err := ioutil.TempDir("", "onConflict")
+		require.Nil(t, err)
+		defer os.RemoveAll(outputRoot)
+		existingFile := filepath.Join(outputRoot, "xlr-pipeline-4.yml")
+		require.Nil(t, ioutil.WriteFile(existingFile, []byte("existing"), 0640))
+
+		gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+		_, _, err = InstantiateBlueprint(
+			BlueprintParams{
+				TemplatePath:       "composed",
+				UseDefaultsAsValue: true,
+				OutputRoot:         outputRoot,
+				OnConflict:         OnConflictOverwrite,
+			},
+			getLocalTestBlueprintContext(t),
+			gb, nil,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, []string{existingFile}, gb.PreExistingFiles)
+
+		require.Nil(t, gb.Cleanup())
+		assert.True(t, fileExists(existingFile))
+		assert.False(t, fileExists(filepath.Join(outputRoot, "xld-infrastructure.yml")))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -118,12 +119,7 @@ func CreateOrUpdateBlueprintConfig(v *viper.Viper, configPath string) (*viper.Vi
 	}
 	if WriteConfigFile && vFromConfig != nil {
 		// write to existing config file
-		c := util.SortMapStringInterface(vFromConfig.AllSettings())
-		yamlBytes, err := yaml.Marshal(c)
-		if err != nil {
-			return v, activeRepoName, err
-		}
-		err = ioutil.WriteFile(configPath, yamlBytes, 0640)
+		err = writeViperConfigFile(vFromConfig, configPath)
 		if err != nil {
 			return v, activeRepoName, err
 		}
@@ -131,6 +127,15 @@ func CreateOrUpdateBlueprintConfig(v *viper.Viper, configPath string) (*viper.Vi
 	return v, activeRepoName, nil
 }
 
+func writeViperConfigFile(v *viper.Viper, configPath string) error {
+	c := util.SortMapStringInterface(v.AllSettings())
+	yamlBytes, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configPath, yamlBytes, 0640)
+}
+
 func SetRootFlags(rootFlags *pflag.FlagSet) {
 	rootFlags.String(FlagBlueprintCurrentRepository, "", "Current active blueprint repository name")
 
@@ -194,6 +199,25 @@ func ConstructLocalBlueprintContext(repoPath string) (*BlueprintContext, error)
 	return nil, fmt.Errorf("error: provided local repository directory [%s] is not valid", repoPath)
 }
 
+// SplitLocalBlueprintDir checks if the given local directory is a single blueprint, ie. it contains a blueprint definition file,
+// in that case its parent directory and the blueprint path within it are returned so that it can be used as local repository
+func SplitLocalBlueprintDir(dirPath string) (string, string, bool) {
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return "", "", false
+	}
+	for _, file := range files {
+		if !file.IsDir() && repository.CheckIfBlueprintDefinitionFile(file.Name()) {
+			absPath, err := filepath.Abs(dirPath)
+			if err != nil {
+				return "", "", false
+			}
+			return filepath.Dir(absPath), filepath.Base(absPath), true
+		}
+	}
+	return "", "", false
+}
+
 func ConstructBlueprintContext(v *viper.Viper, configPath, CLIVersion string) (*BlueprintContext, error) {
 	util.Verbose("Updating CLI config %s with blueprint configuration\n", configPath)
 	v, activeRepoName, err := CreateOrUpdateBlueprintConfig(v, configPath)
@@ -216,34 +240,7 @@ func ConstructBlueprintContext(v *viper.Viper, configPath, CLIVersion string) (*
 			return nil, fmt.Errorf("repository with index %d doesn't have all mandatory fields set [type, name]", i)
 		}
 
-		// Get repository type
-		var repo repository.BlueprintRepository
-		repoProvider, err := models.GetRepoProvider(repoDefinition["type"])
-		if err != nil {
-			return nil, err
-		}
-		util.Verbose("Creating blueprint repo configuration %v\n", repoDefinition)
-		// Parse according to type string
-		switch repoProvider {
-		case models.ProviderMock: // only used for testing purposes
-			repo, err = mock.NewMockBlueprintRepository(repoDefinition)
-		case models.ProviderLocal:
-			repo, err = local.NewLocalBlueprintRepository(repoDefinition)
-		case models.ProviderGitHub:
-			repo, err = github.NewGitHubBlueprintRepository(repoDefinition)
-		case models.ProviderBitbucket:
-			repo, err = bitbucket.NewBitbucketBlueprintRepository(repoDefinition)
-		case models.ProviderBitbucketServer:
-			repo, err = bitbucketserver.NewBitbucketServerBlueprintRepository(repoDefinition)
-		case models.ProviderHttp:
-			repo, err = http.NewHttpBlueprintRepository(repoDefinition, CLIVersion)
-		case models.ProviderGitLab:
-			repo, err = gitlab.NewGitLabBlueprintRepository(repoDefinition)
-		case models.ProviderZip:
-			repo, err = zip.NewDefaultZipBlueprintRepository(repoDefinition, CLIVersion)
-		default:
-			return nil, fmt.Errorf("no blueprint provider implementation found for %s", repoProvider)
-		}
+		repo, err := NewBlueprintRepository(repoDefinition, CLIVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -266,6 +263,55 @@ func ConstructBlueprintContext(v *viper.Viper, configPath, CLIVersion string) (*
 	}, nil
 }
 
+// NewBlueprintRepository creates the repository provider matching the type field of the repository definition
+func NewBlueprintRepository(repoDefinition ConfMap, CLIVersion string) (repository.BlueprintRepository, error) {
+	// Get repository type
+	var repo repository.BlueprintRepository
+	repoProvider, err := models.GetRepoProvider(repoDefinition["type"])
+	if err != nil {
+		return nil, err
+	}
+	util.Verbose("Creating blueprint repo configuration %v\n", repoDefinition)
+	// Parse according to type string
+	switch repoProvider {
+	case models.ProviderMock: // only used for testing purposes
+		repo, err = mock.NewMockBlueprintRepository(repoDefinition)
+	case models.ProviderLocal:
+		repo, err = local.NewLocalBlueprintRepository(repoDefinition)
+	case models.ProviderGitHub:
+		repo, err = github.NewGitHubBlueprintRepository(repoDefinition)
+	case models.ProviderBitbucket:
+		repo, err = bitbucket.NewBitbucketBlueprintRepository(repoDefinition)
+	case models.ProviderBitbucketServer:
+		repo, err = bitbucketserver.NewBitbucketServerBlueprintRepository(repoDefinition)
+	case models.ProviderHttp:
+		repo, err = http.NewHttpBlueprintRepository(repoDefinition, CLIVersion)
+	case models.ProviderGitLab:
+		repo, err = gitlab.NewGitLabBlueprintRepository(repoDefinition)
+	case models.ProviderZip:
+		repo, err = zip.NewDefaultZipBlueprintRepository(repoDefinition, CLIVersion)
+	default:
+		return nil, fmt.Errorf("no blueprint provider implementation found for %s", repoProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// WithActiveRepo returns a copy of the context where the defined repository with the given name is the active one
+func (blueprintContext *BlueprintContext) WithActiveRepo(repoName string) (*BlueprintContext, error) {
+	for _, repo := range blueprintContext.DefinedRepos {
+		if strings.EqualFold((*repo).GetName(), repoName) {
+			return &BlueprintContext{
+				ActiveRepo:   repo,
+				DefinedRepos: blueprintContext.DefinedRepos,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("repository name '%s' is not matching with any of the defined repositories", repoName)
+}
+
 func (blueprintContext *BlueprintContext) initCurrentRepoClient() (map[string]*models.BlueprintRemote, error) {
 	err := (*blueprintContext.ActiveRepo).Initialize()
 	util.Verbose("Using active blueprint repo\n%s\n", (*blueprintContext.ActiveRepo).GetInfo())
@@ -295,7 +341,7 @@ func (blueprintContext *BlueprintContext) parseRepositoryTree() (map[string]*mod
 	return blueprints, nil
 }
 
-func (blueprintContext *BlueprintContext) askUserToChooseBlueprint(blueprints map[string]*models.BlueprintRemote, blueprintTemplate string, surveyOpts ...survey.AskOpt) (string, error) {
+func (blueprintContext *BlueprintContext) askUserToChooseBlueprint(answers AnswerProvider, blueprints map[string]*models.BlueprintRemote, blueprintTemplate string) (string, error) {
 	if blueprintTemplate == "" {
 		var blueprintKeys []string
 		for k := range blueprints {
@@ -313,16 +359,13 @@ func (blueprintContext *BlueprintContext) askUserToChooseBlueprint(blueprints ma
 		}
 		sort.Strings(blueprintKeys)
 
-		surveyOpts = append(surveyOpts, survey.WithValidator(survey.Required))
-		_ = survey.AskOne(
-			&survey.Select{
-				Message: "Choose a blueprint:",
-				Options: blueprintKeys,
-				Default: blueprintKeys[0],
-			},
-			&blueprintTemplate,
-			surveyOpts...,
-		)
+		blueprintTemplate, _ = askString(answers, Question{
+			Type:     TypeSelect,
+			Message:  "Choose a blueprint:",
+			Options:  blueprintKeys,
+			Default:  blueprintKeys[0],
+			Validate: survey.Required,
+		})
 	}
 
 	return blueprintTemplate, nil
@@ -362,18 +405,25 @@ func (blueprintContext *BlueprintContext) parseDefinitionFile(blueprint *models.
 }
 
 func parseTemplateMetadata(ymlContent *[]byte, templatePath string, blueprintRepository *BlueprintContext) (*BlueprintConfig, error) {
+	blueprintConfig, errs := parseTemplateMetadataErrors(ymlContent)
+	return blueprintConfig, firstError(errs)
+}
+
+// parseTemplateMetadataErrors parses the blueprint definition with the parser of its api version and returns
+// all problems found, parseTemplateMetadata returns the first one only
+func parseTemplateMetadataErrors(ymlContent *[]byte) (*BlueprintConfig, []error) {
 	decoder := yaml.NewDecoder(bytes.NewReader(*ymlContent))
 	yamlDoc := struct {
 		ApiVersion string `yaml:"apiVersion"`
 	}{}
 	err := decoder.Decode(&yamlDoc)
 	if err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 	if yamlDoc.ApiVersion == models.BlueprintYamlFormatV1 {
-		return parseTemplateMetadataV1(ymlContent, templatePath, blueprintRepository)
+		return parseTemplateMetadataV1Errors(ymlContent)
 	}
-	return parseTemplateMetadataV2(ymlContent, templatePath, blueprintRepository)
+	return parseTemplateMetadataV2Errors(ymlContent)
 }
 
 /*
@@ -309,6 +309,25 @@ func TestConstructLocalBlueprintContext(t *testing.T) {
 	})
 }
 
+func TestSplitLocalBlueprintDir(t *testing.T) {
+	localPath := GetTestTemplateDir("")
+
+	t.Run("should split blueprint directory into repository and blueprint path", func(t *testing.T) {
+		repoPath, templatePath, ok := SplitLocalBlueprintDir(filepath.Join(localPath, "answer-input"))
+		require.True(t, ok)
+		assert.Equal(t, filepath.Clean(localPath), repoPath)
+		assert.Equal(t, "answer-input", templatePath)
+	})
+	t.Run("should not split repository directory", func(t *testing.T) {
+		_, _, ok := SplitLocalBlueprintDir(localPath)
+		assert.False(t, ok)
+	})
+	t.Run("should not split missing directory", func(t *testing.T) {
+		_, _, ok := SplitLocalBlueprintDir(filepath.Join(localPath, "not-there"))
+		assert.False(t, ok)
+	})
+}
+
 func TestConstructBlueprintContext(t *testing.T) {
 	configDir, _ := ioutil.TempDir("", "xebialabsconfig")
 	defer os.RemoveAll(configDir)
@@ -474,6 +493,27 @@ func TestBlueprintContext_parseDefinitionFile(t *testing.T) {
 	}
 }
 
+func TestBlueprintContext_WithActiveRepo(t *testing.T) {
+	configDir, _ := ioutil.TempDir("", "xebialabsconfig")
+	defer os.RemoveAll(configDir)
+	v := GetViperConf(t, defaultContextYaml)
+	c, err := ConstructBlueprintContext(v, path.Join(configDir, "config.yaml"), DummyCLIVersion)
+	require.Nil(t, err)
+
+	t.Run("should switch active repo by name", func(t *testing.T) {
+		switched, err := c.WithActiveRepo("xl github")
+		require.Nil(t, err)
+		assert.Equal(t, "XL Github", (*switched.ActiveRepo).GetName())
+		assert.Equal(t, c.DefinedRepos, switched.DefinedRepos)
+		assert.Equal(t, "XL Http", (*c.ActiveRepo).GetName())
+	})
+	t.Run("should error on unknown repo name", func(t *testing.T) {
+		_, err := c.WithActiveRepo("unknown")
+		require.NotNil(t, err)
+		assert.Equal(t, "repository name 'unknown' is not matching with any of the defined repositories", err.Error())
+	})
+}
+
 func TestBlueprintContext_initCurrentRepoClient(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 	t.Run("should init repo client with http blueprint provider", func(t *testing.T) {
@@ -0,0 +1,386 @@
+package blueprint
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/yaml"
+)
+
+// parameter types a new blueprint can be created with, file parameters need files to test with
+var scaffoldTypes = []string{TypeInput, TypeSelect, TypeConfirm, TypeSecret, TypeEditor, TypeSecretEditor}
+
+var regExScaffoldParameterName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// BlueprintScaffold describes a new blueprint created by CreateBlueprint
+type BlueprintScaffold struct {
+	Name        string
+	Description string
+	Author      string
+	Version     string
+	Parameters  []ScaffoldParameter
+}
+
+// ScaffoldParameter is a parameter of a new blueprint, Options are only used by TypeSelect parameters
+type ScaffoldParameter struct {
+	Name    string
+	Type    string
+	Prompt  string
+	Options []string
+}
+
+// scaffoldView is the data the files of a new blueprint are rendered with
+type scaffoldView struct {
+	BlueprintScaffold
+	Parameters []scaffoldParameterView
+}
+
+type scaffoldParameterView struct {
+	ScaffoldParameter
+	IsSecret     bool
+	SampleAnswer string
+}
+
+// scaffoldFile is a file of a new blueprint, relative to the blueprint directory
+type scaffoldFile struct {
+	path    string
+	content []byte
+}
+
+// DefaultScaffoldParameters returns the sample parameters a new blueprint is created with when none are given
+func DefaultScaffoldParameters() []ScaffoldParameter {
+	return []ScaffoldParameter{
+		{Name: "AppName", Type: TypeInput, Prompt: "What is the name of the application?"},
+		{Name: "Environment", Type: TypeSelect, Prompt: "Which environment do you want to deploy to?", Options: []string{"dev", "test", "prod"}},
+		{Name: "Public", Type: TypeConfirm, Prompt: "Do you want to expose the application publicly?"},
+	}
+}
+
+// ParseScaffoldParameter parses a parameter given as NAME[:TYPE[:OPTION,OPTION...]], the type defaults to Input
+func ParseScaffoldParameter(value string) (ScaffoldParameter, error) {
+	parts := strings.SplitN(value, ":", 3)
+	parameter := ScaffoldParameter{Name: strings.TrimSpace(parts[0]), Type: TypeInput}
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+		parameter.Type = strings.TrimSpace(parts[1])
+	}
+	if len(parts) > 2 {
+		for _, option := range strings.Split(parts[2], ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				parameter.Options = append(parameter.Options, option)
+			}
+		}
+	}
+	return parameter, parameter.validate()
+}
+
+func (parameter *ScaffoldParameter) validate() error {
+	if !regExScaffoldParameterName.MatchString(parameter.Name) {
+		return fmt.Errorf("parameter name [%s] must start with a letter and contain only letters, digits and underscores", parameter.Name)
+	}
+	if !util.IsStringInSlice(parameter.Type, scaffoldTypes) {
+		return fmt.Errorf("type [%s] of parameter [%s] is not supported, use one of %s", parameter.Type, parameter.Name, strings.Join(scaffoldTypes, ", "))
+	}
+	if parameter.Type == TypeSelect && len(parameter.Options) == 0 {
+		return fmt.Errorf("at least one option is needed for Select parameter [%s]", parameter.Name)
+	}
+	if parameter.Type != TypeSelect && len(parameter.Options) > 0 {
+		return fmt.Errorf("options can only be given for Select parameters, not for parameter [%s]", parameter.Name)
+	}
+	return nil
+}
+
+// prompt returns the prompt of the parameter, a generic one is used when it has none
+func (parameter *ScaffoldParameter) prompt() string {
+	if parameter.Prompt != "" {
+		return parameter.Prompt
+	}
+	switch parameter.Type {
+	case TypeConfirm:
+		return fmt.Sprintf("Do you want to enable %s?", parameter.Name)
+	case TypeSelect:
+		return fmt.Sprintf("Which %s do you want to use?", parameter.Name)
+	default:
+		return fmt.Sprintf("What is the value of %s?", parameter.Name)
+	}
+}
+
+// sampleAnswer returns the answer used for the parameter in the test case of the new blueprint
+func (parameter *ScaffoldParameter) sampleAnswer() string {
+	switch parameter.Type {
+	case TypeConfirm:
+		return "true"
+	case TypeSelect:
+		return parameter.Options[0]
+	case TypeSecret, TypeSecretEditor:
+		return "secret"
+	default:
+		return "my-" + strings.ToLower(parameter.Name)
+	}
+}
+
+func (parameter *ScaffoldParameter) isSecret() bool {
+	return parameter.Type == TypeSecret || parameter.Type == TypeSecretEditor
+}
+
+// AskBlueprintScaffold asks the fields of the scaffold that are not set yet, parameters are asked until an empty
+// name is given when the scaffold has none, and the default parameters are used when none are given
+func AskBlueprintScaffold(answers AnswerProvider, scaffold *BlueprintScaffold) error {
+	fields := []struct {
+		value   *string
+		message string
+	}{
+		{&scaffold.Name, "What is the name of the blueprint?"},
+		{&scaffold.Description, "What does the blueprint generate?"},
+		{&scaffold.Author, "Who is the author of the blueprint?"},
+	}
+	for _, field := range fields {
+		if *field.value != "" {
+			continue
+		}
+		answer, err := askString(answers, Question{Type: TypeInput, Message: field.message})
+		if err != nil {
+			return err
+		}
+		*field.value = answer
+	}
+
+	if len(scaffold.Parameters) > 0 {
+		return nil
+	}
+	for {
+		name, err := askString(answers, Question{
+			Type:    TypeInput,
+			Message: "What is the name of the next parameter?",
+			Help:    "Leave empty to finish, sample parameters are added when no parameter is given",
+			Validate: func(answer interface{}) error {
+				if name, _ := answer.(string); name != "" && !regExScaffoldParameterName.MatchString(name) {
+					return fmt.Errorf("the name must start with a letter and contain only letters, digits and underscores")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		parameter := ScaffoldParameter{Name: name}
+		if parameter.Type, err = askString(answers, Question{
+			Type:    TypeSelect,
+			Message: fmt.Sprintf("What is the type of parameter %s?", name),
+			Options: scaffoldTypes,
+			Default: TypeInput,
+		}); err != nil {
+			return err
+		}
+		if parameter.Type == TypeSelect {
+			options, err := askString(answers, Question{
+				Type:    TypeInput,
+				Message: fmt.Sprintf("What are the options of parameter %s (comma separated)?", name),
+			})
+			if err != nil {
+				return err
+			}
+			if parameter, err = ParseScaffoldParameter(name + ":" + TypeSelect + ":" + options); err != nil {
+				return err
+			}
+		} else if err := parameter.validate(); err != nil {
+			return err
+		}
+		scaffold.Parameters = append(scaffold.Parameters, parameter)
+	}
+	return nil
+}
+
+// CreateBlueprint writes a new blueprint to the given directory, which must not exist or be empty.
+// The blueprint definition is parsed and validated before any file is written. The paths of the written files are returned
+func CreateBlueprint(dir string, scaffold BlueprintScaffold) ([]string, error) {
+	if entries, err := ioutil.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", dir)
+	}
+
+	files, err := renderScaffold(scaffold)
+	if err != nil {
+		return nil, err
+	}
+	definition := files[0].content
+	if _, err := parseTemplateMetadataV2(&definition, dir, nil); err != nil {
+		return nil, fmt.Errorf("created blueprint definition is not valid: %s", err.Error())
+	}
+
+	var written []string
+	for _, file := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(file.path))
+		util.Verbose("[create] Writing blueprint file %s\n", filePath)
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return written, err
+		}
+		if err := ioutil.WriteFile(filePath, file.content, 0640); err != nil {
+			return written, err
+		}
+		written = append(written, filePath)
+	}
+	return written, nil
+}
+
+// renderScaffold returns the files of the new blueprint, the blueprint definition first
+func renderScaffold(scaffold BlueprintScaffold) ([]scaffoldFile, error) {
+	if strings.TrimSpace(scaffold.Name) == "" {
+		return nil, fmt.Errorf("the name of the blueprint is needed")
+	}
+	if scaffold.Version == "" {
+		scaffold.Version = "1.0.0"
+	}
+	if len(scaffold.Parameters) == 0 {
+		scaffold.Parameters = DefaultScaffoldParameters()
+	}
+	view := scaffoldView{BlueprintScaffold: scaffold}
+	names := make(map[string]bool)
+	for _, parameter := range scaffold.Parameters {
+		if err := parameter.validate(); err != nil {
+			return nil, err
+		}
+		if names[parameter.Name] {
+			return nil, fmt.Errorf("parameter [%s] is given more than once", parameter.Name)
+		}
+		names[parameter.Name] = true
+		parameter.Prompt = parameter.prompt()
+		view.Parameters = append(view.Parameters, scaffoldParameterView{parameter, parameter.isSecret(), parameter.sampleAnswer()})
+	}
+
+	definition, err := executeScaffoldTemplate(scaffoldDefinitionTemplate, view)
+	if err != nil {
+		return nil, err
+	}
+	app, err := executeScaffoldTemplate(scaffoldAppTemplate, view)
+	if err != nil {
+		return nil, err
+	}
+	readme, err := executeScaffoldTemplate(scaffoldReadmeTemplate, view)
+	if err != nil {
+		return nil, err
+	}
+	overrideDefaults, err := executeScaffoldTemplate(scaffoldOverrideDefaultsTemplate, view)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := executeScaffoldTemplate(scaffoldAnswersTemplate, view)
+	if err != nil {
+		return nil, err
+	}
+
+	return []scaffoldFile{
+		{path: repository.BlueprintMetadataFileName + ".yaml", content: definition},
+		{path: "app.yaml.tmpl", content: app},
+		{path: "README.md.tmpl", content: readme},
+		{path: overrideDefaultsFile, content: overrideDefaults},
+		{path: path.Join(testCaseDir, "test-case-1.yaml"), content: []byte(scaffoldTestCase)},
+		{path: path.Join(testCaseDir, "answers-1.yaml"), content: answers},
+	}, nil
+}
+
+var scaffoldFuncs = template.FuncMap{
+	// quote returns the value as a YAML scalar, quoted when needed
+	"quote": func(value string) (string, error) {
+		out, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(out), "\n"), err
+	},
+	// placeholder returns the template placeholder of a parameter
+	"placeholder": func(name string) string {
+		return "{{." + name + "}}"
+	},
+}
+
+func executeScaffoldTemplate(text string, view scaffoldView) ([]byte, error) {
+	tmpl, err := template.New("scaffold").Funcs(scaffoldFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const scaffoldDefinitionTemplate = `apiVersion: xl/v2
+kind: Blueprint
+
+metadata:
+  name: {{ quote .Name }}
+  description: {{ quote .Description }}
+  author: {{ quote .Author }}
+  version: {{ quote .Version }}
+
+spec:
+  # parameters are asked in the order they are listed, answers are available in the templates as {{ "{{.Name}}" }}
+  parameters:
+{{- range .Parameters }}
+  - name: {{ .Name }}
+    type: {{ .Type }}
+    prompt: {{ quote .Prompt }}
+{{- if .Options }}
+    options:
+{{- range .Options }}
+    - {{ quote . }}
+{{- end }}
+    default: {{ quote (index .Options 0) }}
+{{- else if eq .Type "Confirm" }}
+    default: false
+{{- end }}
+{{- if not .IsSecret }}
+    overrideDefault: true
+{{- end }}
+{{- end }}
+
+  # files ending in .tmpl are rendered with the answers, all other files are copied as they are.
+  # A file can be skipped with an expression, e.g. writeIf: !expr "Public"
+  files:
+  - path: app.yaml.tmpl
+  - path: README.md.tmpl
+`
+
+const scaffoldAppTemplate = `# replace this file with the files the blueprint should generate
+{{- range .Parameters }}
+{{- if not .IsSecret }}
+{{ .Name }}: {{ placeholder .Name }}
+{{- end }}
+{{- end }}
+`
+
+const scaffoldReadmeTemplate = `# {{ .Name }}
+
+This project was generated with the {{ .Name }} blueprint.
+`
+
+const scaffoldOverrideDefaultsTemplate = `# values given here replace the defaults of the parameters marked with overrideDefault,
+# answers given while generating the blueprint always take precedence
+{{- range .Parameters }}
+{{- if not .IsSecret }}
+# {{ .Name }}: {{ quote .SampleAnswer }}
+{{- end }}
+{{- end }}
+`
+
+const scaffoldTestCase = `# generates the blueprint with the answers of answers-1.yaml and verifies the generated files, run it with xl-blueprint test
+answers-file: answers-1.yaml
+expected-files:
+- app.yaml
+- README.md
+`
+
+const scaffoldAnswersTemplate = `# answers used by __test__/test-case-1.yaml
+{{- range .Parameters }}
+{{ .Name }}: {{ quote .SampleAnswer }}
+{{- end }}
+`
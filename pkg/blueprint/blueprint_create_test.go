@@ -0,0 +1,118 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScaffoldParameter(t *testing.T) {
+	t.Run("should default to an Input parameter", func(t *testing.T) {
+		parameter, err := ParseScaffoldParameter("AppName")
+		require.Nil(t, err)
+		assert.Equal(t, ScaffoldParameter{Name: "AppName", Type: TypeInput}, parameter)
+	})
+
+	t.Run("should parse the type and options", func(t *testing.T) {
+		parameter, err := ParseScaffoldParameter("Region:Select:eu-west-1, us-east-1")
+		require.Nil(t, err)
+		assert.Equal(t, ScaffoldParameter{Name: "Region", Type: TypeSelect, Options: []string{"eu-west-1", "us-east-1"}}, parameter)
+	})
+
+	t.Run("should fail on invalid parameters", func(t *testing.T) {
+		_, err := ParseScaffoldParameter("App Name")
+		assert.Equal(t, "parameter name [App Name] must start with a letter and contain only letters, digits and underscores", err.Error())
+		_, err = ParseScaffoldParameter("Region:Select")
+		assert.Equal(t, "at least one option is needed for Select parameter [Region]", err.Error())
+		_, err = ParseScaffoldParameter("Cert:File")
+		assert.Equal(t, "type [File] of parameter [Cert] is not supported, use one of Input, Select, Confirm, SecretInput, Editor, SecretEditor", err.Error())
+	})
+}
+
+func TestCreateBlueprint(t *testing.T) {
+	t.Run("should create a blueprint that validates and passes its own test case", func(t *testing.T) {
+		repoDir, err := ioutil.TempDir("", "createrepo")
+		require.Nil(t, err)
+		defer os.RemoveAll(repoDir)
+		blueprintDir := filepath.Join(repoDir, "my-blueprint")
+
+		written, err := CreateBlueprint(blueprintDir, BlueprintScaffold{
+			Name:        "My Blueprint",
+			Description: "Generates: my application",
+			Parameters: append(DefaultScaffoldParameters(),
+				ScaffoldParameter{Name: "Password", Type: TypeSecret},
+			),
+		})
+		require.Nil(t, err)
+		assert.Len(t, written, 6)
+		assert.Contains(t, GetFileContent(filepath.Join(blueprintDir, "blueprint.yaml")), `description: 'Generates: my application'`)
+		assert.Equal(t, "# replace this file with the files the blueprint should generate\n"+
+			"AppName: {{.AppName}}\nEnvironment: {{.Environment}}\nPublic: {{.Public}}\n",
+			GetFileContent(filepath.Join(blueprintDir, "app.yaml.tmpl")))
+		assert.True(t, fileExists(filepath.Join(blueprintDir, "override-defaults.yaml")))
+
+		blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+		require.Nil(t, err)
+		problems, err := blueprintContext.ValidateBlueprints("my-blueprint")
+		require.Nil(t, err)
+		assert.Empty(t, problems)
+		results, err := blueprintContext.RunBlueprintTests("my-blueprint")
+		require.Nil(t, err)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Passed(), "%+v", results[0])
+	})
+
+	t.Run("should not write to a directory that is not empty", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "createexisting")
+		require.Nil(t, err)
+		defer os.RemoveAll(dir)
+		require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "existing.txt"), []byte("existing"), 0640))
+
+		_, err = CreateBlueprint(dir, BlueprintScaffold{Name: "My Blueprint"})
+		require.NotNil(t, err)
+		assert.Equal(t, "directory "+dir+" is not empty", err.Error())
+	})
+
+	t.Run("should not write anything when the definition is not valid", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "createinvalid")
+		require.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		_, err = CreateBlueprint(dir, BlueprintScaffold{Name: "My Blueprint", Parameters: []ScaffoldParameter{
+			{Name: "AppName", Type: TypeInput},
+			{Name: "AppName", Type: TypeConfirm},
+		}})
+		require.NotNil(t, err)
+		assert.Equal(t, "parameter [AppName] is given more than once", err.Error())
+		entries, err := ioutil.ReadDir(dir)
+		require.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestAskBlueprintScaffold(t *testing.T) {
+	answers := &scaffoldAnswerProvider{answers: []string{"My Blueprint", "Description", "Region", TypeSelect, "a,b", ""}}
+	scaffold := BlueprintScaffold{Author: "XebiaLabs"}
+	require.Nil(t, AskBlueprintScaffold(answers, &scaffold))
+	assert.Equal(t, BlueprintScaffold{
+		Name:        "My Blueprint",
+		Description: "Description",
+		Author:      "XebiaLabs",
+		Parameters:  []ScaffoldParameter{{Name: "Region", Type: TypeSelect, Options: []string{"a", "b"}}},
+	}, scaffold)
+}
+
+// scaffoldAnswerProvider answers the questions in order
+type scaffoldAnswerProvider struct {
+	answers []string
+}
+
+func (provider *scaffoldAnswerProvider) Ask(question Question) (interface{}, error) {
+	answer := provider.answers[0]
+	provider.answers = provider.answers[1:]
+	return answer, nil
+}
@@ -0,0 +1,109 @@
+package blueprint
+
+import (
+	"fmt"
+
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// BlueprintDescription is the parameter and file contract of a blueprint, including its composed blueprints
+type BlueprintDescription struct {
+	Path               string                 `json:"path" yaml:"path"`
+	Name               string                 `json:"name" yaml:"name"`
+	Description        string                 `json:"description" yaml:"description"`
+	Author             string                 `json:"author" yaml:"author"`
+	Version            string                 `json:"version" yaml:"version"`
+	ComposedBlueprints []string               `json:"composedBlueprints" yaml:"composedBlueprints"`
+	Parameters         []ParameterDescription `json:"parameters" yaml:"parameters"`
+	Files              []FileDescription      `json:"files" yaml:"files"`
+}
+
+// ParameterDescription describes a single blueprint parameter and the composed blueprint it comes from
+type ParameterDescription struct {
+	Name      string   `json:"name" yaml:"name"`
+	Type      string   `json:"type" yaml:"type"`
+	Prompt    string   `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	Value     string   `json:"value,omitempty" yaml:"value,omitempty"`
+	Default   string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Options   []string `json:"options,omitempty" yaml:"options,omitempty"`
+	Validate  string   `json:"validate,omitempty" yaml:"validate,omitempty"`
+	PromptIf  string   `json:"promptIf,omitempty" yaml:"promptIf,omitempty"`
+	Blueprint string   `json:"blueprint" yaml:"blueprint"`
+}
+
+// FileDescription describes a single blueprint file and the composed blueprint it comes from
+type FileDescription struct {
+	Path      string `json:"path" yaml:"path"`
+	WriteIf   string `json:"writeIf,omitempty" yaml:"writeIf,omitempty"`
+	RenameTo  string `json:"renameTo,omitempty" yaml:"renameTo,omitempty"`
+	Blueprint string `json:"blueprint" yaml:"blueprint"`
+}
+
+// DescribeBlueprint resolves the included blueprints of the given blueprint and describes the resulting parameters and files,
+// parameter and file overrides of the including blueprints are applied
+func (blueprintContext *BlueprintContext) DescribeBlueprint(templatePath string) (*BlueprintDescription, error) {
+	util.Verbose("[describe] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	blueprintDocs, masterBlueprintDoc, err := getBlueprintConfig(cliLogger{}, blueprintContext, blueprints, templatePath, []VarField{{}}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	description := &BlueprintDescription{
+		Path:               templatePath,
+		Name:               masterBlueprintDoc.Metadata.Name,
+		Description:        masterBlueprintDoc.Metadata.Description,
+		Author:             masterBlueprintDoc.Metadata.Author,
+		Version:            masterBlueprintDoc.Metadata.Version,
+		ComposedBlueprints: []string{},
+		Parameters:         []ParameterDescription{},
+		Files:              []FileDescription{},
+	}
+	for _, composed := range blueprintDocs {
+		description.ComposedBlueprints = append(description.ComposedBlueprints, composed.Name)
+		for _, variable := range composed.BlueprintConfig.Variables {
+			parameter := ParameterDescription{
+				Name:      variable.Name.Value,
+				Type:      variable.Type.Value,
+				Prompt:    variable.Prompt.Value,
+				Value:     describeVarField(variable.Value),
+				Default:   describeVarField(variable.Default),
+				Validate:  describeVarField(variable.Validate),
+				PromptIf:  describeVarField(variable.DependsOn),
+				Blueprint: composed.Name,
+			}
+			for _, option := range variable.Options {
+				parameter.Options = append(parameter.Options, describeVarField(option))
+			}
+			description.Parameters = append(description.Parameters, parameter)
+		}
+		for _, config := range composed.BlueprintConfig.TemplateConfigs {
+			description.Files = append(description.Files, FileDescription{
+				Path:      config.Path,
+				WriteIf:   describeVarField(config.DependsOn),
+				RenameTo:  describeVarField(config.RenameTo),
+				Blueprint: composed.Name,
+			})
+		}
+	}
+	return description, nil
+}
+
+// describeVarField returns the field as written in the blueprint definition, with its tag if any
+func describeVarField(field VarField) string {
+	value := field.Value
+	if field.Tag != "" {
+		value = fmt.Sprintf("%s %s", field.Tag, value)
+	}
+	if field.Label != "" {
+		value = fmt.Sprintf(optionTextFormat, field.Label, value)
+	}
+	if field.InvertBool && value != "" {
+		value = "!" + value
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueprintContext_DescribeBlueprint(t *testing.T) {
+	blueprintContext := getLocalTestBlueprintContext(t)
+
+	t.Run("should describe parameters and files of composed blueprints with overrides applied", func(t *testing.T) {
+		description, err := blueprintContext.DescribeBlueprint("composed")
+		require.Nil(t, err)
+		assert.Equal(t, "composed", description.Path)
+		assert.Equal(t, "Test Project", description.Name)
+		assert.Equal(t, []string{"valid-no-prompt", "composed", "defaults-as-values"}, description.ComposedBlueprints)
+
+		assert.Equal(t, ParameterDescription{
+			Name:      "TestFoo",
+			Value:     "hello",
+			PromptIf:  "!expr 3 > 2",
+			Blueprint: "valid-no-prompt",
+		}, description.Parameters[0])
+		assert.Contains(t, description.Parameters, ParameterDescription{
+			Name:      "ShouldNotBeThere",
+			Type:      "Input",
+			Prompt:    "Test Prompt",
+			Default:   "shouldnotbehere",
+			PromptIf:  "TestDepends2",
+			Blueprint: "defaults-as-values",
+		})
+		assert.Contains(t, description.Files, FileDescription{
+			Path:      "xlr-pipeline.yml",
+			WriteIf:   "!expr TestDepends",
+			RenameTo:  "xlr-pipeline-new.yml",
+			Blueprint: "valid-no-prompt",
+		})
+		assert.Contains(t, description.Files, FileDescription{
+			Path:      "xlr-pipeline-4.yml",
+			WriteIf:   "!expr TestDepends3",
+			Blueprint: "composed",
+		})
+	})
+
+	t.Run("should error when blueprint is not found", func(t *testing.T) {
+		_, err := blueprintContext.DescribeBlueprint("not-there")
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint [not-there] not found in repository Test", err.Error())
+	})
+}
+
+func Test_describeVarField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field VarField
+		want  string
+	}{
+		{"empty field", VarField{}, ""},
+		{"plain value", VarField{Value: "test"}, "test"},
+		{"expression", VarField{Value: "A == 'b'", Tag: tagExpressionV2}, "!expr A == 'b'"},
+		{"labeled option", VarField{Value: "us-west-1", Label: "US West"}, "US West [us-west-1]"},
+		{"inverted dependency", VarField{Value: "TestDepends", InvertBool: true}, "!TestDepends"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, describeVarField(tt.field))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package blueprint
+
+import (
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Statuses of the project files compared with a fresh rendering of the blueprint
+const (
+	DiffStatusAdded     = "added"
+	DiffStatusRemoved   = "removed"
+	DiffStatusModified  = "modified"
+	DiffStatusUnchanged = "unchanged"
+)
+
+const devNull = "/dev/null"
+
+// BlueprintDiff is the difference between the files on disk and a fresh rendering of the blueprint
+type BlueprintDiff struct {
+	Blueprint string       `json:"blueprint" yaml:"blueprint"`
+	Files     []DiffedFile `json:"files" yaml:"files"`
+}
+
+// DiffedFile is a project file along with how it differs from the rendered blueprint file, as a unified diff
+type DiffedFile struct {
+	Path   string `json:"path" yaml:"path"`
+	Status string `json:"status" yaml:"status"`
+	Diff   string `json:"-" yaml:"-"`
+}
+
+// HasChanges returns true when at least one of the files would be changed by generating the blueprint
+func (blueprintDiff *BlueprintDiff) HasChanges() bool {
+	for _, file := range blueprintDiff.Files {
+		if file.Status != DiffStatusUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDiff writes the unified diffs of all changed files
+func (blueprintDiff *BlueprintDiff) WriteDiff(w io.Writer) error {
+	for _, file := range blueprintDiff.Files {
+		if _, err := io.WriteString(w, file.Diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffBlueprint renders the blueprint in memory, the same way InstantiateBlueprint does, and compares it with the
+// files in the output root directory. Files that were generated before according to the blueprint manifest,
+// but are not rendered anymore, are reported as removed
+func DiffBlueprint(
+	params BlueprintParams,
+	blueprintContext *BlueprintContext,
+	overrideFns ExpressionOverrideFn,
+	surveyOpts ...survey.AskOpt,
+) (*BlueprintDiff, error) {
+	return diffBlueprint(newCLISession(surveyOpts...), params, blueprintContext, overrideFns)
+}
+
+func diffBlueprint(s *session, params BlueprintParams, blueprintContext *BlueprintContext, overrideFns ExpressionOverrideFn) (*BlueprintDiff, error) {
+	generatedBlueprint := &GeneratedBlueprint{OutputDir: models.BlueprintOutputDir}
+	// nothing is written, so there is nothing to confirm, as with a dry run
+	params.DryRun = true
+	preparedData, blueprintDoc, _, err := prepareBlueprint(s, &params, blueprintContext, generatedBlueprint, overrideFns)
+	if err != nil {
+		return nil, err
+	}
+	plannedFiles, err := planBlueprintFiles(blueprintContext, blueprintDoc, preparedData, generatedBlueprint, overrideFns)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprintDiff := &BlueprintDiff{Blueprint: params.TemplatePath}
+	renderedFiles := make(map[string]string)
+	for _, plannedFile := range plannedFiles {
+		if plannedFile.Action != PlanActionSkip {
+			// later files overwrite the ones generated earlier by a composed blueprint
+			renderedFiles[filepath.ToSlash(plannedFile.Path)] = plannedFile.Content
+		}
+	}
+	for path, content := range renderedFiles {
+		diffedFile, err := diffRenderedFile(generatedBlueprint, path, &content)
+		if err != nil {
+			return nil, err
+		}
+		blueprintDiff.Files = append(blueprintDiff.Files, *diffedFile)
+	}
+
+	projectDir := params.OutputRoot
+	if projectDir == "" {
+		projectDir = "."
+	}
+	if util.PathExists(filepath.Join(projectDir, models.BlueprintOutputDir, manifestFile), false) {
+		manifest, err := ReadBlueprintManifest(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifestFile := range manifest.Files {
+			if _, ok := renderedFiles[manifestFile.Path]; ok || !util.PathExists(generatedBlueprint.outputPath(manifestFile.Path), false) {
+				continue
+			}
+			diffedFile, err := diffRenderedFile(generatedBlueprint, manifestFile.Path, nil)
+			if err != nil {
+				return nil, err
+			}
+			blueprintDiff.Files = append(blueprintDiff.Files, *diffedFile)
+		}
+	} else {
+		util.Verbose("[diff] No blueprint manifest found in %s, files that are not rendered anymore are not reported\n", projectDir)
+	}
+
+	sort.Slice(blueprintDiff.Files, func(i, j int) bool {
+		return blueprintDiff.Files[i].Path < blueprintDiff.Files[j].Path
+	})
+	return blueprintDiff, nil
+}
+
+// diffRenderedFile compares the file on disk with the rendered content, nil content means the file is not rendered
+func diffRenderedFile(generatedBlueprint *GeneratedBlueprint, path string, renderedContent *string) (*DiffedFile, error) {
+	existingContent, err := readExistingOutputFile(generatedBlueprint, filepath.FromSlash(path))
+	if err != nil {
+		return nil, err
+	}
+
+	diffedFile := &DiffedFile{Path: path}
+	fromFile, toFile := "a/"+path, "b/"+path
+	from, to := "", ""
+	switch {
+	case existingContent == nil:
+		diffedFile.Status = DiffStatusAdded
+		fromFile = devNull
+		to = *renderedContent
+	case renderedContent == nil:
+		diffedFile.Status = DiffStatusRemoved
+		toFile = devNull
+		from = *existingContent
+	default:
+		diffedFile.Status = DiffStatusModified
+		from, to = *existingContent, *renderedContent
+	}
+
+	diffedFile.Diff, err = util.UnifiedDiff(from, to, fromFile, toFile)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(diffedFile.Diff) == "" && diffedFile.Status == DiffStatusModified {
+		diffedFile.Status = DiffStatusUnchanged
+	}
+	if path == filepath.ToSlash(filepath.Join(generatedBlueprint.OutputDir, secretsFile)) {
+		// secret values are not printed, only whether they change
+		diffedFile.Diff = ""
+	}
+	return diffedFile, nil
+}
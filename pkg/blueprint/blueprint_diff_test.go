@@ -0,0 +1,112 @@
+package blueprint
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diffBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Diffed
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+  files:
+  - path: app.yaml.tmpl
+  - path: notes.txt
+  - path: %s
+`
+
+func TestDiffBlueprint(t *testing.T) {
+	SkipFinalPrompt = true
+	repoDir, err := ioutil.TempDir("", "diffrepo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+	projectDir, err := ioutil.TempDir("", "diffproject")
+	require.Nil(t, err)
+	defer os.RemoveAll(projectDir)
+
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"diffed/blueprint.yaml": fmt.Sprintf(diffBlueprintYaml, "old.txt"),
+		"diffed/app.yaml.tmpl":  "name: {{.AppName}}\nport: 8080\n",
+		"diffed/notes.txt":      "hello\n",
+		"diffed/old.txt":        "old\n",
+	})
+	params := BlueprintParams{
+		TemplatePath:  "diffed",
+		AnswersMap:    map[string]string{"AppName": "my-app"},
+		StrictAnswers: true,
+		OutputRoot:    projectDir,
+	}
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+	_, _, err = InstantiateBlueprint(params, blueprintContext, &GeneratedBlueprint{OutputDir: "xebialabs"}, nil)
+	require.Nil(t, err)
+
+	t.Run("should report no changes for a freshly generated project", func(t *testing.T) {
+		blueprintDiff, err := DiffBlueprint(params, blueprintContext, nil)
+		require.Nil(t, err)
+		assert.False(t, blueprintDiff.HasChanges())
+	})
+
+	t.Run("should not ask for confirmation", func(t *testing.T) {
+		answers := &testAnswerProvider{}
+		s := &session{logger: discardLogger{}, answers: answers, out: ioutil.Discard}
+		// strict answers skip the confirmation on their own
+		nonStrictParams := params
+		nonStrictParams.StrictAnswers = false
+		blueprintDiff, err := diffBlueprint(s, nonStrictParams, blueprintContext, nil)
+		require.Nil(t, err)
+		assert.False(t, blueprintDiff.HasChanges())
+		assert.Empty(t, answers.questions)
+	})
+
+	t.Run("should report modified, added and removed files", func(t *testing.T) {
+		writeUpgradeTestRepo(t, repoDir, map[string]string{
+			"diffed/blueprint.yaml": fmt.Sprintf(diffBlueprintYaml, "new.txt"),
+			"diffed/app.yaml.tmpl":  "name: {{.AppName}}\nport: 9090\n",
+			"diffed/new.txt":        "new\n",
+		})
+		blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+		require.Nil(t, err)
+
+		blueprintDiff, err := DiffBlueprint(params, blueprintContext, nil)
+		require.Nil(t, err)
+		assert.True(t, blueprintDiff.HasChanges())
+
+		statuses := make(map[string]string)
+		for _, file := range blueprintDiff.Files {
+			statuses[file.Path] = file.Status
+		}
+		assert.Equal(t, map[string]string{
+			"app.yaml":                 DiffStatusModified,
+			"notes.txt":                DiffStatusUnchanged,
+			"new.txt":                  DiffStatusAdded,
+			"old.txt":                  DiffStatusRemoved,
+			"xebialabs/.gitignore":     DiffStatusUnchanged,
+			"xebialabs/secrets.xlvals": DiffStatusUnchanged,
+			"xebialabs/values.xlvals":  DiffStatusUnchanged,
+		}, statuses)
+
+		out := &bytes.Buffer{}
+		require.Nil(t, blueprintDiff.WriteDiff(out))
+		assert.Equal(t, "--- a/app.yaml\n+++ b/app.yaml\n@@ -1,2 +1,2 @@\n name: my-app\n-port: 8080\n\\ No newline at end of file\n+port: 9090\n\\ No newline at end of file\n"+
+			"--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+new\n"+
+			"--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n", out.String())
+
+		// nothing is written
+		assert.Equal(t, "name: my-app\nport: 8080", GetFileContent(filepath.Join(projectDir, "app.yaml")))
+		assert.False(t, fileExists(filepath.Join(projectDir, "new.txt")))
+	})
+}
@@ -55,6 +55,10 @@ type PreparedData struct {
 	Values map[string]interface{}
 	// Used to store data to be saved in secrets.xlvals
 	Secrets map[string]interface{}
+	// Used to store the answers given for non-secret fields, as they would be written in an answers file
+	Answers map[string]interface{}
+	// Used to store the answers given for secret fields, as they would be written in an answers file
+	SecretAnswers map[string]interface{}
 }
 
 func NewPreparedData() *PreparedData {
@@ -62,20 +66,26 @@ func NewPreparedData() *PreparedData {
 	summaryData := make(map[string]interface{})
 	values := make(map[string]interface{})
 	secrets := make(map[string]interface{})
-	return &PreparedData{TemplateData: templateData, SummaryData: summaryData, Values: values, Secrets: secrets}
+	answers := make(map[string]interface{})
+	secretAnswers := make(map[string]interface{})
+	return &PreparedData{TemplateData: templateData, SummaryData: summaryData, Values: values, Secrets: secrets, Answers: answers, SecretAnswers: secretAnswers}
 }
 
 // regular Expressions
 var regExFn = regexp.MustCompile(`([\w\d]+).([\w\d]+)\(([,/\-:\s\w\d]*)\)(?:\.([\w\d]*)|\[([\d]+)\])*`)
 
 func GetProcessedExpressionValue(val VarField, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (VarField, error) {
+	return getProcessedExpressionValue(cliLogger{}, val, parameters, overrideFns)
+}
+
+func getProcessedExpressionValue(logger Logger, val VarField, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (VarField, error) {
 	switch val.Tag {
 	case tagExpressionV1, tagExpressionV2:
-		procVal, err := ProcessCustomExpression(val.Value, parameters, overrideFns)
+		procVal, err := processCustomExpression(logger, val.Value, parameters, overrideFns)
 		if err != nil {
 			return val, err
 		}
-		util.Verbose("[expression] Processed value of expression [%s] is: %s\n", val.Value, procVal)
+		logger.Verbose("[expression] Processed value of expression [%s] is: %s\n", val.Value, procVal)
 		switch finalVal := procVal.(type) {
 		case string:
 			val.Value = finalVal
@@ -98,11 +108,19 @@ func GetProcessedExpressionValue(val VarField, parameters map[string]interface{}
 }
 
 func (variable *Variable) ProcessExpression(parameters map[string]interface{}, overrideFns ExpressionOverrideFn) error {
+	return variable.processExpression(cliLogger{}, parameters, overrideFns)
+}
+
+func (variable *Variable) processExpression(logger Logger, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) error {
 	fieldsToSkip := []string{"Validate", "Options"} // these fields have special processing
-	return ProcessExpressionField(variable, fieldsToSkip, parameters, variable.Name.Value, overrideFns)
+	return processExpressionField(logger, variable, fieldsToSkip, parameters, variable.Name.Value, overrideFns)
 }
 
 func ProcessExpressionField(item interface{}, fieldsToSkip []string, parameters map[string]interface{}, id string, overrideFns ExpressionOverrideFn) error {
+	return processExpressionField(cliLogger{}, item, fieldsToSkip, parameters, id, overrideFns)
+}
+
+func processExpressionField(logger Logger, item interface{}, fieldsToSkip []string, parameters map[string]interface{}, id string, overrideFns ExpressionOverrideFn) error {
 	itemR := reflect.ValueOf(item).Elem()
 	typeOfT := itemR.Type()
 	// iterate over the struct fields and map them
@@ -114,7 +132,7 @@ func ProcessExpressionField(item interface{}, fieldsToSkip []string, parameters
 		if !util.IsStringInSlice(fieldName, fieldsToSkip) && field.IsValid() {
 			switch val := value.(type) {
 			case VarField:
-				procVal, err := GetProcessedExpressionValue(val, parameters, overrideFns)
+				procVal, err := getProcessedExpressionValue(logger, val, parameters, overrideFns)
 				if err != nil {
 					return fmt.Errorf("error while processing !expr [%s] for [%s] of [%s]. %s", val.Value, fieldName, id, err.Error())
 				}
@@ -127,19 +145,23 @@ func ProcessExpressionField(item interface{}, fieldsToSkip []string, parameters
 
 // GetDefaultVal variable struct functions
 func (variable *Variable) GetDefaultVal() interface{} {
+	return variable.getDefaultVal(cliLogger{})
+}
+
+func (variable *Variable) getDefaultVal(logger Logger) interface{} {
 	defaultVal := variable.Default.Value
 	switch variable.Default.Tag {
 	case tagFnV1:
-		values, err := ProcessCustomFunction(defaultVal)
+		values, err := processCustomFunction(logger, defaultVal)
 		if err != nil {
-			util.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
+			logger.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
 			defaultVal = ""
 		} else {
-			util.Verbose("[fn] Processed value of function [%s] is: %s\n", defaultVal, values[0])
+			logger.Verbose("[fn] Processed value of function [%s] is: %s\n", defaultVal, values[0])
 			if variable.Type.Value == TypeConfirm {
 				boolVal, err := strconv.ParseBool(values[0])
 				if err != nil {
-					util.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
+					logger.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
 					return false
 				}
 				variable.Default.Bool = boolVal
@@ -153,18 +175,22 @@ func (variable *Variable) GetDefaultVal() interface{} {
 }
 
 func (variable *Variable) GetValueFieldVal() interface{} {
+	return variable.getValueFieldVal(cliLogger{})
+}
+
+func (variable *Variable) getValueFieldVal(logger Logger) interface{} {
 	switch variable.Value.Tag {
 	case tagFnV1:
-		values, err := ProcessCustomFunction(variable.Value.Value)
+		values, err := processCustomFunction(logger, variable.Value.Value)
 		if err != nil {
-			util.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s", variable.Value.Value, variable.Name.Value, err.Error())
+			logger.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s", variable.Value.Value, variable.Name.Value, err.Error())
 			return ""
 		}
-		util.Verbose("[fn] Processed value of function [%s] is: %s\n", variable.Value.Value, values[0])
+		logger.Verbose("[fn] Processed value of function [%s] is: %s\n", variable.Value.Value, values[0])
 		if variable.Type.Value == TypeConfirm {
 			boolVal, err := strconv.ParseBool(values[0])
 			if err != nil {
-				util.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s", variable.Value.Value, variable.Name.Value, err.Error())
+				logger.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s", variable.Value.Value, variable.Name.Value, err.Error())
 				return ""
 			}
 			variable.Value.Bool = boolVal
@@ -176,40 +202,44 @@ func (variable *Variable) GetValueFieldVal() interface{} {
 }
 
 func (variable *Variable) GetOptions(parameters map[string]interface{}, withLabel bool, overrideFns ExpressionOverrideFn) []string {
+	return variable.getOptions(cliLogger{}, parameters, withLabel, overrideFns)
+}
+
+func (variable *Variable) getOptions(logger Logger, parameters map[string]interface{}, withLabel bool, overrideFns ExpressionOverrideFn) []string {
 	options := []string{}
 	for _, option := range variable.Options {
 		switch option.Tag {
 		case tagFnV1:
-			opts, err := ProcessCustomFunction(option.Value)
+			opts, err := processCustomFunction(logger, option.Value)
 			if err != nil {
-				util.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, err.Error())
+				logger.Info("Error while processing !fn [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, err.Error())
 				return options
 			}
-			util.Verbose("[fn] Processed value of function [%s] is: %s\n", option.Value, opts)
+			logger.Verbose("[fn] Processed value of function [%s] is: %s\n", option.Value, opts)
 			options = append(options, opts...)
 		case tagExpressionV1, tagExpressionV2:
-			opts, err := ProcessCustomExpression(option.Value, parameters, overrideFns)
+			opts, err := processCustomExpression(logger, option.Value, parameters, overrideFns)
 			if err != nil {
-				util.Info("Error while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, err.Error())
+				logger.Info("Error while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, err.Error())
 				return options
 			}
 			switch val := opts.(type) {
 			case []string:
-				util.Verbose("[expression] Processed value of expression [%s] is: %v\n", option.Value, val)
+				logger.Verbose("[expression] Processed value of expression [%s] is: %v\n", option.Value, val)
 				if len(val) == 0 {
-					util.Info("Empty response while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Empty array returned.")
+					logger.Info("Empty response while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Empty array returned.")
 				}
 				options = append(options, val...)
 			case []interface{}:
-				util.Verbose("[expression] Processed value of expression [%s] is: %v\n", option.Value, val)
+				logger.Verbose("[expression] Processed value of expression [%s] is: %v\n", option.Value, val)
 				if len(val) == 0 {
-					util.Info("Empty response while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Empty array returned.")
+					logger.Info("Empty response while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Empty array returned.")
 				}
 				for _, option := range val {
 					options = append(options, fmt.Sprint(option))
 				}
 			default:
-				util.Info("Error while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Return type should be a string array.")
+				logger.Info("Error while processing !expr [%s]. Please update the value for [%s] manually. %s\n", option.Value, variable.Name.Value, "Return type should be a string array.")
 				return options
 			}
 		default:
@@ -279,13 +309,13 @@ func (variable *Variable) GetValidateExpr() (string, error) {
 	return "", fmt.Errorf("only '!expr' tag is supported for validate attribute")
 }
 
-func validateField(validateExpr string, variable *Variable, parameters map[string]interface{}, value interface{}, overrideFns ExpressionOverrideFn) error {
+func validateField(logger Logger, validateExpr string, variable *Variable, parameters map[string]interface{}, value interface{}, overrideFns ExpressionOverrideFn) error {
 	if validateExpr != "" {
 		allowEmpty := false
 		if IsSecretType(variable.Type.Value) || variable.AllowEmpty.Bool {
 			allowEmpty = true
 		}
-		validationErr := validatePrompt(variable.Name.Value, validateExpr, allowEmpty, parameters, overrideFns)(value)
+		validationErr := validatePrompt(logger, variable.Name.Value, validateExpr, allowEmpty, parameters, overrideFns)(value)
 		if validationErr != nil {
 			return fmt.Errorf("validation error for answer value [%v] for variable [%s]: %s", value, variable.Name.Value, validationErr.Error())
 		}
@@ -294,6 +324,10 @@ func validateField(validateExpr string, variable *Variable, parameters map[strin
 }
 
 func (variable *Variable) VerifyVariableValue(value interface{}, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (interface{}, error) {
+	return variable.verifyVariableValue(cliLogger{}, value, parameters, overrideFns)
+}
+
+func (variable *Variable) verifyVariableValue(logger Logger, value interface{}, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (interface{}, error) {
 	// get validate expression
 	validateExpr, err := variable.GetValidateExpr()
 	if err != nil {
@@ -323,8 +357,8 @@ func (variable *Variable) VerifyVariableValue(value interface{}, parameters map[
 		return answerBool, nil
 	case TypeSelect:
 		// check if answer is one of the options, error if not
-		options := variable.GetOptions(parameters, false, overrideFns)
-		util.Verbose("[input] Select options verify for %s: \n%+v\n", variable.Name.Value, options)
+		options := variable.getOptions(logger, parameters, false, overrideFns)
+		logger.Verbose("[input] Select options verify for %s: \n%+v\n", variable.Name.Value, options)
 		answerStr := fmt.Sprintf("%v", value)
 		if !funk.Contains(options, answerStr) {
 			return "", fmt.Errorf("answer [%s] is not one of the available options %v for variable [%s]", answerStr, options, variable.Name.Value)
@@ -332,13 +366,13 @@ func (variable *Variable) VerifyVariableValue(value interface{}, parameters map[
 		return answerStr, nil
 	case TypeFile, TypeSecretFile:
 		// do validation if needed
-		err := validateField(validateExpr, variable, parameters, value, overrideFns)
+		err := validateField(logger, validateExpr, variable, parameters, value, overrideFns)
 		if err != nil {
 			return "", err
 		}
 		// read file contents
 		filePath := value.(string)
-		util.Verbose("[input] File path %s\n", filePath)
+		logger.Verbose("[input] File path %s\n", filePath)
 		data, err := ioutil.ReadFile(filePath)
 		if err != nil {
 			return "", fmt.Errorf("error reading input file [%s]: %s", filePath, err.Error())
@@ -346,7 +380,7 @@ func (variable *Variable) VerifyVariableValue(value interface{}, parameters map[
 		return string(data), nil
 	default:
 		// do validation if needed
-		err := validateField(validateExpr, variable, parameters, value, overrideFns)
+		err := validateField(logger, validateExpr, variable, parameters, value, overrideFns)
 		if err != nil {
 			return "", err
 		}
@@ -362,6 +396,11 @@ func (variable *Variable) GetHelpText() string {
 }
 
 func (variable *Variable) GetUserInput(defaultVal interface{}, parameters map[string]interface{}, overrideFns ExpressionOverrideFn, surveyOpts ...survey.AskOpt) (interface{}, error) {
+	return variable.askUserInput(cliLogger{}, NewSurveyAnswerProvider(surveyOpts...), defaultVal, parameters, overrideFns)
+}
+
+// askUserInput asks the value of the variable from the answer provider
+func (variable *Variable) askUserInput(logger Logger, answers AnswerProvider, defaultVal interface{}, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (interface{}, error) {
 	var answer string
 	var err error
 	defaultValStr := fmt.Sprintf("%v", defaultVal)
@@ -372,112 +411,73 @@ func (variable *Variable) GetUserInput(defaultVal interface{}, parameters map[st
 		return nil, fmt.Errorf("error getting validation expression: %s", err.Error())
 	}
 
+	question := Question{
+		Name:    variable.Name.Value,
+		Type:    variable.Type.Value,
+		Message: prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the value of %s?", variable.Name.Value)),
+		Help:    variable.GetHelpText(),
+		Default: defaultValStr,
+	}
 	switch variable.Type.Value {
 	case TypeInput:
-		surveyOpts = append(surveyOpts, survey.WithValidator(validatePrompt(variable.Name.Value, validateExpr, variable.AllowEmpty.Bool, parameters, overrideFns)))
-		err = survey.AskOne(
-			&survey.Input{
-				Message: prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the value of %s?", variable.Name.Value)),
-				Default: defaultValStr,
-				Help:    variable.GetHelpText(),
-			},
-			&answer,
-			surveyOpts...,
-		)
+		question.Validate = validatePrompt(logger, variable.Name.Value, validateExpr, variable.AllowEmpty.Bool, parameters, overrideFns)
+		answer, err = askString(answers, question)
 	case TypeSecret:
-		questionMsg := prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the value of %s?", variable.Name.Value))
 		if defaultVal != "" {
-			questionMsg += fmt.Sprintf(" (%s)", defaultVal)
-		}
-		surveyOpts = append(surveyOpts, survey.WithValidator(validatePrompt(variable.Name.Value, validateExpr, true, parameters, overrideFns)))
-		err = survey.AskOne(
-			&survey.Password{
-				Message: questionMsg,
-				Help:    variable.GetHelpText(),
-			},
-			&answer,
-			surveyOpts...,
-		)
+			question.Message += fmt.Sprintf(" (%s)", defaultVal)
+		}
+		question.Validate = validatePrompt(logger, variable.Name.Value, validateExpr, true, parameters, overrideFns)
+		answer, err = askString(answers, question)
 
 		// if user bypassed question, replace with default value
 		if answer == "" {
-			util.Verbose("[input] Got empty response for secret field '%s', replacing with default value: %s\n", variable.Name.Value, defaultVal)
+			logger.Verbose("[input] Got empty response for secret field '%s', replacing with default value: %s\n", variable.Name.Value, defaultVal)
 			answer = defaultValStr
 		}
 	case TypeEditor, TypeSecretEditor:
-		questionMsg := prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the value of %s?", variable.Name.Value))
-		surveyOpts = append(surveyOpts, survey.WithValidator(validatePrompt(variable.Name.Value, validateExpr, false, parameters, overrideFns)))
-		err = survey.AskOne(
-			&survey.Editor{
-				Message:       questionMsg,
-				Default:       defaultValStr,
-				HideDefault:   true,
-				AppendDefault: true,
-				Help:          variable.GetHelpText(),
-			},
-			&answer,
-			surveyOpts...,
-		)
+		question.Validate = validatePrompt(logger, variable.Name.Value, validateExpr, false, parameters, overrideFns)
+		answer, err = askString(answers, question)
 		// if user bypassed question, replace with default value
 		if answer == "" {
-			util.Verbose("[input] Got empty response for secret field '%s', replacing with default value: %s\n", variable.Name.Value, defaultVal)
+			logger.Verbose("[input] Got empty response for secret field '%s', replacing with default value: %s\n", variable.Name.Value, defaultVal)
 			answer = defaultValStr
 		}
 	case TypeFile, TypeSecretFile:
 		var filePath string
-		surveyOpts = append(surveyOpts, survey.WithValidator(validateFilePath(variable.Name.Value, validateExpr, false, parameters, overrideFns)))
-		err = survey.AskOne(
-			&survey.Input{
-				Message: prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the file path (relative/absolute) for %s?", variable.Name.Value)),
-				Default: defaultValStr,
-				Help:    variable.GetHelpText(),
-			},
-			&filePath,
-			surveyOpts...,
-		)
+		question.Message = prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("What is the file path (relative/absolute) for %s?", variable.Name.Value))
+		question.Validate = validateFilePath(logger, variable.Name.Value, validateExpr, false, parameters, overrideFns)
+		filePath, err = askString(answers, question)
 		filePath = strings.TrimSpace(filePath)
+		variable.Meta.FilePath = filePath
 		// read file contents & save as answer
-		util.Verbose("[input] Reading file contents from path: %s\n", filePath)
+		logger.Verbose("[input] Reading file contents from path: %s\n", filePath)
 		data, err := getFileContents(filePath)
 		if err != nil {
 			return "", err
 		}
 		answer = string(data)
 	case TypeSelect:
-		options := variable.GetOptions(parameters, true, overrideFns)
-		surveyOpts = append(surveyOpts, survey.WithValidator(validatePrompt(variable.Name.Value, validateExpr, false, parameters, overrideFns)))
+		options := variable.getOptions(logger, parameters, true, overrideFns)
 		defaultValue := getDefaultTextWithLabel(defaultValStr, variable.Options, options)
-		util.Verbose("[input] Select options prompt for %s with default value '%s' \n%+v\n", variable.Name.Value, defaultValue, options)
-		err = survey.AskOne(
-			&survey.Select{
-				Message:  prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("Select value for %s?", variable.Name.Value)),
-				Options:  options,
-				Default:  defaultValue,
-				PageSize: 10,
-				Help:     variable.GetHelpText(),
-			},
-			&answer,
-			surveyOpts...,
-		)
+		logger.Verbose("[input] Select options prompt for %s with default value '%s' \n%+v\n", variable.Name.Value, defaultValue, options)
+		question.Message = prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("Select value for %s?", variable.Name.Value))
+		question.Options = options
+		question.Default = defaultValue
+		question.Validate = validatePrompt(logger, variable.Name.Value, validateExpr, false, parameters, overrideFns)
+		answer, err = askString(answers, question)
 		if err != nil {
 			return nil, fmt.Errorf("error rendering '%s', for the field %s: %s", variable.Prompt.Value, variable.Name.Value, err.Error())
 		}
 		answer = findLabelValueFromOptions(answer, variable.Options)
 	case TypeConfirm:
-		var confirm bool
-		surveyOpts = append(surveyOpts, survey.WithValidator(validatePrompt(variable.Name.Value, validateExpr, false, parameters, overrideFns)))
-		err = survey.AskOne(
-			&survey.Confirm{
-				Message: prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("%s?", variable.Name.Value)),
-				Default: variable.Default.Bool,
-				Help:    variable.GetHelpText(),
-			},
-			&confirm,
-			surveyOpts...,
-		)
+		question.Message = prepareQuestionText(variable.Prompt.Value, fmt.Sprintf("%s?", variable.Name.Value))
+		question.Default = variable.Default.Bool
+		question.Validate = validatePrompt(logger, variable.Name.Value, validateExpr, false, parameters, overrideFns)
+		confirmAnswer, err := answers.Ask(question)
 		if err != nil {
 			return "", err
 		}
+		confirm, _ := confirmAnswer.(bool)
 		variable.Value.Bool = confirm
 		// TypeConfirm returns a boolean type
 		return confirm, nil
@@ -486,53 +486,78 @@ func (variable *Variable) GetUserInput(defaultVal interface{}, parameters map[st
 	return strings.TrimSpace(answer), err
 }
 
-// validate blueprint yaml document based on required fields
-func (blueprintDoc *BlueprintConfig) validate() error {
-	if !util.IsStringInSlice(blueprintDoc.ApiVersion, models.BlueprintYamlFormatSupportedVersions) {
-		return fmt.Errorf("api version needs to be %s or %s", models.BlueprintYamlFormatV2, models.BlueprintYamlFormatV1)
+// askString asks the question from the answer provider and returns the answer as a string
+func askString(answers AnswerProvider, question Question) (string, error) {
+	answer, err := answers.Ask(question)
+	if err != nil || answer == nil {
+		return "", err
 	}
-	if blueprintDoc.ApiVersion != models.BlueprintYamlFormatV2 {
-		util.Info("This blueprint uses a deprecated blueprint.yaml schema for apiVersion %s\n", models.BlueprintYamlFormatV1)
+	return fmt.Sprintf("%v", answer), nil
+}
+
+// firstError returns the first of the errors, or nil when there is none
+func firstError(errs []error) error {
+	if len(errs) > 0 {
+		return errs[0]
 	}
-	if blueprintDoc.Kind != models.BlueprintSpecKind {
-		return fmt.Errorf("yaml document kind needs to be %s", models.BlueprintSpecKind)
+	return nil
+}
+
+// validationErrors returns all the problems found while validating the blueprint yaml document, in the order of validation
+func (blueprintDoc *BlueprintConfig) validationErrors() []error {
+	if !util.IsStringInSlice(blueprintDoc.ApiVersion, models.BlueprintYamlFormatSupportedVersions) {
+		return []error{fmt.Errorf("api version needs to be %s or %s", models.BlueprintYamlFormatV2, models.BlueprintYamlFormatV1)}
 	}
-	err := validateVariables(&blueprintDoc.Variables)
-	if err != nil {
-		return err
+	var errs []error
+	if blueprintDoc.Kind != models.BlueprintSpecKind {
+		errs = append(errs, fmt.Errorf("yaml document kind needs to be %s", models.BlueprintSpecKind))
 	}
-	return validateFiles(&blueprintDoc.TemplateConfigs)
+	errs = append(errs, validateVariables(&blueprintDoc.Variables)...)
+	return append(errs, validateFiles(&blueprintDoc.TemplateConfigs)...)
 }
 
 // prepare template data by getting user input and calling named functions
-func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams, data *PreparedData, overrideFns ExpressionOverrideFn, surveyOpts ...survey.AskOpt) (*PreparedData, error) {
+func (blueprintDoc *BlueprintConfig) prepareTemplateData(s *session, params BlueprintParams, data *PreparedData, overrideFns ExpressionOverrideFn) (*PreparedData, error) {
 	// if exists, get map of answers from file
 	var answerMap map[string]string
 	var err error
 	usingAnswersFile := false
-	if params.AnswersFile != "" || params.AnswersMap != nil {
-		if params.AnswersMap != nil {
-			util.Verbose("[dataPrep] Using answers map (strict: %t) instead of asking questions from console\n", params.StrictAnswers)
-			answerMap = params.AnswersMap
-		} else {
+	envAnswers := GetValuesFromEnv(params.AnswersEnvPrefix)
+	if params.AnswersFile != "" || params.AnswersMap != nil || len(envAnswers) > 0 {
+		answerMap = make(map[string]string)
+		if params.AnswersFile != "" {
 			// parse answers file
-			util.Verbose("[dataPrep] Using answers file [%s] (strict: %t) instead of asking questions from console\n", params.AnswersFile, params.StrictAnswers)
+			s.logger.Verbose("[dataPrep] Using answers file [%s] (strict: %t) instead of asking questions from console\n", params.AnswersFile, params.StrictAnswers)
 			answerMap, err = GetValuesFromAnswersFile(params.AnswersFile)
 			if err != nil {
 				return nil, err
 			}
 		}
+		// answers from environment variables take precedence over the answers file
+		if len(envAnswers) > 0 {
+			s.logger.Verbose("[dataPrep] Using %d answers from environment variables with prefix [%s]\n", len(envAnswers), params.AnswersEnvPrefix)
+			for k, v := range envAnswers {
+				answerMap[k] = v
+			}
+		}
+		// answers map takes precedence over the answers file and environment variables
+		if params.AnswersMap != nil {
+			s.logger.Verbose("[dataPrep] Using answers map (strict: %t) instead of asking questions from console\n", params.StrictAnswers)
+			for k, v := range params.AnswersMap {
+				answerMap[k] = v
+			}
+		}
 
 		// skip final prompt if in strict answers mode
 		if params.StrictAnswers {
-			SkipFinalPrompt = true
+			s.skipFinalPrompt = true
 		}
 		usingAnswersFile = true
 	}
 
 	// for every variable defined in blueprint.yaml file
 	for i, variable := range blueprintDoc.Variables {
-		variable.ProcessExpression(data.TemplateData, overrideFns)
+		variable.processExpression(s.logger, data.TemplateData, overrideFns)
 		var defaultVal interface{}
 		// override the default value if its passed and if the param is overridable.
 		if variable.OverrideDefault.Bool && util.MapContainsKeyWithVal(params.OverrideDefaults, variable.Name.Value) {
@@ -540,7 +565,7 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 			if variable.Type.Value == TypeConfirm {
 				boolVal, err := strconv.ParseBool(params.OverrideDefaults[variable.Name.Value])
 				if err != nil {
-					util.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
+					s.logger.Info("Error while processing default value !fn [%s] for [%s]. %s", defaultVal, variable.Name.Value, err.Error())
 					return nil, err
 				}
 				variable.Default.Bool = boolVal
@@ -551,17 +576,17 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 			}
 		} else {
 			// process default field value
-			defaultVal = variable.GetDefaultVal()
+			defaultVal = variable.getDefaultVal(s.logger)
 		}
 
 		// skip question based on DependsOn fields, the provided answer else the default value if present is set as value
 		isSkippedWithAnswer := false
 		if !util.IsStringEmpty(variable.DependsOn.Value) {
-			dependsOnVal, err := ParseDependsOnValue(variable.DependsOn, data.TemplateData)
+			dependsOnVal, err := parseDependsOnValue(s.logger, variable.DependsOn, data.TemplateData)
 			if err != nil {
 				return nil, err
 			}
-			if skipQuestionOnCondition(&variable, variable.DependsOn.Value, dependsOnVal, data, defaultVal, variable.DependsOn.InvertBool) {
+			if skipQuestionOnCondition(s.logger, &variable, variable.DependsOn.Value, dependsOnVal, data, defaultVal, variable.DependsOn.InvertBool) {
 				// If answer is provided for this variable, fall through to the answers file block below
 				// where provided answer is used as the answer for the variable instead of default value
 				if usingAnswersFile && util.MapContainsKeyWithVal(answerMap, variable.Name.Value) {
@@ -573,19 +598,19 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 		}
 		// skip user input if value field is present
 		if variable.Value.Value != "" {
-			parsedVal := variable.GetValueFieldVal()
+			parsedVal := variable.getValueFieldVal(s.logger)
 
 			// check if resulting value is non-empty
 			if parsedVal != nil && parsedVal != "" {
 				if variable.Type.Value == TypeConfirm {
-					saveItemToTemplateDataMap(&variable, data, variable.Value.Bool)
+					saveItemToTemplateDataMap(s.logger, &variable, data, variable.Value.Bool)
 				} else {
-					saveItemToTemplateDataMap(&variable, data, parsedVal)
+					saveItemToTemplateDataMap(s.logger, &variable, data, parsedVal)
 				}
-				util.Verbose("[dataPrep] Skipping question for parameter [%s] because value [%s] is present\n", variable.Name.Value, variable.Value.Value)
+				s.logger.Verbose("[dataPrep] Skipping question for parameter [%s] because value [%s] is present\n", variable.Name.Value, variable.Value.Value)
 				continue
 			} else {
-				util.Verbose("[dataPrep] Parsed value for parameter [%s] is empty, therefore not being skipped\n", variable.Name.Value)
+				s.logger.Verbose("[dataPrep] Parsed value for parameter [%s] is empty, therefore not being skipped\n", variable.Name.Value)
 			}
 		}
 
@@ -596,7 +621,7 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 				if isSkippedWithAnswer {
 					answer = answerMap[variable.Name.Value]
 				} else {
-					answer, err = variable.VerifyVariableValue(answerMap[variable.Name.Value], data.TemplateData, overrideFns)
+					answer, err = variable.verifyVariableValue(s.logger, answerMap[variable.Name.Value], data.TemplateData, overrideFns)
 					if err != nil {
 						return nil, err
 					}
@@ -606,20 +631,25 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 					blueprintDoc.Variables[i] = variable
 				}
 				// if we have a valid answer, save it and skip user input
-				saveItemToTemplateDataMap(&variable, data, answer)
-				util.Info("[dataPrep] Using answer file value [%v] for variable [%s]\n", answer, variable.Name.Value)
+				saveItemToTemplateDataMap(s.logger, &variable, data, answer)
+				saveAnswerToPreparedData(&variable, data, answerMap[variable.Name.Value])
+				if IsSecretType(variable.Type.Value) {
+					s.logger.Info("[dataPrep] Using answer file value [*****] for variable [%s]\n", variable.Name.Value)
+				} else {
+					s.logger.Info("[dataPrep] Using answer file value [%v] for variable [%s]\n", answer, variable.Name.Value)
+				}
 				continue
 			}
 		}
 
 		// skip user input if it is in default mode and default value is present
 		if params.UseDefaultsAsValue && defaultVal != nil && defaultVal != "" {
-			finalVal, err := variable.VerifyVariableValue(defaultVal, data.TemplateData, overrideFns)
+			finalVal, err := variable.verifyVariableValue(s.logger, defaultVal, data.TemplateData, overrideFns)
 			if err != nil {
 				return nil, err
 			}
 
-			util.Verbose(
+			s.logger.Verbose(
 				"[dataPrep] Use Defaults as Value mode: Skipping question for parameter [%s] because default value [%v] is present\n",
 				variable.Name.Value,
 				finalVal,
@@ -627,9 +657,18 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 			if variable.Type.Value == TypeConfirm {
 				blueprintDoc.Variables[i] = variable
 			}
-			saveItemToTemplateDataMap(&variable, data, finalVal)
+			saveItemToTemplateDataMap(s.logger, &variable, data, finalVal)
+			saveAnswerToPreparedData(&variable, data, defaultVal)
+			continue
+		}
+		// nothing is asked in non-interactive mode, parameters without an answer are collected to be reported at once
+		if params.NonInteractive && shouldAskForInput(variable, s.skipUserInput) {
+			s.missingAnswers = append(s.missingAnswers, MissingAnswer{Name: variable.Name.Value, Prompt: variable.Prompt.Value, Type: variable.Type.Value})
+			// continue with the default value, so that the parameters depending on this one can still be evaluated
+			saveItemToTemplateDataMap(s.logger, &variable, data, defaultVal)
 			continue
 		}
+
 		// do not return error when in non-strict answers mode, instead ask user input for the variable value
 		if usingAnswersFile && params.StrictAnswers && !variable.IgnoreIfSkipped.Bool {
 			return nil, fmt.Errorf("variable with name [%s] could not be found in answers file", variable.Name.Value)
@@ -640,10 +679,10 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 		// * if value field is not present
 		// * if not in default mode and default value is present
 		// * if answers file is not present or isPartial is set to TRUE and answer not found on file for the variable
-		util.Verbose("[dataPrep] Processing template variable [Name: %s, Type: %s]\n", variable.Name.Value, variable.Type.Value)
+		s.logger.Verbose("[dataPrep] Processing template variable [Name: %s, Type: %s]\n", variable.Name.Value, variable.Type.Value)
 		var answer interface{}
-		if shouldAskForInput(variable) {
-			answer, err = variable.GetUserInput(defaultVal, data.TemplateData, overrideFns, surveyOpts...)
+		if shouldAskForInput(variable, s.skipUserInput) {
+			answer, err = variable.askUserInput(s.logger, s.answers, defaultVal, data.TemplateData, overrideFns)
 		}
 		if err != nil {
 			return nil, err
@@ -651,14 +690,19 @@ func (blueprintDoc *BlueprintConfig) prepareTemplateData(params BlueprintParams,
 		if variable.Type.Value == TypeConfirm {
 			blueprintDoc.Variables[i] = variable
 		}
-		saveItemToTemplateDataMap(&variable, data, answer)
+		saveItemToTemplateDataMap(s.logger, &variable, data, answer)
+		if answer != nil && (variable.Type.Value == TypeFile || variable.Type.Value == TypeSecretFile) {
+			saveAnswerToPreparedData(&variable, data, variable.Meta.FilePath)
+		} else if answer != nil {
+			saveAnswerToPreparedData(&variable, data, answer)
+		}
 	}
 
 	return data, nil
 }
 
-func shouldAskForInput(variable Variable) bool {
-	if SkipUserInput {
+func shouldAskForInput(variable Variable, skipUserInput bool) bool {
+	if skipUserInput {
 		return false
 	}
 	if variable.IgnoreIfSkipped.Bool {
@@ -676,15 +720,84 @@ func GetValuesFromAnswersFile(answersFilePath string) (map[string]string, error)
 			return nil, err
 		}
 
-		// parse answers file
-		answers := make(map[string]string)
-		err = yaml.Unmarshal(content, answers)
+		return parseAnswers(content)
+	}
+	return nil, fmt.Errorf("blueprint answers file not found in path %s", answersFilePath)
+}
+
+// GetValuesFromSetFlags parses answers given as key=value pairs and key=path pairs, where the value is read from the file at path.
+// When the same key is given more than once, the last key=value pair wins and key=value pairs take precedence over key=path pairs
+func GetValuesFromSetFlags(setValues []string, setFiles []string) (map[string]string, error) {
+	answers := make(map[string]string)
+	for _, setFile := range setFiles {
+		key, filePath, err := splitSetFlag(setFile)
 		if err != nil {
 			return nil, err
 		}
-		return answers, nil
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read value of answer [%s] from file %s: %s", key, filePath, err.Error())
+		}
+		answers[key] = string(content)
 	}
-	return nil, fmt.Errorf("blueprint answers file not found in path %s", answersFilePath)
+	for _, setValue := range setValues {
+		key, value, err := splitSetFlag(setValue)
+		if err != nil {
+			return nil, err
+		}
+		answers[key] = value
+	}
+	return answers, nil
+}
+
+// GetValuesFromEnv returns the answers given as environment variables whose name starts with the prefix,
+// keyed by the rest of the name. Nothing is returned when the prefix is empty
+func GetValuesFromEnv(prefix string) map[string]string {
+	answers := make(map[string]string)
+	if prefix == "" {
+		return answers
+	}
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], prefix) && len(parts[0]) > len(prefix) {
+			answers[strings.TrimPrefix(parts[0], prefix)] = parts[1]
+		}
+	}
+	return answers
+}
+
+func splitSetFlag(flagValue string) (string, string, error) {
+	parts := strings.SplitN(flagValue, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", fmt.Errorf("invalid answer [%s], expected format is key=value", flagValue)
+	}
+	return strings.TrimSpace(parts[0]), parts[1], nil
+}
+
+// SaveAnswersFile writes the answers collected while preparing the template data to a file that can be read back
+// with GetValuesFromAnswersFile, answers for secret fields are only written when includeSecrets is set
+func SaveAnswersFile(answersFilePath string, data *PreparedData, includeSecrets bool) error {
+	answers := make(map[string]interface{})
+	util.CopyIntoStringInterfaceMap(data.Answers, answers)
+	if includeSecrets {
+		util.CopyIntoStringInterfaceMap(data.SecretAnswers, answers)
+	}
+	content, err := yaml.Marshal(answers)
+	if err != nil {
+		return err
+	}
+	util.Verbose("[dataPrep] Saving %d answers to file [%s]\n", len(answers), answersFilePath)
+	return ioutil.WriteFile(answersFilePath, content, 0640)
+}
+
+// parseAnswers parses the contents of an answers file
+func parseAnswers(content []byte) (map[string]string, error) {
+	answers := make(map[string]string)
+	err := yaml.Unmarshal(content, answers)
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
 }
 
 // utility functions
@@ -696,17 +809,18 @@ func getFileContents(filepath string) (string, error) {
 	return string(data), nil
 }
 
-func validateVariables(variables *[]Variable) error {
+func validateVariables(variables *[]Variable) []error {
+	var errs []error
 	var variableNames []string
 	for _, userVar := range *variables {
 		// validate select case
 		if userVar.Type.Value == TypeSelect && len(userVar.Options) == 0 {
-			return fmt.Errorf("at least one option field is need to be set for parameter [%s]", userVar.Name.Value)
+			errs = append(errs, fmt.Errorf("at least one option field is need to be set for parameter [%s]", userVar.Name.Value))
 		}
 
 		// validate file case
 		if userVar.Type.Value == TypeFile && !util.IsStringEmpty(userVar.Value.Value) {
-			return fmt.Errorf("'value' field is not allowed for file input type")
+			errs = append(errs, fmt.Errorf("'value' field is not allowed for file input type"))
 		}
 
 		variableNames = append(variableNames, userVar.Name.Value)
@@ -714,25 +828,25 @@ func validateVariables(variables *[]Variable) error {
 
 	// Check if there are duplicate variable names
 	if len(funk.UniqString(variableNames)) != len(*variables) {
-		return fmt.Errorf("variable names must be unique within blueprint 'parameters' definition")
+		errs = append(errs, fmt.Errorf("variable names must be unique within blueprint 'parameters' definition"))
 	}
-	return nil
+	return errs
 }
 
-func validateFiles(configs *[]TemplateConfig) error {
+func validateFiles(configs *[]TemplateConfig) []error {
+	var errs []error
 	for _, file := range *configs {
 		// validate non-empty
 		if util.IsStringEmpty(file.Path) {
-			return fmt.Errorf("path is missing for file specification in files")
-		}
-		if filepath.IsAbs(file.Path) || strings.HasPrefix(file.Path, "..") || strings.HasPrefix(file.Path, "."+string(os.PathSeparator)) {
-			return fmt.Errorf("path for file specification cannot start with /, .. or ./")
+			errs = append(errs, fmt.Errorf("path is missing for file specification in files"))
+		} else if filepath.IsAbs(file.Path) || strings.HasPrefix(file.Path, "..") || strings.HasPrefix(file.Path, "."+string(os.PathSeparator)) {
+			errs = append(errs, fmt.Errorf("path for file specification cannot start with /, .. or ./"))
 		}
 	}
-	return nil
+	return errs
 }
 
-func validatePrompt(varName string, validateExpr string, allowEmpty bool, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) func(val interface{}) error {
+func validatePrompt(logger Logger, varName string, validateExpr string, allowEmpty bool, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) func(val interface{}) error {
 	return func(val interface{}) error {
 		var value interface{}
 		switch valType := val.(type) {
@@ -761,7 +875,7 @@ func validatePrompt(varName string, validateExpr string, allowEmpty bool, parame
 			if varName != "" {
 				parameters[varName] = value
 			}
-			isSuccess, err := ProcessCustomExpression(validateExpr, parameters, overrideFns)
+			isSuccess, err := processCustomExpression(logger, validateExpr, parameters, overrideFns)
 			if err != nil {
 				return err
 			}
@@ -775,13 +889,13 @@ func validatePrompt(varName string, validateExpr string, allowEmpty bool, parame
 	}
 }
 
-func validateFilePath(varName string, validateExpr string, allowEmpty bool, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) func(val interface{}) error {
+func validateFilePath(logger Logger, varName string, validateExpr string, allowEmpty bool, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) func(val interface{}) error {
 	return func(val interface{}) error {
 		err := survey.Required(val)
 		if err != nil {
 			return err
 		}
-		validationErr := validatePrompt(varName, validateExpr, allowEmpty, parameters, overrideFns)(val)
+		validationErr := validatePrompt(logger, varName, validateExpr, allowEmpty, parameters, overrideFns)(val)
 
 		if validationErr != nil {
 			return fmt.Errorf("validation error for answer value [%v] for variable [%s]: %s", val, varName, validationErr.Error())
@@ -792,7 +906,7 @@ func validateFilePath(varName string, validateExpr string, allowEmpty bool, para
 		if filePath != "" {
 			info, err := os.Stat(filePath)
 			if err != nil {
-				util.Verbose("[input] error in file stat: %s\n", err.Error())
+				logger.Verbose("[input] error in file stat: %s\n", err.Error())
 				return fmt.Errorf("file not found on path %s", filePath)
 			}
 			if info.IsDir() {
@@ -803,15 +917,15 @@ func validateFilePath(varName string, validateExpr string, allowEmpty bool, para
 	}
 }
 
-func skipQuestionOnCondition(currentVar *Variable, dependsOnVal string, dependsOn bool, dataMap *PreparedData, defaultVal interface{}, condition bool) bool {
+func skipQuestionOnCondition(logger Logger, currentVar *Variable, dependsOnVal string, dependsOn bool, dataMap *PreparedData, defaultVal interface{}, condition bool) bool {
 	if dependsOn == condition {
 		// return false if this is a skipped confirm question
 		if defaultVal == "" && currentVar.Type.Value == TypeConfirm {
 			defaultVal = false
 		}
 		currentVar.Meta.PromptSkipped = true
-		saveItemToTemplateDataMap(currentVar, dataMap, defaultVal)
-		util.Verbose("[dataPrep] Skipping question for parameter [%s] because PromptIf [%s] value is %t\n", currentVar.Name.Value, dependsOnVal, condition)
+		saveItemToTemplateDataMap(logger, currentVar, dataMap, defaultVal)
+		logger.Verbose("[dataPrep] Skipping question for parameter [%s] because PromptIf [%s] value is %t\n", currentVar.Name.Value, dependsOnVal, condition)
 		return true
 	}
 	return false
@@ -824,7 +938,16 @@ func prepareQuestionText(desc string, fallbackQuestion string) string {
 	return fallbackQuestion
 }
 
-func saveItemToTemplateDataMap(variable *Variable, preparedData *PreparedData, data interface{}) {
+// saveAnswerToPreparedData keeps the answer given for the variable, so that it can be written back to an answers file
+func saveAnswerToPreparedData(variable *Variable, preparedData *PreparedData, answer interface{}) {
+	if IsSecretType(variable.Type.Value) {
+		preparedData.SecretAnswers[variable.Name.Value] = answer
+	} else {
+		preparedData.Answers[variable.Name.Value] = answer
+	}
+}
+
+func saveItemToTemplateDataMap(logger Logger, variable *Variable, preparedData *PreparedData, data interface{}) {
 	skipParam := variable.IgnoreIfSkipped.Bool && (variable.Meta.PromptSkipped || data == nil || data == "")
 
 	switch variable.Type.Value {
@@ -842,7 +965,7 @@ func saveItemToTemplateDataMap(variable *Variable, preparedData *PreparedData, d
 
 	if IsSecretType(variable.Type.Value) {
 		if !skipParam {
-			util.Verbose("[dataPrep] Skipping secret parameter [%s] from summary-table/value-files because IgnoreIfSkipped is true and PromptIf is false\n", variable.Name.Value)
+			logger.Verbose("[dataPrep] Skipping secret parameter [%s] from summary-table/value-files because IgnoreIfSkipped is true and PromptIf is false\n", variable.Name.Value)
 
 			if variable.RevealOnSummary.Bool {
 				preparedData.SummaryData[variable.Label.Value] = data
@@ -860,7 +983,7 @@ func saveItemToTemplateDataMap(variable *Variable, preparedData *PreparedData, d
 		}
 	} else {
 		if !skipParam {
-			util.Verbose("[dataPrep] Skipping parameter [%s] from summary-table/value-files because IgnoreIfSkipped is true and PromptIf is false\n", variable.Name.Value)
+			logger.Verbose("[dataPrep] Skipping parameter [%s] from summary-table/value-files because IgnoreIfSkipped is true and PromptIf is false\n", variable.Name.Value)
 
 			preparedData.SummaryData[variable.Label.Value] = data
 
@@ -875,8 +998,12 @@ func saveItemToTemplateDataMap(variable *Variable, preparedData *PreparedData, d
 }
 
 func ProcessCustomFunction(fnStr string) ([]string, error) {
+	return processCustomFunction(cliLogger{}, fnStr)
+}
+
+func processCustomFunction(logger Logger, fnStr string) ([]string, error) {
 	// validate function call string (DOMAIN.MODULE(PARAMS...).ATTR|[INDEX])
-	util.Verbose("[fn] Calling fn [%s] for getting template variable value\n", fnStr)
+	logger.Verbose("[fn] Calling fn [%s] for getting template variable value\n", fnStr)
 	if regExFn.MatchString(fnStr) {
 		groups := regExFn.FindStringSubmatch(fnStr)
 		if len(groups) != 6 {
@@ -319,7 +319,7 @@ func TestSkipQuestionOnCondition(t *testing.T) {
 			Type:      VarField{Value: TypeInput},
 			DependsOn: VarField{Value: "confirm", InvertBool: true},
 		}
-		assert.True(t, skipQuestionOnCondition(&variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
+		assert.True(t, skipQuestionOnCondition(cliLogger{}, &variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
 	})
 	t.Run("should skip question (promptIf)", func(t *testing.T) {
 		variables := make([]Variable, 2)
@@ -333,7 +333,7 @@ func TestSkipQuestionOnCondition(t *testing.T) {
 			Type:      VarField{Value: TypeInput},
 			DependsOn: VarField{Value: "confirm"},
 		}
-		assert.True(t, skipQuestionOnCondition(&variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
+		assert.True(t, skipQuestionOnCondition(cliLogger{}, &variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
 	})
 	t.Run("should skip question and default value should be false (promptIf)", func(t *testing.T) {
 		data := NewPreparedData()
@@ -348,7 +348,7 @@ func TestSkipQuestionOnCondition(t *testing.T) {
 			Type:      VarField{Value: TypeConfirm},
 			DependsOn: VarField{Value: "confirm"},
 		}
-		assert.True(t, skipQuestionOnCondition(&variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, data, "", variables[1].DependsOn.InvertBool))
+		assert.True(t, skipQuestionOnCondition(cliLogger{}, &variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, data, "", variables[1].DependsOn.InvertBool))
 		assert.False(t, data.TemplateData[variables[1].Name.Value].(bool))
 	})
 
@@ -364,7 +364,7 @@ func TestSkipQuestionOnCondition(t *testing.T) {
 			Type:      VarField{Value: TypeInput},
 			DependsOn: VarField{Value: "confirm", InvertBool: true},
 		}
-		assert.False(t, skipQuestionOnCondition(&variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
+		assert.False(t, skipQuestionOnCondition(cliLogger{}, &variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
 	})
 	t.Run("should not skip question (promptIf)", func(t *testing.T) {
 		variables := make([]Variable, 2)
@@ -378,7 +378,7 @@ func TestSkipQuestionOnCondition(t *testing.T) {
 			Type:      VarField{Value: TypeInput},
 			DependsOn: VarField{Value: "confirm"},
 		}
-		assert.False(t, skipQuestionOnCondition(&variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
+		assert.False(t, skipQuestionOnCondition(cliLogger{}, &variables[1], variables[1].DependsOn.Value, variables[0].Value.Bool, NewPreparedData(), "", variables[1].DependsOn.InvertBool))
 	})
 }
 
@@ -612,7 +612,7 @@ func TestValidatePrompt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := validatePrompt(tt.args.varName, tt.args.validateExpr, tt.args.emtpyAllowed, tt.args.params, nil)(tt.args.value)
+			got := validatePrompt(cliLogger{}, tt.args.varName, tt.args.validateExpr, tt.args.emtpyAllowed, tt.args.params, nil)(tt.args.value)
 			if tt.want == nil || got == nil {
 				assert.Equal(t, tt.want, got)
 			} else {
@@ -672,7 +672,7 @@ func TestValidateFilePath(t *testing.T) {
 				ioutil.WriteFile(tt.args.value, contents, os.ModePerm)
 			}
 
-			got := validateFilePath(tt.args.varName, tt.args.validateExpr, tt.args.emtpyAllowed, tt.args.params, nil)(tt.args.value)
+			got := validateFilePath(cliLogger{}, tt.args.varName, tt.args.validateExpr, tt.args.emtpyAllowed, tt.args.params, nil)(tt.args.value)
 			if tt.want == nil || got == nil {
 				assert.Equal(t, tt.want, got)
 			} else {
@@ -746,6 +746,117 @@ confirm=true
 	}
 }
 
+func TestGetValuesFromSetFlags(t *testing.T) {
+	// Create needed temporary directory for tests
+	os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+	certFilePath := filepath.Join("test", "cert.pem")
+	ioutil.WriteFile(certFilePath, []byte("line1\nline2\n"), os.ModePerm)
+
+	tests := []struct {
+		name      string
+		setValues []string
+		setFiles  []string
+		wantOut   map[string]string
+		errOut    bool
+	}{
+		{
+			"set flags: parse key=value pairs",
+			[]string{"test=testing", "url=http://localhost:5516?a=b", "empty="},
+			nil,
+			map[string]string{"test": "testing", "url": "http://localhost:5516?a=b", "empty": ""},
+			false,
+		},
+		{
+			"set flags: last key=value pair wins",
+			[]string{"test=testing", "test=overridden"},
+			nil,
+			map[string]string{"test": "overridden"},
+			false,
+		},
+		{
+			"set flags: read value from file",
+			nil,
+			[]string{"cert=" + certFilePath},
+			map[string]string{"cert": "line1\nline2\n"},
+			false,
+		},
+		{
+			"set flags: key=value pairs take precedence over key=path pairs",
+			[]string{"cert=inline"},
+			[]string{"cert=" + certFilePath},
+			map[string]string{"cert": "inline"},
+			false,
+		},
+		{
+			"set flags: error when value is not in key=value format",
+			[]string{"testing"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"set flags: error when key is empty",
+			[]string{"=testing"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"set flags: error when file not found",
+			nil,
+			[]string{"cert=error.pem"},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetValuesFromSetFlags(tt.setValues, tt.setFiles)
+			if tt.errOut {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+			assert.Equal(t, tt.wantOut, got)
+		})
+	}
+}
+
+func TestGetValuesFromEnv(t *testing.T) {
+	t.Setenv("XL_BP_TEST_ANSWER_AppName", "my-app")
+	t.Setenv("XL_BP_TEST_ANSWER_Cert", "line1=a\nline2")
+	t.Setenv("XL_BP_TEST_ANSWER_", "no name")
+
+	assert.Equal(t, map[string]string{"AppName": "my-app", "Cert": "line1=a\nline2"}, GetValuesFromEnv("XL_BP_TEST_ANSWER_"))
+	assert.Empty(t, GetValuesFromEnv(""))
+}
+
+func TestSaveAnswersFile(t *testing.T) {
+	// Create needed temporary directory for tests
+	os.MkdirAll("test", os.ModePerm)
+	defer os.RemoveAll("test")
+	answersFilePath := filepath.Join("test", "saved-answers.yaml")
+
+	data := NewPreparedData()
+	data.Answers = map[string]interface{}{"AppName": "my-app", "UseProxy": true, "Cert": "line1\nline2"}
+	data.SecretAnswers = map[string]interface{}{"Password": "secret"}
+
+	t.Run("should save answers without secrets", func(t *testing.T) {
+		require.Nil(t, SaveAnswersFile(answersFilePath, data, false))
+		got, err := GetValuesFromAnswersFile(answersFilePath)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"AppName": "my-app", "UseProxy": "true", "Cert": "line1\nline2"}, got)
+	})
+
+	t.Run("should save answers with secrets", func(t *testing.T) {
+		require.Nil(t, SaveAnswersFile(answersFilePath, data, true))
+		got, err := GetValuesFromAnswersFile(answersFilePath)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"AppName": "my-app", "UseProxy": "true", "Cert": "line1\nline2", "Password": "secret"}, got)
+	})
+}
+
 func TestVerifyVariableValue(t *testing.T) {
 	// Create needed temporary directory for tests
 	os.MkdirAll("test", os.ModePerm)
@@ -894,10 +1005,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 			},
 			args{"", false, true, false, nil},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "default1", "input2": "!value input2", "input3": "!value input3"},
-				SummaryData:  map[string]interface{}{"input1": "default1", "input 2": "*****", "input 3": "default3"},
-				Secrets:      map[string]interface{}{"input2": "default2", "input3": "default3"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "default1", "input2": "!value input2", "input3": "!value input3"},
+				SummaryData:   map[string]interface{}{"input1": "default1", "input 2": "*****", "input 3": "default3"},
+				Secrets:       map[string]interface{}{"input2": "default2", "input3": "default3"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input1": "default1"},
+				SecretAnswers: map[string]interface{}{"input2": "default2", "input3": "default3"},
 			},
 			false,
 		},
@@ -976,10 +1089,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 			},
 			args{GetTestTemplateDir("answer-input-2.yaml"), true, false, false, nil},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input4": "ans4", "input5": ""},
-				SummaryData:  map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 4": "ans4"},
-				Secrets:      map[string]interface{}{"input2": "ans2"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input4": "ans4", "input5": ""},
+				SummaryData:   map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 4": "ans4"},
+				Secrets:       map[string]interface{}{"input2": "ans2"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input3": "ans3", "input4": "ans4"},
+				SecretAnswers: map[string]interface{}{"input2": "ans2"},
 			},
 			false,
 		},
@@ -1009,10 +1124,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 			},
 			args{GetTestTemplateDir("answer-input-2.yaml"), true, false, false, nil},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3"},
-				SummaryData:  map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3"},
-				Secrets:      map[string]interface{}{"input2": "ans2"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3"},
+				SummaryData:   map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3"},
+				Secrets:       map[string]interface{}{"input2": "ans2"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input3": "ans3"},
+				SecretAnswers: map[string]interface{}{"input2": "ans2"},
 			},
 			false,
 		},
@@ -1048,10 +1165,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 			},
 			args{GetTestTemplateDir("answer-input-2.yaml"), true, true, false, nil},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input5": "default5"},
-				SummaryData:  map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 5": "default5"},
-				Secrets:      map[string]interface{}{"input2": "ans2"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input5": "default5"},
+				SummaryData:   map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 5": "default5"},
+				Secrets:       map[string]interface{}{"input2": "ans2"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input3": "ans3", "input5": "default5"},
+				SecretAnswers: map[string]interface{}{"input2": "ans2"},
 			},
 			false,
 		},
@@ -1103,10 +1222,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 			},
 			args{GetTestTemplateDir("answer-input-2.yaml"), true, true, false, nil},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "true", "input2": "100", "input3": "true", "input4": "50.885", "input5": "!value input5", "input6": "false"},
-				SummaryData:  map[string]interface{}{"input1": "true", "input 2": "100", "input 3": "true", "input4": "50.885", "input 5": "*****", "input6": "false"},
-				Secrets:      map[string]interface{}{"input5": "50.58"},
-				Values:       map[string]interface{}{"input4": "50.885", "input6": "false"},
+				TemplateData:  map[string]interface{}{"input1": "true", "input2": "100", "input3": "true", "input4": "50.885", "input5": "!value input5", "input6": "false"},
+				SummaryData:   map[string]interface{}{"input1": "true", "input 2": "100", "input 3": "true", "input4": "50.885", "input 5": "*****", "input6": "false"},
+				Secrets:       map[string]interface{}{"input5": "50.58"},
+				Values:        map[string]interface{}{"input4": "50.885", "input6": "false"},
+				Answers:       map[string]interface{}{"input6": "false"},
+				SecretAnswers: map[string]interface{}{},
 			},
 			false,
 		},
@@ -1157,10 +1278,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 				"input4": "overdefault4",
 			}},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input4": "ans4", "input5": "default5"},
-				SummaryData:  map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 4": "ans4", "input 5": "default5"},
-				Secrets:      map[string]interface{}{"input2": "ans2"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "ans3", "input4": "ans4", "input5": "default5"},
+				SummaryData:   map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "ans3", "input 4": "ans4", "input 5": "default5"},
+				Secrets:       map[string]interface{}{"input2": "ans2"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input3": "ans3", "input4": "ans4", "input5": "default5"},
+				SecretAnswers: map[string]interface{}{"input2": "ans2"},
 			},
 			false,
 		},
@@ -1217,10 +1340,12 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 				"input4": "overdefault4",
 			}},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "overdefault3", "input4": "ans4", "input5": "default5", "input6": "default6"},
-				SummaryData:  map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "overdefault3", "input 4": "ans4", "input 5": "default5", "input 6": "default6"},
-				Secrets:      map[string]interface{}{"input2": "overdefault2"},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"input1": "val1", "input2": "!value input2", "input3": "overdefault3", "input4": "ans4", "input5": "default5", "input6": "default6"},
+				SummaryData:   map[string]interface{}{"input1": "val1", "input 2": "*****", "input 3": "overdefault3", "input 4": "ans4", "input 5": "default5", "input 6": "default6"},
+				Secrets:       map[string]interface{}{"input2": "overdefault2"},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{"input3": "overdefault3", "input4": "ans4", "input5": "default5", "input6": "default6"},
+				SecretAnswers: map[string]interface{}{"input2": "overdefault2"},
 			},
 			false,
 		},
@@ -1235,6 +1360,7 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 				Variables:       tt.fields.Variables,
 			}
 			got, err := blueprintDoc.prepareTemplateData(
+				newCLISession(),
 				BlueprintParams{
 					AnswersFile:        tt.args.answersFilePath,
 					StrictAnswers:      tt.args.strictAnswers,
@@ -1254,6 +1380,93 @@ func TestBlueprintYaml_prepareTemplateData(t *testing.T) {
 	}
 }
 
+func TestBlueprintYaml_prepareTemplateData_AnswersMap(t *testing.T) {
+	variables := []Variable{
+		{
+			Name:  VarField{Value: "input1"},
+			Label: VarField{Value: "input1"},
+			Type:  VarField{Value: "Input"},
+		},
+		{
+			Name:  VarField{Value: "input3"},
+			Label: VarField{Value: "input 3"},
+			Type:  VarField{Value: "Input"},
+		},
+		{
+			Name:    VarField{Value: "select"},
+			Label:   VarField{Value: "select"},
+			Type:    VarField{Value: "Select"},
+			Options: []VarField{{Value: "a"}, {Value: "b"}},
+		},
+	}
+
+	t.Run("should merge answers map over answers file", func(t *testing.T) {
+		blueprintDoc := &BlueprintConfig{Variables: variables}
+		got, err := blueprintDoc.prepareTemplateData(
+			newCLISession(),
+			BlueprintParams{
+				AnswersFile:   GetTestTemplateDir("answer-input-2.yaml"),
+				AnswersMap:    map[string]string{"input3": "set3", "select": "b"},
+				StrictAnswers: true,
+			},
+			NewPreparedData(),
+			nil,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"input1": "ans1", "input3": "set3", "select": "b"}, got.TemplateData)
+	})
+
+	t.Run("should merge environment variables between answers file and answers map", func(t *testing.T) {
+		t.Setenv("XL_BP_TEST_ANSWER_input1", "env1")
+		t.Setenv("XL_BP_TEST_ANSWER_input3", "env3")
+		blueprintDoc := &BlueprintConfig{Variables: variables}
+		got, err := blueprintDoc.prepareTemplateData(
+			newCLISession(),
+			BlueprintParams{
+				AnswersFile:      GetTestTemplateDir("answer-input-2.yaml"),
+				AnswersMap:       map[string]string{"input3": "set3", "select": "b"},
+				AnswersEnvPrefix: "XL_BP_TEST_ANSWER_",
+				StrictAnswers:    true,
+			},
+			NewPreparedData(),
+			nil,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"input1": "env1", "input3": "set3", "select": "b"}, got.TemplateData)
+	})
+
+	t.Run("should use environment variables without answers file", func(t *testing.T) {
+		t.Setenv("XL_BP_TEST_ANSWER_input1", "env1")
+		t.Setenv("XL_BP_TEST_ANSWER_input3", "env3")
+		t.Setenv("XL_BP_TEST_ANSWER_select", "a")
+		blueprintDoc := &BlueprintConfig{Variables: variables}
+		got, err := blueprintDoc.prepareTemplateData(
+			newCLISession(),
+			BlueprintParams{AnswersEnvPrefix: "XL_BP_TEST_ANSWER_", StrictAnswers: true},
+			NewPreparedData(),
+			nil,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"input1": "env1", "input3": "env3", "select": "a"}, got.TemplateData)
+	})
+
+	t.Run("should verify answers map values", func(t *testing.T) {
+		blueprintDoc := &BlueprintConfig{Variables: variables}
+		_, err := blueprintDoc.prepareTemplateData(
+			newCLISession(),
+			BlueprintParams{
+				AnswersFile:   GetTestTemplateDir("answer-input-2.yaml"),
+				AnswersMap:    map[string]string{"select": "c"},
+				StrictAnswers: true,
+			},
+			NewPreparedData(),
+			nil,
+		)
+		require.NotNil(t, err)
+		assert.Equal(t, "answer [c] is not one of the available options [a b] for variable [select]", err.Error())
+	})
+}
+
 func Test_findLabelValueFromOptions(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1820,7 +2033,7 @@ func Test_saveItemToTemplateDataMap(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			saveItemToTemplateDataMap(tt.args.variable, tt.args.preparedData, tt.args.data)
+			saveItemToTemplateDataMap(cliLogger{}, tt.args.variable, tt.args.preparedData, tt.args.data)
 			assert.Equal(t, tt.exprected, *tt.args.preparedData)
 		})
 	}
@@ -1873,7 +2086,7 @@ func Test_shouldAskForInput(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := shouldAskForInput(tt.variable); got != tt.want {
+			if got := shouldAskForInput(tt.variable, SkipUserInput); got != tt.want {
 				t.Errorf("shouldAskForInput() = %v, want %v", got, tt.want)
 			}
 			SkipUserInput = false // reset the field
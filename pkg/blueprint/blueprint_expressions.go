@@ -40,7 +40,7 @@ func regexMatch(pattern, value string) (bool, error) {
 	return true, nil
 }
 
-func getExpressionFunctions(params map[string]interface{}, overrideFnMethods map[string]govaluate.ExpressionFunction) map[string]govaluate.ExpressionFunction {
+func getExpressionFunctions(logger Logger, params map[string]interface{}, overrideFnMethods map[string]govaluate.ExpressionFunction) map[string]govaluate.ExpressionFunction {
 	baseFnMap := map[string]govaluate.ExpressionFunction{
 		"strlen": func(args ...interface{}) (interface{}, error) {
 			length := len(args[0].(string))
@@ -76,7 +76,7 @@ func getExpressionFunctions(params map[string]interface{}, overrideFnMethods map
 		"length": func(args ...interface{}) (interface{}, error) {
 			arrayList := args[0].([]string)
 			arrayListLen := len(arrayList)
-			util.Verbose("Calculate length of %s: %d\n", strings.Join(arrayList, ","), arrayListLen)
+			logger.Verbose("Calculate length of %s: %d\n", strings.Join(arrayList, ","), arrayListLen)
 			return strconv.Itoa(arrayListLen), nil
 		},
 		"regex": func(args ...interface{}) (interface{}, error) {
@@ -303,7 +303,11 @@ func getExpressionFunctions(params map[string]interface{}, overrideFnMethods map
 // ProcessCustomExpression evaluates the expressions passed in the blueprint.yaml file using https://github.com/Knetic/govaluate
 // {parameters} are the result of the spec -> parameters defined in the blueprint yaml. Parameters needs to be defined before use.
 func ProcessCustomExpression(exStr string, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (interface{}, error) {
-	util.Verbose("[expression] Evaluating expression [%s]\n", exStr)
+	return processCustomExpression(cliLogger{}, exStr, parameters, overrideFns)
+}
+
+func processCustomExpression(logger Logger, exStr string, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) (interface{}, error) {
+	logger.Verbose("[expression] Evaluating expression [%s]\n", exStr)
 
 	expressionParams := FixValueTypes(parameters)
 	var overrideFnMethods map[string]govaluate.ExpressionFunction
@@ -312,7 +316,7 @@ func ProcessCustomExpression(exStr string, parameters map[string]interface{}, ov
 		overrideFnMethods = overrideFns(expressionParams)
 	}
 
-	expression, err := govaluate.NewEvaluableExpressionWithFunctions(exStr, getExpressionFunctions(expressionParams, overrideFnMethods))
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(exStr, getExpressionFunctions(logger, expressionParams, overrideFnMethods))
 	if err != nil {
 		return nil, err
 	}
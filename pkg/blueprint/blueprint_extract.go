@@ -0,0 +1,327 @@
+package blueprint
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/yaml"
+)
+
+// Kinds of files extracted into a blueprint
+const (
+	ExtractedTemplate = "template"
+	ExtractedCopy     = "copy"
+	ExtractedBinary   = "binary"
+)
+
+// number of leading bytes checked for NUL bytes to detect binary files
+const binarySniffLength = 8000
+
+// ExtractLiteral is a literal value in the files of a project, replaced by the placeholder of the parameter Name
+type ExtractLiteral struct {
+	Name  string
+	Value string
+}
+
+// ExtractOptions configures ExtractBlueprint. The files of SourceDir are written to OutputDir as a blueprint named Name,
+// directories named in IgnoredDirs are skipped
+type ExtractOptions struct {
+	SourceDir   string
+	OutputDir   string
+	Name        string
+	Literals    []ExtractLiteral
+	IgnoredDirs []string
+}
+
+// ExtractedFile is a file of the project written to the blueprint, Path is the path in the blueprint
+type ExtractedFile struct {
+	Path         string `json:"path" yaml:"path"`
+	Kind         string `json:"kind" yaml:"kind"`
+	Replacements int    `json:"replacements" yaml:"replacements"`
+}
+
+// ExtractResult lists the files written to the blueprint and the literals which were not found in any file
+type ExtractResult struct {
+	Files          []ExtractedFile `json:"files" yaml:"files"`
+	UnusedLiterals []string        `json:"unusedLiterals,omitempty" yaml:"unusedLiterals,omitempty"`
+}
+
+// ParseExtractLiterals parses literals given as NAME=VALUE pairs. The same name can be given more than once,
+// such a parameter becomes a Select parameter with the values as options
+func ParseExtractLiterals(values []string) ([]ExtractLiteral, error) {
+	var literals []ExtractLiteral
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid literal [%s], expected format is name=value", value)
+		}
+		literals = append(literals, ExtractLiteral{Name: strings.TrimSpace(parts[0]), Value: parts[1]})
+	}
+	return literals, nil
+}
+
+// ExtractBlueprint creates a blueprint from an existing project. Every literal found in a text file is replaced by the
+// placeholder of its parameter in a .tmpl copy of the file, other files, binary files included, are copied untouched.
+// The parameters are inferred from the literal values and the blueprint definition is validated before any file is written
+func ExtractBlueprint(options ExtractOptions) (*ExtractResult, error) {
+	if entries, err := ioutil.ReadDir(options.OutputDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", options.OutputDir)
+	}
+	if strings.TrimSpace(options.Name) == "" {
+		return nil, fmt.Errorf("the name of the blueprint is needed")
+	}
+	parameters, literalPattern, err := inferExtractParameters(options.Literals)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	for _, literal := range options.Literals {
+		names[literal.Value] = literal.Name
+	}
+
+	sourceDir, err := filepath.Abs(options.SourceDir)
+	if err != nil {
+		return nil, err
+	}
+	outputDir, err := filepath.Abs(options.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExtractResult{}
+	contents := make(map[string][]byte)
+	used := make(map[string]bool)
+	err = filepath.Walk(sourceDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filePath == outputDir || (filePath != sourceDir && util.IsStringInSlice(info.Name(), options.IgnoredDirs)) {
+				util.Verbose("[extract] Skipping directory %s\n", filePath)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(sourceDir, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if repository.CheckIfBlueprintDefinitionFile(relPath) {
+			return fmt.Errorf("project directory %s already contains a blueprint definition", options.SourceDir)
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		file := ExtractedFile{Path: relPath, Kind: ExtractedCopy}
+		if isBinaryContent(content) {
+			file.Kind = ExtractedBinary
+		} else {
+			var rendered []byte
+			rendered, file.Replacements = replaceLiterals(content, literalPattern, names, used)
+			if file.Replacements > 0 || strings.HasSuffix(relPath, templateExtension) {
+				// files ending in .tmpl are rendered, so their content has to be escaped as well
+				file.Path, file.Kind, content = relPath+templateExtension, ExtractedTemplate, rendered
+			}
+		}
+		util.Verbose("[extract] File %s is extracted as %s with %d replacements\n", relPath, file.Kind, file.Replacements)
+		result.Files = append(result.Files, file)
+		contents[file.Path] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, literal := range options.Literals {
+		if !used[literal.Value] {
+			result.UnusedLiterals = append(result.UnusedLiterals, literal.Name+"="+literal.Value)
+		}
+	}
+
+	yamlDoc := BlueprintYamlV2{
+		ApiVersion: models.BlueprintYamlFormatV2,
+		Kind:       models.BlueprintSpecKind,
+		Metadata:   MetadataV2{Name: options.Name, Version: "1.0.0"},
+		Spec:       SpecV2{Parameters: parameters},
+	}
+	for _, file := range result.Files {
+		yamlDoc.Spec.Files = append(yamlDoc.Spec.Files, FileV2{Path: file.Path})
+	}
+	definition, err := yaml.Marshal(yamlDoc)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseTemplateMetadataV2(&definition, options.OutputDir, nil); err != nil {
+		return nil, fmt.Errorf("extracted blueprint definition is not valid: %s", err.Error())
+	}
+	contents[repository.BlueprintMetadataFileName+".yaml"] = definition
+
+	paths := make([]string, 0, len(contents))
+	for filePath := range contents {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	for _, filePath := range paths {
+		target := filepath.Join(outputDir, filepath.FromSlash(filePath))
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(target, contents[filePath], 0640); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// inferExtractParameters returns the parameters of the literals in the order they are first given, and a pattern
+// matching any of the literal values, longest first so that a value containing another one is replaced as a whole.
+// A parameter with more than one value is a Select, a parameter with a boolean value a Confirm, any other an Input.
+// The value matched by the pattern is its submatch named value
+func inferExtractParameters(literals []ExtractLiteral) ([]ParameterV2, *regexp.Regexp, error) {
+	var order []string
+	values := make(map[string][]string)
+	owners := make(map[string]string)
+	for _, literal := range literals {
+		if !regExScaffoldParameterName.MatchString(literal.Name) {
+			return nil, nil, fmt.Errorf("parameter name [%s] must start with a letter and contain only letters, digits and underscores", literal.Name)
+		}
+		if literal.Value == "" {
+			return nil, nil, fmt.Errorf("the value of parameter [%s] is empty", literal.Name)
+		}
+		if owner, ok := owners[literal.Value]; ok {
+			if owner != literal.Name {
+				return nil, nil, fmt.Errorf("value [%s] is given for both parameters [%s] and [%s]", literal.Value, owner, literal.Name)
+			}
+			continue
+		}
+		owners[literal.Value] = literal.Name
+		if _, ok := values[literal.Name]; !ok {
+			order = append(order, literal.Name)
+		}
+		values[literal.Name] = append(values[literal.Name], literal.Value)
+	}
+	if len(order) == 0 {
+		return nil, nil, fmt.Errorf("at least one literal is needed to extract a blueprint")
+	}
+
+	var parameters []ParameterV2
+	var patternValues, booleanValues []string
+	for _, name := range order {
+		parameter := ScaffoldParameter{Name: name, Type: TypeInput}
+		var defaultVal interface{} = values[name][0]
+		var options []interface{}
+		if len(values[name]) > 1 {
+			parameter.Type = TypeSelect
+			for _, value := range values[name] {
+				options = append(options, value)
+			}
+		} else if isBooleanLiteral(values[name][0]) {
+			parameter.Type = TypeConfirm
+			defaultVal = strings.ToLower(values[name][0]) == "true"
+		}
+		parameters = append(parameters, ParameterV2{
+			Name:    name,
+			Type:    parameter.Type,
+			Prompt:  parameter.prompt(),
+			Default: defaultVal,
+			Options: options,
+		})
+		for _, value := range values[name] {
+			if isBooleanLiteral(value) {
+				booleanValues = append(booleanValues, regexp.QuoteMeta(value))
+			} else {
+				patternValues = append(patternValues, value)
+			}
+		}
+	}
+	sort.SliceStable(patternValues, func(i, j int) bool {
+		return len(patternValues[i]) > len(patternValues[j])
+	})
+	var patterns []string
+	for _, value := range patternValues {
+		patterns = append(patterns, literalValuePattern(value))
+	}
+	// a boolean value is only replaced where it is the whole value of a YAML key, replacing every true or false
+	// of a project would change unrelated settings
+	var alternatives []string
+	if len(patterns) > 0 {
+		alternatives = append(alternatives, "(?P<value>"+strings.Join(patterns, "|")+")")
+	}
+	if len(booleanValues) > 0 {
+		alternatives = append(alternatives, `:[ \t]+(?P<value>`+strings.Join(booleanValues, "|")+`)[ \t]*(?:#|\r?$)`)
+	}
+	return parameters, regexp.MustCompile("(?m)" + strings.Join(alternatives, "|")), nil
+}
+
+func isBooleanLiteral(value string) bool {
+	value = strings.ToLower(value)
+	return value == "true" || value == "false"
+}
+
+// literalValuePattern returns the pattern of a literal value that only matches on token boundaries, so that the
+// value dev is not replaced inside device. A value starting or ending with a punctuation character, like /dev,
+// already delimits itself on that side
+func literalValuePattern(value string) string {
+	pattern := regexp.QuoteMeta(value)
+	if first, _ := utf8.DecodeRuneInString(value); isWordRune(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(value); isWordRune(last) {
+		pattern = pattern + `\b`
+	}
+	return pattern
+}
+
+// isWordRune returns true for the ASCII characters the \b assertion of the regexp package treats as word characters
+func isWordRune(r rune) bool {
+	return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// replaceLiterals replaces the literals in the content by the placeholders of their parameters and escapes
+// the template delimiters already in the content. The used literal values are marked in used
+func replaceLiterals(content []byte, literalPattern *regexp.Regexp, names map[string]string, used map[string]bool) ([]byte, int) {
+	var out bytes.Buffer
+	last := 0
+	matches := literalPattern.FindAllSubmatchIndex(content, -1)
+	for _, match := range matches {
+		var start, end int
+		for i, name := range literalPattern.SubexpNames() {
+			if name == "value" && match[2*i] >= 0 {
+				start, end = match[2*i], match[2*i+1]
+			}
+		}
+		out.Write(escapeTemplateDelimiters(content[last:start]))
+		value := string(content[start:end])
+		used[value] = true
+		fmt.Fprintf(&out, "{{.%s}}", names[value])
+		last = end
+	}
+	out.Write(escapeTemplateDelimiters(content[last:]))
+	return out.Bytes(), len(matches)
+}
+
+func escapeTemplateDelimiters(content []byte) []byte {
+	return bytes.ReplaceAll(content, []byte("{{"), []byte(`{{"{{"}}`))
+}
+
+// isBinaryContent returns true for content that is not UTF-8 text or has NUL bytes in its first bytes
+func isBinaryContent(content []byte) bool {
+	sniff := content
+	if len(sniff) > binarySniffLength {
+		sniff = sniff[:binarySniffLength]
+	}
+	return bytes.IndexByte(sniff, 0) >= 0 || !utf8.Valid(content)
+}
@@ -0,0 +1,121 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExtractLiterals(t *testing.T) {
+	literals, err := ParseExtractLiterals([]string{"AppName=my-app", "Env=dev", "Env=prod", "Url=http://host?a=b"})
+	require.Nil(t, err)
+	assert.Equal(t, []ExtractLiteral{
+		{Name: "AppName", Value: "my-app"},
+		{Name: "Env", Value: "dev"},
+		{Name: "Env", Value: "prod"},
+		{Name: "Url", Value: "http://host?a=b"},
+	}, literals)
+
+	_, err = ParseExtractLiterals([]string{"AppName"})
+	require.NotNil(t, err)
+	assert.Equal(t, "invalid literal [AppName], expected format is name=value", err.Error())
+}
+
+func TestExtractBlueprint(t *testing.T) {
+	newProject := func(t *testing.T, files map[string]string) (string, string) {
+		projectDir, err := ioutil.TempDir("", "extractproject")
+		require.Nil(t, err)
+		t.Cleanup(func() { os.RemoveAll(projectDir) })
+		writeUpgradeTestRepo(t, projectDir, files)
+		repoDir, err := ioutil.TempDir("", "extractrepo")
+		require.Nil(t, err)
+		t.Cleanup(func() { os.RemoveAll(repoDir) })
+		return projectDir, repoDir
+	}
+
+	t.Run("should replace literals in template copies and infer the parameters", func(t *testing.T) {
+		projectDir, repoDir := newProject(t, map[string]string{
+			"app.yaml":            "name: my-app\ndatabase: my-app-db\nenv: dev\ndevice: devices\npublic: true # exposed\nuntrue: trueish\nnote: it is true\nchart: {{ .Values.name }}\n",
+			"config/prod.yaml":    "env: prod\n",
+			"README.md":           "No literals here\n",
+			"images/logo.png":     "\x89PNG\x00my-app",
+			".git/config":         "my-app",
+			"config/blueprint.md": "my-app-db",
+		})
+		outputDir := filepath.Join(repoDir, "my-app")
+
+		result, err := ExtractBlueprint(ExtractOptions{
+			SourceDir: projectDir,
+			OutputDir: outputDir,
+			Name:      "My App",
+			Literals: []ExtractLiteral{
+				{Name: "AppName", Value: "my-app"},
+				{Name: "DbName", Value: "my-app-db"},
+				{Name: "Env", Value: "dev"},
+				{Name: "Env", Value: "prod"},
+				{Name: "Public", Value: "true"},
+				{Name: "Region", Value: "eu-west-1"},
+			},
+			IgnoredDirs: []string{".git"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, []ExtractedFile{
+			{Path: "README.md", Kind: ExtractedCopy},
+			{Path: "app.yaml.tmpl", Kind: ExtractedTemplate, Replacements: 4},
+			{Path: "config/blueprint.md.tmpl", Kind: ExtractedTemplate, Replacements: 1},
+			{Path: "config/prod.yaml.tmpl", Kind: ExtractedTemplate, Replacements: 1},
+			{Path: "images/logo.png", Kind: ExtractedBinary},
+		}, result.Files)
+		assert.Equal(t, []string{"Region=eu-west-1"}, result.UnusedLiterals)
+
+		assert.Equal(t, "name: {{.AppName}}\ndatabase: {{.DbName}}\nenv: {{.Env}}\ndevice: devices\npublic: {{.Public}} # exposed\nuntrue: trueish\nnote: it is true\nchart: {{\"{{\"}} .Values.name }}\n",
+			GetFileContent(filepath.Join(outputDir, "app.yaml.tmpl")))
+		assert.Equal(t, "\x89PNG\x00my-app", GetFileContent(filepath.Join(outputDir, "images", "logo.png")))
+		assert.False(t, fileExists(filepath.Join(outputDir, ".git", "config")))
+
+		definition, err := ioutil.ReadFile(filepath.Join(outputDir, "blueprint.yaml"))
+		require.Nil(t, err)
+		doc, err := parseTemplateMetadataV2(&definition, "my-app", nil)
+		require.Nil(t, err)
+		require.Len(t, doc.Variables, 5)
+		assert.Equal(t, TypeInput, doc.Variables[0].Type.Value)
+		assert.Equal(t, "my-app", doc.Variables[0].Default.Value)
+		assert.Equal(t, TypeSelect, doc.Variables[2].Type.Value)
+		assert.Equal(t, []VarField{{Value: "dev"}, {Value: "prod"}}, doc.Variables[2].Options)
+		assert.Equal(t, TypeConfirm, doc.Variables[3].Type.Value)
+		assert.True(t, doc.Variables[3].Default.Bool)
+		assert.Len(t, doc.TemplateConfigs, 5)
+
+		// the extracted blueprint generates the original project again
+		blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+		require.Nil(t, err)
+		engine, err := NewEngine(EngineOptions{Context: blueprintContext, SkipFinalPrompt: true})
+		require.Nil(t, err)
+		fs := NewMemoryFS()
+		_, _, err = engine.Instantiate(
+			BlueprintParams{TemplatePath: "my-app", UseDefaultsAsValue: true},
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: fs},
+		)
+		require.Nil(t, err)
+		content, err := fs.ReadFile("app.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "name: my-app\ndatabase: my-app-db\nenv: dev\ndevice: devices\npublic: true # exposed\nuntrue: trueish\nnote: it is true\nchart: {{ .Values.name }}", string(content))
+	})
+
+	t.Run("should fail when a value is given for two parameters", func(t *testing.T) {
+		projectDir, repoDir := newProject(t, map[string]string{"app.yaml": "name: my-app\n"})
+		_, err := ExtractBlueprint(ExtractOptions{
+			SourceDir: projectDir,
+			OutputDir: filepath.Join(repoDir, "my-app"),
+			Name:      "My App",
+			Literals:  []ExtractLiteral{{Name: "AppName", Value: "my-app"}, {Name: "Other", Value: "my-app"}},
+		})
+		require.NotNil(t, err)
+		assert.Equal(t, "value [my-app] is given for both parameters [AppName] and [Other]", err.Error())
+		assert.False(t, fileExists(filepath.Join(repoDir, "my-app")))
+	})
+}
@@ -0,0 +1,299 @@
+package blueprint
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputFS is the filesystem blueprint output files are written to
+type OutputFS interface {
+	Create(name string) (io.WriteCloser, error)
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (os.FileInfo, error)
+	Mkdir(name string, perm os.FileMode) error
+	MkdirAll(name string, perm os.FileMode) error
+	ReadDirNames(name string) ([]string, error)
+	Remove(name string) error
+	RemoveAll(name string) error
+	Rename(oldName, newName string) error
+}
+
+// OSFS is the local filesystem, used by GeneratedBlueprint when no other filesystem is set
+var OSFS OutputFS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Create(name string) (io.WriteCloser, error)   { return os.Create(name) }
+func (osFS) ReadFile(name string) ([]byte, error)         { return ioutil.ReadFile(name) }
+func (osFS) Stat(name string) (os.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Mkdir(name string, perm os.FileMode) error    { return os.Mkdir(name, perm) }
+func (osFS) MkdirAll(name string, perm os.FileMode) error { return os.MkdirAll(name, perm) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) RemoveAll(name string) error                  { return os.RemoveAll(name) }
+func (osFS) Rename(oldName, newName string) error         { return os.Rename(oldName, newName) }
+
+func (osFS) ReadDirNames(name string) ([]string, error) {
+	dir, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	return dir.Readdirnames(-1)
+}
+
+// MemoryFS keeps all files in memory, paths are relative to its root which always exists
+type MemoryFS struct {
+	mutex sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewMemoryFS returns an empty in-memory filesystem
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{
+		files: make(map[string][]byte),
+		dirs:  map[string]bool{".": true},
+	}
+}
+
+// memoryPath cleans the path, so that the same file is always stored under the same key
+func memoryPath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+func memoryPathError(op string, name string, err error) error {
+	return &os.PathError{Op: op, Path: name, Err: err}
+}
+
+type memoryFile struct {
+	bytes.Buffer
+	fs   *MemoryFS
+	name string
+}
+
+// Close stores the written contents in the filesystem
+func (file *memoryFile) Close() error {
+	file.fs.mutex.Lock()
+	defer file.fs.mutex.Unlock()
+	file.fs.files[file.name] = file.Bytes()
+	return nil
+}
+
+type memoryFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (info memoryFileInfo) Name() string       { return path.Base(info.name) }
+func (info memoryFileInfo) Size() int64        { return info.size }
+func (info memoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (info memoryFileInfo) IsDir() bool        { return info.isDir }
+func (info memoryFileInfo) Sys() interface{}   { return nil }
+
+func (info memoryFileInfo) Mode() os.FileMode {
+	if info.isDir {
+		return os.ModeDir | os.ModePerm
+	}
+	return 0666
+}
+
+// Create creates or truncates the file, the contents are stored when the returned file is closed
+func (fs *MemoryFS) Create(name string) (io.WriteCloser, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	if fs.dirs[name] {
+		return nil, memoryPathError("open", name, os.ErrExist)
+	}
+	if !fs.dirs[path.Dir(name)] {
+		return nil, memoryPathError("open", name, os.ErrNotExist)
+	}
+	fs.files[name] = []byte{}
+	return &memoryFile{fs: fs, name: name}, nil
+}
+
+// ReadFile returns the contents of the file
+func (fs *MemoryFS) ReadFile(name string) ([]byte, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	content, ok := fs.files[memoryPath(name)]
+	if !ok {
+		return nil, memoryPathError("open", name, os.ErrNotExist)
+	}
+	return append([]byte{}, content...), nil
+}
+
+// Stat returns the file info of the file or directory
+func (fs *MemoryFS) Stat(name string) (os.FileInfo, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	if fs.dirs[name] {
+		return memoryFileInfo{name: name, isDir: true}, nil
+	}
+	if content, ok := fs.files[name]; ok {
+		return memoryFileInfo{name: name, size: int64(len(content))}, nil
+	}
+	return nil, memoryPathError("stat", name, os.ErrNotExist)
+}
+
+// Mkdir creates the directory, its parent directory has to exist
+func (fs *MemoryFS) Mkdir(name string, perm os.FileMode) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	if _, ok := fs.files[name]; ok || fs.dirs[name] {
+		return memoryPathError("mkdir", name, os.ErrExist)
+	}
+	if !fs.dirs[path.Dir(name)] {
+		return memoryPathError("mkdir", name, os.ErrNotExist)
+	}
+	fs.dirs[name] = true
+	return nil
+}
+
+// MkdirAll creates the directory along with its missing parent directories
+func (fs *MemoryFS) MkdirAll(name string, perm os.FileMode) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	for dir := name; !fs.dirs[dir]; dir = path.Dir(dir) {
+		if _, ok := fs.files[dir]; ok {
+			return memoryPathError("mkdir", dir, os.ErrExist)
+		}
+	}
+	for dir := name; !fs.dirs[dir]; dir = path.Dir(dir) {
+		fs.dirs[dir] = true
+	}
+	return nil
+}
+
+// ReadDirNames returns the names of the files and directories in the directory
+func (fs *MemoryFS) ReadDirNames(name string) ([]string, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	if !fs.dirs[name] {
+		return nil, memoryPathError("open", name, os.ErrNotExist)
+	}
+	var names []string
+	for _, child := range fs.children(name) {
+		if path.Dir(child) == name {
+			names = append(names, path.Base(child))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// children returns all files and directories under the directory, at any depth
+func (fs *MemoryFS) children(dir string) []string {
+	var children []string
+	isChild := func(name string) bool {
+		return name != dir && (dir == "." || strings.HasPrefix(name, dir+"/"))
+	}
+	for name := range fs.files {
+		if isChild(name) {
+			children = append(children, name)
+		}
+	}
+	for name := range fs.dirs {
+		if isChild(name) {
+			children = append(children, name)
+		}
+	}
+	return children
+}
+
+// Remove removes the file or the empty directory
+func (fs *MemoryFS) Remove(name string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	if _, ok := fs.files[name]; ok {
+		delete(fs.files, name)
+		return nil
+	}
+	if !fs.dirs[name] || name == "." {
+		return memoryPathError("remove", name, os.ErrNotExist)
+	}
+	if len(fs.children(name)) > 0 {
+		return memoryPathError("remove", name, os.ErrExist)
+	}
+	delete(fs.dirs, name)
+	return nil
+}
+
+// RemoveAll removes the file or the directory with everything under it, it does nothing when the path does not exist
+func (fs *MemoryFS) RemoveAll(name string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	name = memoryPath(name)
+	for _, child := range fs.children(name) {
+		delete(fs.files, child)
+		delete(fs.dirs, child)
+	}
+	delete(fs.files, name)
+	if name != "." {
+		delete(fs.dirs, name)
+	}
+	return nil
+}
+
+// Rename moves the file or the directory with everything under it, an existing file at the new path is replaced
+func (fs *MemoryFS) Rename(oldName, newName string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	oldName, newName = memoryPath(oldName), memoryPath(newName)
+	if !fs.dirs[path.Dir(newName)] {
+		return memoryPathError("rename", newName, os.ErrNotExist)
+	}
+	if content, ok := fs.files[oldName]; ok {
+		if fs.dirs[newName] {
+			return memoryPathError("rename", newName, os.ErrExist)
+		}
+		delete(fs.files, oldName)
+		fs.files[newName] = content
+		return nil
+	}
+	if !fs.dirs[oldName] || oldName == "." {
+		return memoryPathError("rename", oldName, os.ErrNotExist)
+	}
+	if _, ok := fs.files[newName]; ok || fs.dirs[newName] {
+		return memoryPathError("rename", newName, os.ErrExist)
+	}
+	for _, child := range fs.children(oldName) {
+		movedChild := newName + strings.TrimPrefix(child, oldName)
+		if content, ok := fs.files[child]; ok {
+			delete(fs.files, child)
+			fs.files[movedChild] = content
+		} else {
+			delete(fs.dirs, child)
+			fs.dirs[movedChild] = true
+		}
+	}
+	delete(fs.dirs, oldName)
+	fs.dirs[newName] = true
+	return nil
+}
+
+// Files returns the paths of all files, sorted
+func (fs *MemoryFS) Files() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	var names []string
+	for name := range fs.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,98 @@
+package blueprint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Formats blueprint output files can be written in
+const (
+	OutputFormatDir   = "dir"
+	OutputFormatZip   = "zip"
+	OutputFormatTarGz = "tar.gz"
+)
+
+// OutputFormats are the supported formats for blueprint output files, the stream of rendered YAML files
+// written by WriteBlueprintStream is not one of them since it does not hold all output files
+var OutputFormats = []string{OutputFormatDir, OutputFormatZip, OutputFormatTarGz}
+
+// ValidateOutputFormat returns an error when the output format is not supported, an empty format means dir
+func ValidateOutputFormat(format string) error {
+	if format != "" && !util.IsStringInSlice(format, OutputFormats) {
+		return fmt.Errorf("unsupported output format [%s], must be one of: %s", format, strings.Join(OutputFormats, ", "))
+	}
+	return nil
+}
+
+// ArchiveFS keeps the output files in memory and writes all of them to the writer in one go when it is closed,
+// as a zip archive or a gzipped tar stream
+type ArchiveFS struct {
+	*MemoryFS
+	format string
+	w      io.Writer
+}
+
+// NewArchiveFS returns a filesystem that writes its files to the writer in the given format when closed
+func NewArchiveFS(format string, w io.Writer) (*ArchiveFS, error) {
+	if err := ValidateOutputFormat(format); err != nil {
+		return nil, err
+	}
+	if format != OutputFormatZip && format != OutputFormatTarGz {
+		return nil, fmt.Errorf("output format [%s] is not an archive format", format)
+	}
+	return &ArchiveFS{MemoryFS: NewMemoryFS(), format: format, w: w}, nil
+}
+
+// Close writes all files to the writer
+func (fs *ArchiveFS) Close() error {
+	if fs.format == OutputFormatZip {
+		return fs.writeZip()
+	}
+	return fs.writeTarGz()
+}
+
+func (fs *ArchiveFS) writeZip() error {
+	zipWriter := zip.NewWriter(fs.w)
+	for _, name := range fs.Files() {
+		content, err := fs.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		file, err := zipWriter.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(content); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+func (fs *ArchiveFS) writeTarGz() error {
+	gzipWriter := gzip.NewWriter(fs.w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range fs.Files() {
+		content, err := fs.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
@@ -0,0 +1,159 @@
+package blueprint
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMemoryFile(t *testing.T, fs OutputFS, name string, content string) {
+	file, err := fs.Create(name)
+	require.Nil(t, err)
+	_, err = io.WriteString(file, content)
+	require.Nil(t, err)
+	require.Nil(t, file.Close())
+}
+
+func TestMemoryFS(t *testing.T) {
+	t.Run("should create files in existing directories only", func(t *testing.T) {
+		fs := NewMemoryFS()
+		_, err := fs.Create("dir/file.txt")
+		assert.True(t, os.IsNotExist(err))
+
+		require.Nil(t, fs.MkdirAll("dir/sub", os.ModePerm))
+		writeMemoryFile(t, fs, "dir/sub/file.txt", "content")
+		writeMemoryFile(t, fs, "./dir/other.txt", "other")
+
+		content, err := fs.ReadFile("dir/sub/file.txt")
+		require.Nil(t, err)
+		assert.Equal(t, "content", string(content))
+		info, err := fs.Stat("dir/sub")
+		require.Nil(t, err)
+		assert.True(t, info.IsDir())
+		names, err := fs.ReadDirNames("dir")
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other.txt", "sub"}, names)
+		assert.Equal(t, []string{"dir/other.txt", "dir/sub/file.txt"}, fs.Files())
+	})
+
+	t.Run("should only remove empty directories", func(t *testing.T) {
+		fs := NewMemoryFS()
+		require.Nil(t, fs.Mkdir("dir", os.ModePerm))
+		writeMemoryFile(t, fs, "dir/file.txt", "content")
+
+		assert.NotNil(t, fs.Remove("dir"))
+		require.Nil(t, fs.Remove("dir/file.txt"))
+		require.Nil(t, fs.Remove("dir"))
+		_, err := fs.Stat("dir")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should rename files and directories", func(t *testing.T) {
+		fs := NewMemoryFS()
+		require.Nil(t, fs.MkdirAll("staging/files/sub", os.ModePerm))
+		writeMemoryFile(t, fs, "staging/files/sub/file.txt", "content")
+		writeMemoryFile(t, fs, "existing.txt", "old")
+		writeMemoryFile(t, fs, "new.txt", "new")
+
+		require.Nil(t, fs.Rename("new.txt", "existing.txt"))
+		require.Nil(t, fs.Rename("staging/files", "out"))
+		assert.Equal(t, []string{"existing.txt", "out/sub/file.txt"}, fs.Files())
+		content, err := fs.ReadFile("existing.txt")
+		require.Nil(t, err)
+		assert.Equal(t, "new", string(content))
+
+		require.Nil(t, fs.RemoveAll("out"))
+		assert.Equal(t, []string{"existing.txt"}, fs.Files())
+	})
+}
+
+func TestInstantiateBlueprint_MemoryFS(t *testing.T) {
+	SkipFinalPrompt = true
+	workDir, err := os.Getwd()
+	require.Nil(t, err)
+
+	fs := NewMemoryFS()
+	gb := &GeneratedBlueprint{OutputDir: "xebialabs", FS: fs}
+	_, _, err = InstantiateBlueprint(
+		BlueprintParams{TemplatePath: "composed", UseDefaultsAsValue: true},
+		getLocalTestBlueprintContext(t),
+		gb, nil,
+	)
+	require.Nil(t, err)
+
+	assert.Contains(t, fs.Files(), "xld-infrastructure.yml")
+	assert.Contains(t, fs.Files(), "xebialabs/values.xlvals")
+	assert.Contains(t, fs.Files(), "xebialabs/.blueprint.lock.yaml")
+	assert.False(t, fileExists("xld-infrastructure.yml"))
+	assert.Empty(t, stagingDirs(t, workDir))
+
+	require.Nil(t, gb.Cleanup())
+	assert.Empty(t, fs.Files())
+}
+
+func TestArchiveFS(t *testing.T) {
+	writeArchiveFiles := func(t *testing.T, fs *ArchiveFS) {
+		require.Nil(t, fs.Mkdir("xebialabs", os.ModePerm))
+		writeMemoryFile(t, fs, "xebialabs/values.xlvals", "a = b\n")
+		writeMemoryFile(t, fs, "app.yaml", "name: app")
+		require.Nil(t, fs.Close())
+	}
+
+	t.Run("should write zip archive", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		fs, err := NewArchiveFS(OutputFormatZip, out)
+		require.Nil(t, err)
+		writeArchiveFiles(t, fs)
+
+		reader, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		require.Nil(t, err)
+		require.Len(t, reader.File, 2)
+		assert.Equal(t, "app.yaml", reader.File[0].Name)
+		assert.Equal(t, "xebialabs/values.xlvals", reader.File[1].Name)
+		file, err := reader.File[0].Open()
+		require.Nil(t, err)
+		content, err := ioutil.ReadAll(file)
+		require.Nil(t, err)
+		assert.Equal(t, "name: app", string(content))
+	})
+
+	t.Run("should write tar.gz stream", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		fs, err := NewArchiveFS(OutputFormatTarGz, out)
+		require.Nil(t, err)
+		writeArchiveFiles(t, fs)
+
+		gzipReader, err := gzip.NewReader(out)
+		require.Nil(t, err)
+		tarReader := tar.NewReader(gzipReader)
+		var names []string
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.Nil(t, err)
+			names = append(names, header.Name)
+		}
+		assert.Equal(t, []string{"app.yaml", "xebialabs/values.xlvals"}, names)
+	})
+
+	t.Run("should fail on unsupported formats", func(t *testing.T) {
+		_, err := NewArchiveFS("rar", &bytes.Buffer{})
+		require.NotNil(t, err)
+		assert.Equal(t, "unsupported output format [rar], must be one of: dir, zip, tar.gz", err.Error())
+		_, err = NewArchiveFS(OutputFormatDir, &bytes.Buffer{})
+		assert.NotNil(t, err)
+		_, err = NewArchiveFS(OutputFormatStdout, &bytes.Buffer{})
+		assert.NotNil(t, err)
+		assert.NotNil(t, ValidateOutputFormat(OutputFormatStdout))
+	})
+}
@@ -7,22 +7,65 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/xebialabs/blueprint-cli/pkg/models"
 	"github.com/xebialabs/blueprint-cli/pkg/util"
 )
 
 // GeneratedBlueprint keeps track of all files and directories that were generated as part of the blueprint process.
+// When RootDir is set, all output files are generated under it instead of the current working directory.
+// PreExistingFiles are output files that existed before the blueprint process, they are never removed on cleanup.
+// After BeginStaging, output files are written into a staging directory until they are promoted with Commit.
+// FS is the filesystem the output files are written to, the local filesystem is used when it is not set.
+// Logger receives the messages about the output files, they are printed to stdout when it is not set.
 type GeneratedBlueprint struct {
-	OutputDir      string
-	GeneratedFiles []string
+	RootDir          string
+	OutputDir        string
+	GeneratedFiles   []string
+	PreExistingFiles []string
+	FS               OutputFS
+	Logger           Logger
+
+	stagingDir     string
+	stagedFiles    map[string]string
+	stagedOrder    []string
+	stagedRemovals map[string]bool
+	backupFiles    []string
+	journal        []journalEntry
+	interruptedBy  os.Signal
+	mutex          sync.Mutex
+}
+
+// outputPath returns the path of the output file under the root directory
+func (generatedBlueprint *GeneratedBlueprint) outputPath(fileName string) string {
+	if generatedBlueprint.RootDir == "" {
+		return fileName
+	}
+	return filepath.Join(generatedBlueprint.RootDir, fileName)
+}
+
+// fs returns the filesystem the output files are written to
+func (generatedBlueprint *GeneratedBlueprint) fs() OutputFS {
+	if generatedBlueprint.FS == nil {
+		return OSFS
+	}
+	return generatedBlueprint.FS
+}
+
+// log returns the logger of the messages about the output files
+func (generatedBlueprint *GeneratedBlueprint) log() Logger {
+	if generatedBlueprint.Logger == nil {
+		return cliLogger{}
+	}
+	return generatedBlueprint.Logger
 }
 
 // createDirectoryIfNeeded will create a Directory if it does not exist and add it to the GeneratedBlueprint context object.
 func (generatedBlueprint *GeneratedBlueprint) createDirectoryIfNeeded(dirName string) error {
-	util.Verbose("[file] Checking whether path %s exists\n", dirName)
-	if exists(dirName) {
-		if b, _ := isDirectory(dirName); !b {
+	generatedBlueprint.log().Verbose("[file] Checking whether path %s exists\n", dirName)
+	if generatedBlueprint.pathExists(dirName, false) {
+		if b, _ := generatedBlueprint.isDirectory(dirName); !b {
 			return fmt.Errorf("%s exists but is not a directory", dirName)
 		}
 		return nil
@@ -38,8 +81,8 @@ func (generatedBlueprint *GeneratedBlueprint) createDirectoryIfNeeded(dirName st
 }
 
 func (generatedBlueprint *GeneratedBlueprint) createDirectory(dirname string) error {
-	util.Verbose("[file] Creating directory %s\n", dirname)
-	err := os.Mkdir(dirname, os.ModePerm)
+	generatedBlueprint.log().Verbose("[file] Creating directory %s\n", dirname)
+	err := generatedBlueprint.fs().Mkdir(dirname, os.ModePerm)
 	if err != nil {
 		return err
 	}
@@ -47,18 +90,28 @@ func (generatedBlueprint *GeneratedBlueprint) createDirectory(dirname string) er
 	return nil
 }
 
-// GetOutputFile will return a newly created (or truncated) file.
-func (generatedBlueprint *GeneratedBlueprint) GetOutputFile(fileName string) (*os.File, error) {
-	if err := generatedBlueprint.createDirectoryIfNeeded(filepath.Dir(fileName)); err != nil {
-		return nil, err
+// GetOutputFile will return a newly created (or truncated) file, relative to the root directory if set.
+// While staging, the returned file is the staged version of the output file.
+func (generatedBlueprint *GeneratedBlueprint) GetOutputFile(fileName string) (io.WriteCloser, error) {
+	outputFile := generatedBlueprint.outputPath(fileName)
+	if generatedBlueprint.stagingDir != "" {
+		return generatedBlueprint.getStagedFile(fileName, outputFile)
 	}
-	util.Verbose("[file] Creating file %s\n", fileName)
-	file, err := os.Create(fileName)
-	if err != nil {
+	if err := generatedBlueprint.createDirectoryIfNeeded(filepath.Dir(outputFile)); err != nil {
 		return nil, err
 	}
-	generatedBlueprint.GeneratedFiles = append(generatedBlueprint.GeneratedFiles, fileName)
-	return file, nil
+	generatedBlueprint.log().Verbose("[file] Creating file %s\n", outputFile)
+	generatedBlueprint.registerOutputFile(outputFile)
+	return generatedBlueprint.fs().Create(outputFile)
+}
+
+// registerOutputFile adds the output file to the generated files
+func (generatedBlueprint *GeneratedBlueprint) registerOutputFile(outputFile string) {
+	if generatedBlueprint.pathExists(outputFile, false) && !util.IsStringInSlice(outputFile, generatedBlueprint.GeneratedFiles) {
+		// keep track of existing files so that they are not removed on cleanup
+		generatedBlueprint.PreExistingFiles = append(generatedBlueprint.PreExistingFiles, outputFile)
+	}
+	generatedBlueprint.GeneratedFiles = append(generatedBlueprint.GeneratedFiles, outputFile)
 }
 
 // Cleanup will cleanup all generated blueprint files
@@ -67,11 +120,11 @@ func (generatedBlueprint *GeneratedBlueprint) Cleanup(filesSkipped ...string) er
 
 	// Clean all files first
 	for _, file := range generatedBlueprint.GeneratedFiles {
-		if isDir, _ := isDirectory(file); isDir {
+		if isDir, _ := generatedBlueprint.isDirectory(file); isDir {
 			directories = append(directories, file)
-		} else if util.PathExists(file, false) {
-			if !util.IsStringInSlice(file, filesSkipped) {
-				if err := os.Remove(file); err != nil {
+		} else if generatedBlueprint.pathExists(file, false) {
+			if !util.IsStringInSlice(file, filesSkipped) && !util.IsStringInSlice(file, generatedBlueprint.PreExistingFiles) {
+				if err := generatedBlueprint.fs().Remove(file); err != nil {
 					return err
 				}
 			}
@@ -83,10 +136,10 @@ func (generatedBlueprint *GeneratedBlueprint) Cleanup(filesSkipped ...string) er
 	xebialabsDir := ""
 
 	for _, dir := range directories {
-		util.Verbose("[file] Removing directory %s\n", dir)
-		if util.PathExists(dir, true) {
-			if empty, _ := isDirectoryEmpty(dir); empty {
-				if err := os.Remove(dir); err != nil {
+		generatedBlueprint.log().Verbose("[file] Removing directory %s\n", dir)
+		if generatedBlueprint.pathExists(dir, true) {
+			if empty, _ := generatedBlueprint.isDirectoryEmpty(dir); empty {
+				if err := generatedBlueprint.fs().Remove(dir); err != nil {
 					return err
 				}
 			} else {
@@ -99,7 +152,7 @@ func (generatedBlueprint *GeneratedBlueprint) Cleanup(filesSkipped ...string) er
 
 	// Manually remove the xebialabs directory
 	if xebialabsDir != "" {
-		if err := os.Remove(models.BlueprintOutputDir); err != nil {
+		if err := generatedBlueprint.fs().Remove(generatedBlueprint.outputPath(models.BlueprintOutputDir)); err != nil {
 			return err
 		}
 	}
@@ -107,29 +160,27 @@ func (generatedBlueprint *GeneratedBlueprint) Cleanup(filesSkipped ...string) er
 	return nil
 }
 
-func isDirectory(path string) (bool, error) {
-	fileInfo, err := os.Stat(path)
+// pathExists checks whether the path exists in the output filesystem, and whether it is a directory if required
+func (generatedBlueprint *GeneratedBlueprint) pathExists(name string, mustBeDir bool) bool {
+	info, err := generatedBlueprint.fs().Stat(name)
 	if err != nil {
-		return false, err
+		return false
 	}
-	return fileInfo.IsDir(), err
+	return !mustBeDir || info.IsDir()
 }
 
-func isDirectoryEmpty(name string) (bool, error) {
-	f, err := os.Open(name)
+func (generatedBlueprint *GeneratedBlueprint) isDirectory(path string) (bool, error) {
+	fileInfo, err := generatedBlueprint.fs().Stat(path)
 	if err != nil {
 		return false, err
 	}
-	defer f.Close()
-
-	_, err = f.Readdirnames(1) // Or f.Readdir(1)
-	if err == io.EOF {
-		return true, nil
-	}
-	return false, err // Either not empty or error, suits both cases
+	return fileInfo.IsDir(), err
 }
 
-func exists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil || os.IsExist(err)
+func (generatedBlueprint *GeneratedBlueprint) isDirectoryEmpty(name string) (bool, error) {
+	names, err := generatedBlueprint.fs().ReadDirNames(name)
+	if err != nil {
+		return false, err
+	}
+	return len(names) == 0, nil
 }
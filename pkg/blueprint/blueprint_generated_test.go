@@ -66,6 +66,27 @@ func TestGeneratedBlueprintDoesNotRegisterExistingDirectory(t *testing.T) {
 	os.Remove("foo")
 }
 
+func TestGeneratedBlueprintWithRootDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rootDirTest")
+	require.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	rootDir := filepath.Join(tmpDir, "out")
+	gb := GeneratedBlueprint{RootDir: rootDir, OutputDir: "xebialabs"}
+	for _, file := range []string{"xebialabs/values.xlvals", "foo/foo.tmp"} {
+		f, err := gb.GetOutputFile(file)
+		require.Nil(t, err)
+		f.Close()
+		assert.FileExists(t, filepath.Join(rootDir, file))
+		assert.False(t, exists(file))
+	}
+	assert.Contains(t, gb.GeneratedFiles, rootDir)
+
+	require.Nil(t, gb.Cleanup())
+	assert.False(t, exists(rootDir))
+	assert.True(t, exists(tmpDir))
+}
+
 func TestCreateDirectoryIfNeeded(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("", "pathTest")
 	require.Nil(t, err)
@@ -92,3 +113,8 @@ func TestCreateDirectoryIfNeeded(t *testing.T) {
 		})
 	}
 }
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil || os.IsExist(err)
+}
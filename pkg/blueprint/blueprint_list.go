@@ -0,0 +1,67 @@
+package blueprint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// BlueprintInfo holds the metadata of a blueprint found in a repository
+type BlueprintInfo struct {
+	Path        string `json:"path" yaml:"path"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	Author      string `json:"author" yaml:"author"`
+	Version     string `json:"version" yaml:"version"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ListBlueprints returns the metadata of all blueprints in the active repository sorted by path,
+// blueprints with an invalid definition file are listed with the parsing error. The metadata provided by the
+// repository index is used when there is one, without reading the definition files
+func (blueprintContext *BlueprintContext) ListBlueprints() ([]BlueprintInfo, error) {
+	util.Verbose("[list] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var blueprintPaths []string
+	for k := range blueprints {
+		// Hide blueprints in the fragments directory
+		if !strings.HasPrefix(k, fragmentsDir) {
+			blueprintPaths = append(blueprintPaths, k)
+		}
+	}
+	sort.Strings(blueprintPaths)
+
+	infos := make([]BlueprintInfo, 0, len(blueprintPaths))
+	for _, blueprintPath := range blueprintPaths {
+		if metadata := blueprints[blueprintPath].Metadata; metadata != nil {
+			// the repository index has the metadata, no need to read the definition file
+			infos = append(infos, BlueprintInfo{
+				Path:        blueprintPath,
+				Name:        metadata.Name,
+				Description: metadata.Description,
+				Author:      metadata.Author,
+				Version:     metadata.Version,
+			})
+			continue
+		}
+		blueprintDoc, err := blueprintContext.parseDefinitionFile(blueprints[blueprintPath], blueprintPath)
+		if err != nil {
+			util.Verbose("[list] Error while parsing blueprint [%s]: %s\n", blueprintPath, err.Error())
+			infos = append(infos, BlueprintInfo{Path: blueprintPath, Error: err.Error()})
+			continue
+		}
+		infos = append(infos, BlueprintInfo{
+			Path:        blueprintPath,
+			Name:        blueprintDoc.Metadata.Name,
+			Description: blueprintDoc.Metadata.Description,
+			Author:      blueprintDoc.Metadata.Author,
+			Version:     blueprintDoc.Metadata.Version,
+		})
+	}
+	return infos, nil
+}
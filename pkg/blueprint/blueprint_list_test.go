@@ -0,0 +1,73 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueprintContext_ListBlueprints(t *testing.T) {
+	t.Run("should list blueprints of local repository with metadata", func(t *testing.T) {
+		blueprintContext := getLocalTestBlueprintContext(t)
+		infos, err := blueprintContext.ListBlueprints()
+		require.Nil(t, err)
+		require.NotEmpty(t, infos)
+
+		var paths []string
+		for _, info := range infos {
+			paths = append(paths, info.Path)
+		}
+		assert.IsIncreasing(t, paths)
+		assert.Contains(t, infos, BlueprintInfo{
+			Path:        "answer-input",
+			Name:        "Test Project",
+			Description: "Is just a test blueprint project",
+			Author:      "XebiaLabs",
+			Version:     "1.0",
+		})
+		assert.Contains(t, infos, BlueprintInfo{
+			Path:  "invalid",
+			Error: "parameter AppName must have a 'prompt' field",
+		})
+	})
+
+	t.Run("should list blueprints of http repository with the metadata of its index", func(t *testing.T) {
+		configdir, err := ioutil.TempDir("", "xebialabsconfig")
+		require.Nil(t, err)
+		blueprintContext, err := ConstructBlueprintContext(GetViperConf(t, defaultContextYaml), filepath.Join(configdir, "config.yaml"), DummyCLIVersion)
+		require.Nil(t, err)
+
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		// only the index is served, the definition files must not be read
+		httpmock.RegisterResponder(
+			"GET",
+			"http://mock.repo.server.com/index.json",
+			httpmock.NewStringResponder(200, `{
+				"indexVersion": 2,
+				"version": "9.1.0",
+				"blueprints": [{
+					"path": "aws/monolith",
+					"definitionFile": {"path": "blueprint.yaml", "size": 10, "sha256": "abc"},
+					"metadata": {"name": "Monolith", "description": "A monolith", "author": "XebiaLabs", "version": "1.0"},
+					"files": []
+				}]
+			}`),
+		)
+
+		infos, err := blueprintContext.ListBlueprints()
+		require.Nil(t, err)
+		assert.Equal(t, []BlueprintInfo{{
+			Path:        "aws/monolith",
+			Name:        "Monolith",
+			Description: "A monolith",
+			Author:      "XebiaLabs",
+			Version:     "1.0",
+		}}, infos)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+}
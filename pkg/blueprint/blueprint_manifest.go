@@ -0,0 +1,134 @@
+package blueprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/yaml"
+)
+
+const manifestFile = ".blueprint.lock.yaml"
+
+// BlueprintManifest records what was generated by a blueprint run, from which blueprint and with which answers
+type BlueprintManifest struct {
+	Blueprint          string                 `yaml:"blueprint"`
+	Version            string                 `yaml:"version,omitempty"`
+	Repository         ManifestRepository     `yaml:"repository"`
+	ComposedBlueprints []string               `yaml:"composedBlueprints,omitempty"`
+	Answers            map[string]interface{} `yaml:"answers"`
+	Files              []ManifestFile         `yaml:"files"`
+}
+
+// ManifestRepository is the repository the blueprint was read from
+type ManifestRepository struct {
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider"`
+}
+
+// ManifestFile is a generated file along with the SHA-256 checksum of its contents
+type ManifestFile struct {
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// newBlueprintManifest creates the manifest for the generated blueprint, only answers of non-secret fields are recorded
+func newBlueprintManifest(
+	blueprintContext *BlueprintContext,
+	templatePath string,
+	blueprintDoc *BlueprintConfig,
+	composedBlueprints []string,
+	preparedData *PreparedData,
+	generatedBlueprint *GeneratedBlueprint,
+) (*BlueprintManifest, error) {
+	manifest := &BlueprintManifest{
+		Blueprint:          templatePath,
+		Version:            blueprintDoc.Metadata.Version,
+		ComposedBlueprints: composedBlueprints,
+		Answers:            make(map[string]interface{}),
+	}
+	if blueprintContext.ActiveRepo != nil {
+		manifest.Repository = ManifestRepository{
+			Name:     (*blueprintContext.ActiveRepo).GetName(),
+			Provider: (*blueprintContext.ActiveRepo).GetProvider(),
+		}
+	}
+	util.CopyIntoStringInterfaceMap(preparedData.Answers, manifest.Answers)
+
+	for _, file := range generatedBlueprint.GeneratedFiles {
+		if isDir, _ := generatedBlueprint.isDirectory(file); isDir {
+			continue
+		}
+		content, err := generatedBlueprint.fs().ReadFile(generatedBlueprint.writtenPath(file))
+		if err != nil {
+			return nil, err
+		}
+		checksum := contentChecksum(content)
+		relPath := file
+		if generatedBlueprint.RootDir != "" {
+			if relPath, err = filepath.Rel(generatedBlueprint.RootDir, file); err != nil {
+				return nil, err
+			}
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{Path: filepath.ToSlash(relPath), SHA256: checksum})
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest, nil
+}
+
+// writeBlueprintManifest writes the manifest to the blueprint output directory
+func writeBlueprintManifest(generatedBlueprint *GeneratedBlueprint, manifest *BlueprintManifest) error {
+	content, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	data := string(content)
+	return writeDataToFile(generatedBlueprint, filepath.Join(generatedBlueprint.OutputDir, manifestFile), &data)
+}
+
+// ReadBlueprintManifest reads the manifest of a blueprint generated in the project directory
+func ReadBlueprintManifest(projectDir string) (*BlueprintManifest, error) {
+	manifestPath := filepath.Join(projectDir, models.BlueprintOutputDir, manifestFile)
+	content, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("blueprint manifest not found in path %s", manifestPath)
+		}
+		return nil, err
+	}
+	manifest := &BlueprintManifest{}
+	if err := yaml.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("cannot parse blueprint manifest %s: %s", manifestPath, err.Error())
+	}
+	return manifest, nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of the file contents
+func fileChecksum(fileName string) (string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// contentChecksum returns the hex encoded SHA-256 checksum of the contents
+func contentChecksum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
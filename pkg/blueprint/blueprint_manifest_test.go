@@ -0,0 +1,65 @@
+package blueprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/yaml"
+)
+
+func TestInstantiateBlueprint_Manifest(t *testing.T) {
+	SkipFinalPrompt = true
+	outputRoot, err := ioutil.TempDir("", "manifest")
+	require.Nil(t, err)
+	defer os.RemoveAll(outputRoot)
+
+	gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+	_, _, err = InstantiateBlueprint(
+		BlueprintParams{
+			TemplatePath:       "composed",
+			UseDefaultsAsValue: true,
+			OutputRoot:         outputRoot,
+		},
+		getLocalTestBlueprintContext(t),
+		gb, nil,
+	)
+	require.Nil(t, err)
+
+	manifestPath := filepath.Join(outputRoot, "xebialabs", manifestFile)
+	assert.Contains(t, gb.GeneratedFiles, manifestPath)
+	content, err := ioutil.ReadFile(manifestPath)
+	require.Nil(t, err)
+	manifest := BlueprintManifest{}
+	require.Nil(t, yaml.Unmarshal(content, &manifest))
+
+	t.Run("should record blueprint and repository", func(t *testing.T) {
+		assert.Equal(t, "composed", manifest.Blueprint)
+		assert.Equal(t, ManifestRepository{Name: "Test", Provider: "local"}, manifest.Repository)
+		assert.Equal(t, []string{"valid-no-prompt", "defaults-as-values"}, manifest.ComposedBlueprints)
+	})
+
+	t.Run("should record non-secret answers only", func(t *testing.T) {
+		assert.Equal(t, "TestApp", manifest.Answers["AppName"])
+		assert.NotContains(t, manifest.Answers, "AWSAccessKey")
+	})
+
+	t.Run("should record checksum of generated files", func(t *testing.T) {
+		files := make(map[string]string)
+		for _, file := range manifest.Files {
+			files[file.Path] = file.SHA256
+		}
+		assert.NotContains(t, files, "xebialabs/"+manifestFile)
+		assert.Contains(t, files, "xebialabs/values.xlvals")
+
+		content, err := ioutil.ReadFile(filepath.Join(outputRoot, "xld-infrastructure.yml"))
+		require.Nil(t, err)
+		checksum := sha256.Sum256(content)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), files["xld-infrastructure.yml"])
+	})
+}
@@ -0,0 +1,303 @@
+package blueprint
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// v1 keys renamed in v2
+var migratedParameterKeys = map[string]string{
+	"description":        "prompt",
+	"saveInXlVals":       "saveInXlvals",
+	"useRawValue":        "replaceAsIs",
+	"showValueOnSummary": "revealOnSummary",
+}
+
+var regExExpressionIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// MigrationProblem is a part of an xl/v1 blueprint definition that could not be migrated automatically
+type MigrationProblem struct {
+	Line    int    `json:"line" yaml:"line"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type blueprintMigrator struct {
+	problems []MigrationProblem
+}
+
+// MigrateBlueprintV1 rewrites an xl/v1 blueprint definition as an equivalent xl/v2 document, keeping comments and key order.
+// Parts that cannot be migrated automatically are kept as they are and returned as problems, the migrated document is
+// parsed as an xl/v2 blueprint definition and a problem is returned as well when it is not valid
+func MigrateBlueprintV1(content []byte, templatePath string) ([]byte, []MigrationProblem, error) {
+	doc := yamlv3.Node{}
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return nil, nil, fmt.Errorf("blueprint definition is not a YAML mapping")
+	}
+	root := doc.Content[0]
+	apiVersion := mappingValue(root, "apiVersion")
+	if apiVersion == nil || apiVersion.Value != models.BlueprintYamlFormatV1 {
+		return nil, nil, fmt.Errorf("blueprint definition is not an %s document", models.BlueprintYamlFormatV1)
+	}
+	apiVersion.Value = models.BlueprintYamlFormatV2
+
+	migrator := &blueprintMigrator{}
+	if metadata := mappingValue(root, "metadata"); metadata != nil {
+		renameMappingKey(metadata, "projectName", "name")
+	}
+	spec := migrator.moveToSpec(root)
+	if parameters := mappingValue(spec, "parameters"); parameters != nil {
+		for _, parameter := range parameters.Content {
+			migrator.migrateParameter(parameter)
+		}
+	}
+	if files := mappingValue(spec, "files"); files != nil {
+		for _, file := range files.Content {
+			migrator.migrateDependsOn(file, "writeIf")
+		}
+	}
+	migrator.migrateTags(root)
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	migrated := buf.Bytes()
+	if _, err := parseTemplateMetadataV2(&migrated, templatePath, nil); err != nil {
+		migrator.addProblem(0, "the migrated blueprint definition is not valid yet: %s", err.Error())
+	}
+	return migrated, migrator.problems, nil
+}
+
+func (migrator *blueprintMigrator) addProblem(line int, format string, a ...interface{}) {
+	migrator.problems = append(migrator.problems, MigrationProblem{Line: line, Message: fmt.Sprintf(format, a...)})
+}
+
+// moveToSpec moves the parameters and files defined at the root of the document, as supported for backward
+// compatibility with v8.5, into the spec, which is created when there is none
+func (migrator *blueprintMigrator) moveToSpec(root *yamlv3.Node) *yamlv3.Node {
+	spec := mappingValue(root, "spec")
+	for _, key := range []string{"parameters", "files"} {
+		index := mappingIndex(root, key)
+		if index < 0 {
+			continue
+		}
+		if spec == nil {
+			spec = &yamlv3.Node{Kind: yamlv3.MappingNode}
+			root.Content = append(root.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: "spec"}, spec)
+		}
+		if mappingIndex(spec, key) >= 0 {
+			migrator.addProblem(root.Content[index].Line, "%s are defined both at the root and in the spec, the ones at the root are ignored", key)
+			continue
+		}
+		spec.Content = append(spec.Content, root.Content[index], root.Content[index+1])
+		root.Content = append(root.Content[:index], root.Content[index+2:]...)
+	}
+	if spec == nil {
+		return &yamlv3.Node{Kind: yamlv3.MappingNode}
+	}
+	return spec
+}
+
+func (migrator *blueprintMigrator) migrateParameter(parameter *yamlv3.Node) {
+	if parameter.Kind != yamlv3.MappingNode {
+		return
+	}
+	name := ""
+	if nameNode := mappingValue(parameter, "name"); nameNode != nil {
+		name = nameNode.Value
+	}
+	if mappingIndex(parameter, "description") < 0 && mappingIndex(parameter, "value") < 0 {
+		migrator.addProblem(parameter.Line, "parameter [%s] has no description to use as prompt, add a prompt", name)
+	}
+	for oldKey, newKey := range migratedParameterKeys {
+		renameMappingKey(parameter, oldKey, newKey)
+	}
+
+	if index := mappingIndex(parameter, "secret"); index >= 0 {
+		secret := parameter.Content[index+1]
+		switch {
+		case secret.Tag == "!!bool" || secret.Tag == "!!str":
+			if secret.Value == "true" {
+				if typeNode := mappingValue(parameter, "type"); typeNode != nil {
+					typeNode.Value = getSecretType(typeNode.Value)
+				}
+			}
+			parameter.Content = append(parameter.Content[:index], parameter.Content[index+2:]...)
+		default:
+			migrator.addProblem(secret.Line, "secret of parameter [%s] is not a boolean, set a secret type instead", name)
+		}
+	}
+
+	if index := mappingIndex(parameter, "pattern"); index >= 0 {
+		pattern := parameter.Content[index+1]
+		if pattern.Kind == yamlv3.ScalarNode && (pattern.Tag == "!!str" || pattern.Tag == "") {
+			parameter.Content[index].Value = "validate"
+			setExpression(pattern, fmt.Sprintf("regex('%s', %s)", strings.Replace(pattern.Value, "\\", "\\\\", -1), name))
+		} else {
+			migrator.addProblem(pattern.Line, "pattern of parameter [%s] is not a string, use a validate expression instead", name)
+		}
+	}
+
+	migrator.migrateDependsOn(parameter, "promptIf")
+}
+
+// migrateDependsOn replaces the dependsOnTrue and dependsOnFalse conditions with a single expression under the given key.
+// xl/v1 reads both into the same field, so when several are set only the last one applies and the others are dropped.
+func (migrator *blueprintMigrator) migrateDependsOn(item *yamlv3.Node, key string) {
+	if item.Kind != yamlv3.MappingNode {
+		return
+	}
+	var dependsOnKeys []string
+	for _, dependsOnKey := range []string{"dependsOnTrue", "dependsOn", "dependsOnFalse"} {
+		if mappingIndex(item, dependsOnKey) >= 0 {
+			dependsOnKeys = append(dependsOnKeys, dependsOnKey)
+		}
+	}
+	if len(dependsOnKeys) == 0 {
+		return
+	}
+	appliedKey := dependsOnKeys[len(dependsOnKeys)-1]
+	value := item.Content[mappingIndex(item, appliedKey)+1]
+	condition, ok := migrator.toExpression(value)
+	if !ok {
+		return
+	}
+	if appliedKey == "dependsOnFalse" {
+		condition = "!" + wrapExpression(condition)
+	}
+
+	// the expression takes the place of the first condition to keep the key order
+	first := len(item.Content)
+	for _, dependsOnKey := range dependsOnKeys {
+		if index := mappingIndex(item, dependsOnKey); index < first {
+			first = index
+		}
+	}
+	for _, dependsOnKey := range dependsOnKeys[:len(dependsOnKeys)-1] {
+		migrator.addProblem(item.Content[mappingIndex(item, dependsOnKey)].Line, "%s is ignored by xl/v1 when %s is set, it is not migrated", dependsOnKey, appliedKey)
+	}
+	item.Content[first].Value = key
+	item.Content[first+1] = value
+	setExpression(value, condition)
+	for i := len(item.Content) - 2; i > first; i -= 2 {
+		if isDependsOnKey(item.Content[i].Value, dependsOnKeys) {
+			item.Content = append(item.Content[:i], item.Content[i+2:]...)
+		}
+	}
+}
+
+func isDependsOnKey(key string, dependsOnKeys []string) bool {
+	for _, dependsOnKey := range dependsOnKeys {
+		if key == dependsOnKey {
+			return true
+		}
+	}
+	return false
+}
+
+// toExpression returns the v2 expression of a v1 dependsOn value, which is the name of a parameter, an expression or a function
+func (migrator *blueprintMigrator) toExpression(value *yamlv3.Node) (string, bool) {
+	switch value.Tag {
+	case tagExpressionV1, tagExpressionV2:
+		return value.Value, true
+	case tagFnV1:
+		// functions without an equivalent are reported while migrating the tags
+		return fnToExpression(value.Value)
+	}
+	if !regExExpressionIdentifier.MatchString(value.Value) {
+		migrator.addProblem(value.Line, "[%s] is not a parameter name, rewrite it as an expression", value.Value)
+		return "", false
+	}
+	return value.Value, true
+}
+
+// migrateTags replaces the v1 tags left in the document with expressions
+func (migrator *blueprintMigrator) migrateTags(node *yamlv3.Node) {
+	switch node.Tag {
+	case tagExpressionV1:
+		node.Tag = tagExpressionV2
+	case tagFnV1:
+		if expression, ok := fnToExpression(node.Value); ok {
+			setExpression(node, expression)
+		} else {
+			migrator.addProblem(node.Line, "function [%s] has no expression function equivalent, rewrite it as an expression", node.Value)
+		}
+	}
+	for _, child := range node.Content {
+		migrator.migrateTags(child)
+	}
+}
+
+// fnToExpression returns the expression calling the expression function equivalent to a v1 function
+func fnToExpression(fn string) (string, bool) {
+	fn = strings.TrimSpace(fn)
+	groups := regExFn.FindStringSubmatch(fn)
+	if len(groups) != 6 || groups[0] != fn {
+		return "", false
+	}
+	domain, module, params, attr, index := groups[1], strings.ToLower(groups[2]), strings.TrimSpace(groups[3]), groups[4], groups[5]
+	switch {
+	case domain == FnK8S && module == "config" && attr != "" && index == "":
+		if params != "" {
+			return fmt.Sprintf("k8sConfig('%s', '%s')", attr, params), true
+		}
+		return fmt.Sprintf("k8sConfig('%s')", attr), true
+	case domain == FnOs && params == "" && attr == "" && index == "":
+		return fmt.Sprintf("os('%s')", module), true
+	}
+	return "", false
+}
+
+// wrapExpression puts an expression in parentheses unless it is a single parameter name
+func wrapExpression(expression string) string {
+	if regExExpressionIdentifier.MatchString(expression) {
+		return expression
+	}
+	return "(" + expression + ")"
+}
+
+func setExpression(node *yamlv3.Node, expression string) {
+	node.Kind = yamlv3.ScalarNode
+	node.Tag = tagExpressionV2
+	node.Value = expression
+	node.Style = yamlv3.DoubleQuotedStyle
+}
+
+// mappingIndex returns the index of the key node in the content of a mapping node, or -1
+func mappingIndex(mapping *yamlv3.Node, key string) int {
+	if mapping == nil || mapping.Kind != yamlv3.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func mappingValue(mapping *yamlv3.Node, key string) *yamlv3.Node {
+	if index := mappingIndex(mapping, key); index >= 0 {
+		return mapping.Content[index+1]
+	}
+	return nil
+}
+
+func renameMappingKey(mapping *yamlv3.Node, oldKey string, newKey string) {
+	if index := mappingIndex(mapping, oldKey); index >= 0 {
+		mapping.Content[index].Value = newKey
+	}
+}
@@ -0,0 +1,126 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const migrateV1Yaml = `# sample v1 blueprint
+apiVersion: xl/v1
+kind: Blueprint
+metadata:
+  projectName: Sample # the name shown in the list
+  description: Sample project
+parameters:
+# the application name
+- name: AppName
+  type: Input
+  description: Application name?
+  pattern: "[a-z\\-]+"
+- name: UseK8s
+  type: Confirm
+  description: Use Kubernetes?
+  default: !fn k8s.config().IsAvailable
+- name: Password
+  type: Input
+  secret: true
+  description: Password?
+  dependsOnFalse: UseK8s
+  useRawValue: true
+  showValueOnSummary: true
+- name: Url
+  type: Input
+  description: Server URL?
+  default: !fn k8s.config(my-context).ClusterServer
+  dependsOnTrue: !expression "UseK8s && AppName != ''"
+  dependsOnFalse: !fn k8s.config().IsAvailable
+files:
+- path: app.yaml.tmpl
+  dependsOnTrue: UseK8s
+- path: other.yaml
+  dependsOnFalse: !expression "UseK8s || AppName == 'x'"
+`
+
+func TestMigrateBlueprintV1(t *testing.T) {
+	t.Run("should migrate a v1 definition keeping comments and key order", func(t *testing.T) {
+		migrated, problems, err := MigrateBlueprintV1([]byte(migrateV1Yaml), "sample")
+		require.Nil(t, err)
+		assert.Equal(t, []MigrationProblem{
+			{Line: 28, Message: "dependsOnTrue is ignored by xl/v1 when dependsOnFalse is set, it is not migrated"},
+		}, problems)
+		assert.Equal(t, `# sample v1 blueprint
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Sample # the name shown in the list
+  description: Sample project
+spec:
+  parameters:
+    # the application name
+    - name: AppName
+      type: Input
+      prompt: Application name?
+      validate: !expr "regex('[a-z\\\\-]+', AppName)"
+    - name: UseK8s
+      type: Confirm
+      prompt: Use Kubernetes?
+      default: !expr "k8sConfig('IsAvailable')"
+    - name: Password
+      type: SecretInput
+      prompt: Password?
+      promptIf: !expr "!UseK8s"
+      replaceAsIs: true
+      revealOnSummary: true
+    - name: Url
+      type: Input
+      prompt: Server URL?
+      default: !expr "k8sConfig('ClusterServer', 'my-context')"
+      promptIf: !expr "!(k8sConfig('IsAvailable'))"
+  files:
+    - path: app.yaml.tmpl
+      writeIf: !expr "UseK8s"
+    - path: other.yaml
+      writeIf: !expr "!(UseK8s || AppName == 'x')"
+`, string(migrated))
+
+		doc, err := parseTemplateMetadataV2(&migrated, "sample", nil)
+		require.Nil(t, err)
+		assert.Equal(t, "regex('[a-z\\\\-]+', AppName)", doc.Variables[0].Validate.Value)
+	})
+
+	t.Run("should migrate the v1 test blueprint and report what could not be migrated", func(t *testing.T) {
+		content, err := ioutil.ReadFile(GetTestTemplateDir("valid-no-prompt-v1") + "/blueprint.yaml")
+		require.Nil(t, err)
+		_, problems, err := MigrateBlueprintV1(content, "valid-no-prompt-v1")
+		require.Nil(t, err)
+		assert.Equal(t, []MigrationProblem{
+			{Line: 62, Message: "function [aws.regions(ecs)] has no expression function equivalent, rewrite it as an expression"},
+			{Line: 63, Message: "function [aws.regions(ecs)[0]] has no expression function equivalent, rewrite it as an expression"},
+			{Line: 0, Message: "the migrated blueprint definition is not valid yet: unknown tag !fn aws.regions(ecs)[0], supported tags are [!expr]"},
+		}, problems)
+	})
+
+	t.Run("should report dependsOn values which are not parameter names", func(t *testing.T) {
+		_, problems, err := MigrateBlueprintV1([]byte(`apiVersion: xl/v1
+kind: Blueprint
+metadata:
+  projectName: Sample
+spec:
+  files:
+  - path: app.yaml
+    dependsOnTrue: not a name
+`), "sample")
+		require.Nil(t, err)
+		require.Len(t, problems, 2)
+		assert.Equal(t, MigrationProblem{Line: 8, Message: "[not a name] is not a parameter name, rewrite it as an expression"}, problems[0])
+	})
+
+	t.Run("should fail on documents which are not v1", func(t *testing.T) {
+		_, _, err := MigrateBlueprintV1([]byte("apiVersion: xl/v2\nkind: Blueprint\n"), "sample")
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint definition is not an xl/v1 document", err.Error())
+	})
+}
@@ -41,6 +41,7 @@ type Variable struct {
 
 type VariableMeta struct {
 	PromptSkipped bool
+	FilePath      string // path given by the user for file types
 }
 
 // TemplateConfig holds the merged template file definitions with repository info
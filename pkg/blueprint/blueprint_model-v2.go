@@ -2,56 +2,56 @@ package blueprint
 
 // Blueprint YAML schema definition V2
 type BlueprintYamlV2 struct {
-	ApiVersion string `yaml:"apiVersion"`
-	Kind       string `yaml:"kind"`
-	Metadata   MetadataV2
-	Spec       SpecV2
+	ApiVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   MetadataV2 `yaml:"metadata"`
+	Spec       SpecV2     `yaml:"spec"`
 }
 
 type MetadataV2 struct {
 	Name                    string `yaml:"name"`
-	Description             string `yaml:"description"`
-	Author                  string `yaml:"author"`
-	Version                 string `yaml:"version"`
-	Instructions            string `yaml:"instructions"`
-	SuppressXebiaLabsFolder bool   `yaml:"suppressXebiaLabsFolder"`
+	Description             string `yaml:"description,omitempty"`
+	Author                  string `yaml:"author,omitempty"`
+	Version                 string `yaml:"version,omitempty"`
+	Instructions            string `yaml:"instructions,omitempty"`
+	SuppressXebiaLabsFolder bool   `yaml:"suppressXebiaLabsFolder,omitempty"`
 }
 
 type SpecV2 struct {
-	Parameters    []ParameterV2
-	Files         []FileV2
-	IncludeBefore []IncludedBlueprintV2 `yaml:"includeBefore"`
-	IncludeAfter  []IncludedBlueprintV2 `yaml:"includeAfter"`
+	Parameters    []ParameterV2         `yaml:"parameters,omitempty"`
+	Files         []FileV2              `yaml:"files,omitempty"`
+	IncludeBefore []IncludedBlueprintV2 `yaml:"includeBefore,omitempty"`
+	IncludeAfter  []IncludedBlueprintV2 `yaml:"includeAfter,omitempty"`
 }
 
 type ParameterV2 struct {
 	Name            interface{}   `yaml:"name"`
-	Type            interface{}   `yaml:"type"`
-	Default         interface{}   `yaml:"default"`
-	Value           interface{}   `yaml:"value"`
-	PromptIf        interface{}   `yaml:"promptIf"`
-	Options         []interface{} `yaml:"options"`
-	SaveInXlvals    interface{}   `yaml:"saveInXlvals"`
-	ReplaceAsIs     interface{}   `yaml:"replaceAsIs"`
-	RevealOnSummary interface{}   `yaml:"revealOnSummary"`
-	Validate        interface{}   `yaml:"validate"`
-	Prompt          interface{}   `yaml:"prompt"`
-	Description     interface{}   `yaml:"description"`
-	Label           interface{}   `yaml:"label"`
-	IgnoreIfSkipped interface{}   `yaml:"ignoreIfSkipped"`
-	OverrideDefault interface{}   `yaml:"overrideDefault"`
-	AllowEmpty      interface{}   `yaml:"allowEmpty"`
+	Type            interface{}   `yaml:"type,omitempty"`
+	Default         interface{}   `yaml:"default,omitempty"`
+	Value           interface{}   `yaml:"value,omitempty"`
+	PromptIf        interface{}   `yaml:"promptIf,omitempty"`
+	Options         []interface{} `yaml:"options,omitempty"`
+	SaveInXlvals    interface{}   `yaml:"saveInXlvals,omitempty"`
+	ReplaceAsIs     interface{}   `yaml:"replaceAsIs,omitempty"`
+	RevealOnSummary interface{}   `yaml:"revealOnSummary,omitempty"`
+	Validate        interface{}   `yaml:"validate,omitempty"`
+	Prompt          interface{}   `yaml:"prompt,omitempty"`
+	Description     interface{}   `yaml:"description,omitempty"`
+	Label           interface{}   `yaml:"label,omitempty"`
+	IgnoreIfSkipped interface{}   `yaml:"ignoreIfSkipped,omitempty"`
+	OverrideDefault interface{}   `yaml:"overrideDefault,omitempty"`
+	AllowEmpty      interface{}   `yaml:"allowEmpty,omitempty"`
 }
 
 type FileV2 struct {
 	Path     interface{} `yaml:"path"`
-	WriteIf  interface{} `yaml:"writeIf"`
-	RenameTo interface{} `yaml:"renameTo"`
+	WriteIf  interface{} `yaml:"writeIf,omitempty"`
+	RenameTo interface{} `yaml:"renameTo,omitempty"`
 }
 
 type IncludedBlueprintV2 struct {
 	Blueprint          string        `yaml:"blueprint"`
-	IncludeIf          interface{}   `yaml:"includeIf"`
-	ParameterOverrides []ParameterV2 `yaml:"parameterOverrides"`
-	FileOverrides      []FileV2      `yaml:"fileOverrides"`
+	IncludeIf          interface{}   `yaml:"includeIf,omitempty"`
+	ParameterOverrides []ParameterV2 `yaml:"parameterOverrides,omitempty"`
+	FileOverrides      []FileV2      `yaml:"fileOverrides,omitempty"`
 }
@@ -0,0 +1,35 @@
+package blueprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingAnswer is a parameter that would have been asked, but has no answer in non-interactive mode
+type MissingAnswer struct {
+	Blueprint string `json:"blueprint" yaml:"blueprint"`
+	Name      string `json:"name" yaml:"name"`
+	Prompt    string `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	Type      string `json:"type" yaml:"type"`
+}
+
+// MissingAnswersError is returned in non-interactive mode when parameters have no answer,
+// it lists the parameters of all composed blueprints that have no answer
+type MissingAnswersError struct {
+	Answers []MissingAnswer
+}
+
+func (err *MissingAnswersError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "answers are missing for %d parameters in non-interactive mode:", len(err.Answers))
+	for _, answer := range err.Answers {
+		fmt.Fprintf(&sb, "\n  - %s (%s) in blueprint %s", answer.Name, answer.Type, answer.Blueprint)
+		if answer.Prompt != "" {
+			fmt.Fprintf(&sb, ": %s", answer.Prompt)
+		}
+	}
+	return sb.String()
+}
+
+// errNoBlueprintInNonInteractiveMode is returned when the blueprint to use would have been asked
+var errNoBlueprintInNonInteractiveMode = fmt.Errorf("the blueprint to use must be given in non-interactive mode")
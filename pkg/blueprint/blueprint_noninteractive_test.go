@@ -0,0 +1,141 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nonInteractiveParentYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Parent
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+  - name: Region
+    type: Select
+    prompt: Which region?
+    options:
+    - eu-west-1
+    - us-east-1
+    default: eu-west-1
+  - name: Public
+    type: Confirm
+    prompt: Expose the application?
+  files:
+  - path: app.yaml.tmpl
+  includeAfter:
+  - blueprint: child
+`
+
+const nonInteractiveChildYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Child
+spec:
+  parameters:
+  - name: Password
+    type: SecretInput
+    prompt: Database password?
+  - name: Port
+    type: Input
+    prompt: Database port?
+    promptIf: !expr "Public"
+`
+
+func TestInstantiateBlueprint_NonInteractive(t *testing.T) {
+	SkipFinalPrompt = false
+	defer func() { SkipFinalPrompt = true }()
+	repoDir, err := ioutil.TempDir("", "noninteractiverepo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"parent/blueprint.yaml": nonInteractiveParentYaml,
+		"parent/app.yaml.tmpl":  "name: {{.AppName}}\nregion: {{.Region}}\n",
+		"child/blueprint.yaml":  nonInteractiveChildYaml,
+	})
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+
+	t.Run("should report all missing answers of all composed blueprints at once", func(t *testing.T) {
+		fs := NewMemoryFS()
+		_, _, err := InstantiateBlueprint(
+			BlueprintParams{TemplatePath: "parent", AnswersMap: map[string]string{"Public": "true"}, NonInteractive: true},
+			blueprintContext,
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: fs},
+			nil,
+		)
+		require.NotNil(t, err)
+		missingAnswersErr, ok := err.(*MissingAnswersError)
+		require.True(t, ok)
+		assert.Equal(t, []MissingAnswer{
+			{Blueprint: "parent", Name: "AppName", Prompt: "Application name?", Type: TypeInput},
+			{Blueprint: "parent", Name: "Region", Prompt: "Which region?", Type: TypeSelect},
+			{Blueprint: "child", Name: "Password", Prompt: "Database password?", Type: TypeSecret},
+			{Blueprint: "child", Name: "Port", Prompt: "Database port?", Type: TypeInput},
+		}, missingAnswersErr.Answers)
+		assert.Equal(t, "answers are missing for 4 parameters in non-interactive mode:\n"+
+			"  - AppName (Input) in blueprint parent: Application name?\n"+
+			"  - Region (Select) in blueprint parent: Which region?\n"+
+			"  - Password (SecretInput) in blueprint child: Database password?\n"+
+			"  - Port (Input) in blueprint child: Database port?", err.Error())
+		assert.Empty(t, fs.Files())
+	})
+
+	t.Run("should generate without confirmation when all parameters are answered", func(t *testing.T) {
+		fs := NewMemoryFS()
+		_, _, err := InstantiateBlueprint(
+			BlueprintParams{
+				TemplatePath:       "parent",
+				AnswersMap:         map[string]string{"AppName": "my-app", "Public": "false", "Password": "secret"},
+				UseDefaultsAsValue: true,
+				NonInteractive:     true,
+			},
+			blueprintContext,
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: fs},
+			nil,
+		)
+		require.Nil(t, err)
+		content, err := fs.ReadFile("app.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "name: my-app\nregion: eu-west-1", string(content))
+	})
+
+	t.Run("should fail when the blueprint is not given", func(t *testing.T) {
+		_, _, err := InstantiateBlueprint(BlueprintParams{NonInteractive: true}, blueprintContext, &GeneratedBlueprint{FS: NewMemoryFS()}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, "the blueprint to use must be given in non-interactive mode", err.Error())
+	})
+
+	t.Run("should fail on existing files instead of prompting", func(t *testing.T) {
+		outputRoot, err := ioutil.TempDir("", "noninteractiveoutput")
+		require.Nil(t, err)
+		defer os.RemoveAll(outputRoot)
+		require.Nil(t, ioutil.WriteFile(filepath.Join(outputRoot, "app.yaml"), []byte("existing"), 0640))
+
+		_, _, err = InstantiateBlueprint(
+			BlueprintParams{
+				TemplatePath:   "parent",
+				AnswersMap:     map[string]string{"AppName": "my-app", "Region": "us-east-1", "Public": "false", "Password": "secret"},
+				NonInteractive: true,
+				OutputRoot:     outputRoot,
+				OnConflict:     OnConflictPrompt,
+			},
+			blueprintContext,
+			&GeneratedBlueprint{OutputDir: "xebialabs"},
+			nil,
+		)
+		require.NotNil(t, err)
+		assert.Equal(t, "output file "+filepath.Join(outputRoot, "app.yaml")+" already exists", err.Error())
+		assert.Equal(t, "existing", GetFileContent(filepath.Join(outputRoot, "app.yaml")))
+	})
+}
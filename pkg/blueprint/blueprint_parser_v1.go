@@ -13,23 +13,24 @@ import (
 
 // parse blueprint definition doc
 func parseTemplateMetadataV1(ymlContent *[]byte, templatePath string, blueprintRepository *BlueprintContext) (*BlueprintConfig, error) {
+	blueprintConfig, errs := parseTemplateMetadataV1Errors(ymlContent)
+	return blueprintConfig, firstError(errs)
+}
+
+// parse blueprint definition doc, going on after a parameter or file fails so that all problems are returned
+func parseTemplateMetadataV1Errors(ymlContent *[]byte) (*BlueprintConfig, []error) {
 	decoder := yaml.NewDecoder(bytes.NewReader(*ymlContent))
 	decoder.SetStrict(true)
 	yamlDoc := BlueprintYamlV1{}
 	err := decoder.Decode(&yamlDoc)
 	if err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 
 	// parse & validate
-	variables, err := yamlDoc.parseParameters()
-	if err != nil {
-		return nil, err
-	}
-	templateConfigs, err := yamlDoc.parseFiles()
-	if err != nil {
-		return nil, err
-	}
+	variables, errs := yamlDoc.parseParameters()
+	templateConfigs, fileErrs := yamlDoc.parseFiles()
+	errs = append(errs, fileErrs...)
 	blueprintConfig := BlueprintConfig{
 		ApiVersion:      yamlDoc.ApiVersion,
 		Kind:            yamlDoc.Kind,
@@ -37,8 +38,7 @@ func parseTemplateMetadataV1(ymlContent *[]byte, templatePath string, blueprintR
 		TemplateConfigs: templateConfigs,
 		Variables:       variables,
 	}
-	err = blueprintConfig.validate()
-	return &blueprintConfig, err
+	return &blueprintConfig, append(errs, blueprintConfig.validationErrors()...)
 }
 
 func (yamlDoc *BlueprintYamlV1) parseToMetadata() Metadata {
@@ -51,8 +51,8 @@ func (yamlDoc *BlueprintYamlV1) parseToMetadata() Metadata {
 	}
 }
 
-// parse doc parameters into list of variables
-func (yamlDoc *BlueprintYamlV1) parseParameters() ([]Variable, error) {
+// parse doc parameters into list of variables, parameters which fail are left out and their errors returned
+func (yamlDoc *BlueprintYamlV1) parseParameters() ([]Variable, []error) {
 	parameters := []ParameterV1{}
 	variables := []Variable{}
 	if yamlDoc.Spec.Parameters != nil {
@@ -61,18 +61,20 @@ func (yamlDoc *BlueprintYamlV1) parseParameters() ([]Variable, error) {
 		// for backward compatibility with v8.5
 		parameters = yamlDoc.Parameters
 	}
+	var errs []error
 	for _, m := range parameters {
 		parsedVar, err := parseParameterV1(&m)
 		if err != nil {
-			return variables, err
+			errs = append(errs, err)
+			continue
 		}
 		variables = append(variables, parsedVar)
 	}
-	return variables, nil
+	return variables, errs
 }
 
-// parse doc files into list of TemplateConfig
-func (blueprintDoc *BlueprintYamlV1) parseFiles() ([]TemplateConfig, error) {
+// parse doc files into list of TemplateConfig, files which fail are left out and their errors returned
+func (blueprintDoc *BlueprintYamlV1) parseFiles() ([]TemplateConfig, []error) {
 	files := []FileV1{}
 	templateConfigs := []TemplateConfig{}
 	if blueprintDoc.Spec.Files != nil {
@@ -81,14 +83,16 @@ func (blueprintDoc *BlueprintYamlV1) parseFiles() ([]TemplateConfig, error) {
 		// for backward compatibility with v8.5
 		files = blueprintDoc.Files
 	}
+	var errs []error
 	for _, m := range files {
 		templateConfig, err := parseFileV1(&m)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
 		templateConfigs = append(templateConfigs, templateConfig)
 	}
-	return templateConfigs, nil
+	return templateConfigs, errs
 }
 
 func parseParameterV1(m *ParameterV1) (Variable, error) {
@@ -617,7 +617,8 @@ func TestBlueprintYaml_parseParametersV1(t *testing.T) {
 				Parameters: tt.params,
 				Spec:       tt.spec,
 			}
-			got, err := blueprintDoc.parseParameters()
+			got, errs := blueprintDoc.parseParameters()
+			err := firstError(errs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BlueprintYaml.parseParameters() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -685,7 +686,8 @@ func TestBlueprintYaml_parseFilesV1(t *testing.T) {
 				Metadata:   tt.fields.Metadata,
 				Spec:       tt.fields.Spec,
 			}
-			tconfigs, err := blueprintDoc.parseFiles()
+			tconfigs, errs := blueprintDoc.parseFiles()
+			err := firstError(errs)
 			if tt.wantErr == nil || err == nil {
 				assert.Equal(t, tt.wantErr, err)
 			} else {
@@ -13,27 +13,25 @@ import (
 
 // parse blueprint definition doc
 func parseTemplateMetadataV2(ymlContent *[]byte, templatePath string, blueprintRepository *BlueprintContext) (*BlueprintConfig, error) {
+	blueprintConfig, errs := parseTemplateMetadataV2Errors(ymlContent)
+	return blueprintConfig, firstError(errs)
+}
+
+// parse blueprint definition doc, going on after a parameter, file or include fails so that all problems are returned
+func parseTemplateMetadataV2Errors(ymlContent *[]byte) (*BlueprintConfig, []error) {
 	decoder := yaml.NewDecoder(bytes.NewReader(*ymlContent))
 	decoder.SetStrict(true)
 	yamlDoc := BlueprintYamlV2{}
 	err := decoder.Decode(&yamlDoc)
 	if err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 
 	// parse & validate
-	variables, err := yamlDoc.parseParameters()
-	if err != nil {
-		return nil, err
-	}
-	templateConfigs, err := yamlDoc.parseFiles()
-	if err != nil {
-		return nil, err
-	}
-	included, err := yamlDoc.parseIncludes()
-	if err != nil {
-		return nil, err
-	}
+	variables, errs := yamlDoc.parseParameters()
+	templateConfigs, fileErrs := yamlDoc.parseFiles()
+	included, includeErrs := yamlDoc.parseIncludes()
+	errs = append(append(errs, fileErrs...), includeErrs...)
 	blueprintConfig := BlueprintConfig{
 		ApiVersion:      yamlDoc.ApiVersion,
 		Kind:            yamlDoc.Kind,
@@ -42,8 +40,7 @@ func parseTemplateMetadataV2(ymlContent *[]byte, templatePath string, blueprintR
 		TemplateConfigs: templateConfigs,
 		Variables:       variables,
 	}
-	err = blueprintConfig.validate()
-	return &blueprintConfig, err
+	return &blueprintConfig, append(errs, blueprintConfig.validationErrors()...)
 }
 
 func (yamlDoc *BlueprintYamlV2) parseToMetadata() Metadata {
@@ -57,43 +54,45 @@ func (yamlDoc *BlueprintYamlV2) parseToMetadata() Metadata {
 	}
 }
 
-// parse doc parameters into list of variables
-func (yamlDoc *BlueprintYamlV2) parseParameters() ([]Variable, error) {
-	parameters := []ParameterV2{}
+// parse doc parameters into list of variables, parameters which fail are left out and their errors returned
+func (yamlDoc *BlueprintYamlV2) parseParameters() ([]Variable, []error) {
+	var errs []error
 	variables := []Variable{}
-	parameters = yamlDoc.Spec.Parameters
-	for _, m := range parameters {
+	for _, m := range yamlDoc.Spec.Parameters {
 		parsedVar, err := parseParameterV2(&m)
 		if err != nil {
-			return variables, err
+			errs = append(errs, err)
+			continue
 		}
 		variables = append(variables, parsedVar)
 	}
-	return variables, nil
+	return variables, errs
 }
 
-// parse doc files into list of TemplateConfig
-func (yamlDoc *BlueprintYamlV2) parseFiles() ([]TemplateConfig, error) {
-	files := []FileV2{}
+// parse doc files into list of TemplateConfig, files which fail are left out and their errors returned
+func (yamlDoc *BlueprintYamlV2) parseFiles() ([]TemplateConfig, []error) {
+	var errs []error
 	templateConfigs := []TemplateConfig{}
-	files = yamlDoc.Spec.Files
-	for _, m := range files {
+	for _, m := range yamlDoc.Spec.Files {
 		templateConfig, err := parseFileV2(&m)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
 		templateConfigs = append(templateConfigs, templateConfig)
 	}
-	return templateConfigs, nil
+	return templateConfigs, errs
 }
 
-// parse doc files into list of TemplateConfig
-func (yamlDoc *BlueprintYamlV2) parseIncludes() ([]IncludedBlueprintProcessed, error) {
+// parse doc includes into list of IncludedBlueprintProcessed, includes which fail are left out and their errors returned
+func (yamlDoc *BlueprintYamlV2) parseIncludes() ([]IncludedBlueprintProcessed, []error) {
+	var errs []error
 	processedIncludes := []IncludedBlueprintProcessed{}
 	for _, m := range yamlDoc.Spec.IncludeBefore {
 		include, err := parseIncludeV2(&m)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
 		include.Stage = "before"
 		processedIncludes = append(processedIncludes, include)
@@ -101,12 +100,13 @@ func (yamlDoc *BlueprintYamlV2) parseIncludes() ([]IncludedBlueprintProcessed, e
 	for _, m := range yamlDoc.Spec.IncludeAfter {
 		include, err := parseIncludeV2(&m)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
 		include.Stage = "after"
 		processedIncludes = append(processedIncludes, include)
 	}
-	return processedIncludes, nil
+	return processedIncludes, errs
 }
 
 func parseParameterV2(m *ParameterV2) (Variable, error) {
@@ -302,18 +302,22 @@ func setVariableField(field *reflect.Value, val interface{}, varField VarField)
 
 // ParseDependsOnValue parse the functions and expressions set on the dependsOn fields
 func ParseDependsOnValue(varField VarField, parameters map[string]interface{}) (bool, error) {
+	return parseDependsOnValue(cliLogger{}, varField, parameters)
+}
+
+func parseDependsOnValue(logger Logger, varField VarField, parameters map[string]interface{}) (bool, error) {
 	tagVal := varField.Tag
 	fieldVal := varField.Value
 	switch tagVal {
 	case tagFnV1:
-		values, err := ProcessCustomFunction(fieldVal)
+		values, err := processCustomFunction(logger, fieldVal)
 		if err != nil {
 			return false, err
 		}
 		if len(values) == 0 {
 			return false, fmt.Errorf("function [%s] results is empty", fieldVal)
 		}
-		util.Verbose("[fn] Processed value of function [%s] is: %s\n", fieldVal, values[0])
+		logger.Verbose("[fn] Processed value of function [%s] is: %s\n", fieldVal, values[0])
 
 		dependsOnVal, err := strconv.ParseBool(values[0])
 		if err != nil {
@@ -439,6 +439,40 @@ func TestParseTemplateMetadataV2(t *testing.T) {
 	})
 }
 
+func TestParseTemplateMetadataV2Errors(t *testing.T) {
+	metadata := []byte(`
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Errors
+spec:
+  parameters:
+  - name: NoPrompt
+    type: Input
+  - name: Valid
+    type: Input
+    prompt: Valid?
+  - name: NoType
+    prompt: No type?
+  files:
+  - path: app.yaml.tmpl
+  - writeIf: Valid
+`)
+
+	doc, errs := parseTemplateMetadataV2Errors(&metadata)
+	require.NotNil(t, doc)
+	require.Len(t, errs, 3)
+	assert.Equal(t, "parameter NoPrompt must have a 'prompt' field", errs[0].Error())
+	assert.Equal(t, "parameter NoType must have a 'type' field", errs[1].Error())
+	assert.Len(t, doc.Variables, 1)
+	assert.Len(t, doc.TemplateConfigs, 2)
+
+	// generation stops at the first of the same problems
+	_, err := parseTemplateMetadataV2(&metadata, "errors", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, errs[0], err)
+}
+
 func TestBlueprintYaml_parseParameters(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -565,7 +599,8 @@ func TestBlueprintYaml_parseParameters(t *testing.T) {
 				Kind:       "",
 				Spec:       tt.spec,
 			}
-			got, err := blueprintDoc.parseParameters()
+			got, errs := blueprintDoc.parseParameters()
+			err := firstError(errs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("BlueprintYaml.parseParameters() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -633,7 +668,8 @@ func TestBlueprintYaml_parseFiles(t *testing.T) {
 				Metadata:   tt.fields.Metadata,
 				Spec:       tt.fields.Spec,
 			}
-			tconfigs, err := blueprintDoc.parseFiles()
+			tconfigs, errs := blueprintDoc.parseFiles()
+			err := firstError(errs)
 			if tt.wantErr == nil || err == nil {
 				assert.Equal(t, tt.wantErr, err)
 			} else {
@@ -765,7 +801,8 @@ func TestBlueprintYaml_parseIncludes(t *testing.T) {
 				Metadata:   tt.fields.Metadata,
 				Spec:       tt.fields.Spec,
 			}
-			tconfigs, err := blueprintDoc.parseIncludes()
+			tconfigs, errs := blueprintDoc.parseIncludes()
+			err := firstError(errs)
 			if tt.wantErr == nil || err == nil {
 				assert.Equal(t, tt.wantErr, err)
 			} else {
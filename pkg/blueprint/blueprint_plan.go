@@ -0,0 +1,246 @@
+package blueprint
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/thoas/go-funk"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Actions planned for the blueprint output files
+const (
+	PlanActionCreate    = "create"
+	PlanActionOverwrite = "overwrite"
+	PlanActionRename    = "rename"
+	PlanActionMerge     = "merge"
+	PlanActionSkip      = "skip"
+)
+
+// PlannedFile is a blueprint output file along with the action that is taken for it and why
+type PlannedFile struct {
+	Path    string `json:"path" yaml:"path"`
+	Source  string `json:"source,omitempty" yaml:"source,omitempty"`
+	Action  string `json:"action" yaml:"action"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Content string `json:"-" yaml:"-"`
+}
+
+// planBlueprintFiles renders all blueprint output files in memory, without writing anything to disk,
+// and decides for each of them whether it would be created, overwritten, renamed or skipped
+func planBlueprintFiles(
+	blueprintContext *BlueprintContext,
+	blueprintDoc *BlueprintConfig,
+	preparedData *PreparedData,
+	generatedBlueprint *GeneratedBlueprint,
+	overrideFns ExpressionOverrideFn,
+) ([]PlannedFile, error) {
+	var plannedFiles []PlannedFile
+	plannedPaths := make(map[string]bool)
+	addPlannedFile := func(plannedFile PlannedFile, renamedFrom string) {
+		if plannedFile.Action != PlanActionSkip {
+			var reasons []string
+			if renamedFrom != "" {
+				plannedFile.Action = PlanActionRename
+				reasons = append(reasons, fmt.Sprintf("renamed from %s by composed blueprint", renamedFrom))
+			}
+			if plannedPaths[plannedFile.Path] {
+				reasons = append(reasons, "overwrites a file generated earlier by a composed blueprint")
+				if renamedFrom == "" {
+					plannedFile.Action = PlanActionOverwrite
+				}
+			} else if plannedFile.Action != PlanActionMerge && generatedBlueprint.pathExists(generatedBlueprint.outputPath(plannedFile.Path), false) {
+				reasons = append(reasons, "overwrites existing file")
+				if renamedFrom == "" {
+					plannedFile.Action = PlanActionOverwrite
+				}
+			}
+			if len(reasons) > 0 {
+				plannedFile.Reason = strings.Join(reasons, ", ")
+			}
+			plannedPaths[plannedFile.Path] = true
+		}
+		plannedFiles = append(plannedFiles, plannedFile)
+	}
+
+	createXebiaLabsFolder := !blueprintDoc.Metadata.SuppressXebiaLabsFolder
+
+	// prepared data goes to values & secrets files, which are merged into existing ones
+	if createXebiaLabsFolder || len(preparedData.Values) != 0 {
+		plannedFile, err := planConfigFile(generatedBlueprint, valuesFile, valuesFileHeader, preparedData.Values, "non-secret parameter values")
+		if err != nil {
+			return nil, err
+		}
+		addPlannedFile(*plannedFile, "")
+	}
+
+	if createXebiaLabsFolder || len(preparedData.Secrets) != 0 {
+		plannedFile, err := planConfigFile(generatedBlueprint, secretsFile, secretsFileHeader, preparedData.Secrets, "secret parameter values")
+		if err != nil {
+			return nil, err
+		}
+		addPlannedFile(*plannedFile, "")
+
+		plannedFile = &PlannedFile{
+			Path:    filepath.Join(generatedBlueprint.OutputDir, gitignoreFile),
+			Action:  PlanActionCreate,
+			Reason:  "excludes secrets from GIT",
+			Content: secretsFile,
+		}
+		existingContent, err := readExistingOutputFile(generatedBlueprint, plannedFile.Path)
+		if err != nil {
+			return nil, err
+		}
+		if existingContent != nil {
+			plannedFile.Action = PlanActionMerge
+			plannedFile.Content = mergeGitignore(*existingContent, secretsFile)
+			if plannedFile.Content == *existingContent {
+				plannedFile.Reason = "existing file already excludes secrets from GIT"
+			} else {
+				plannedFile.Reason = "appends missing lines to existing file"
+			}
+		}
+		addPlannedFile(*plannedFile, "")
+	}
+
+	// render each template file found
+	for _, config := range blueprintDoc.TemplateConfigs {
+		writeIf := describeVarField(config.DependsOn)
+		config.processExpression(generatedBlueprint.log(), preparedData.TemplateData, overrideFns)
+		skipFile, err := shouldSkipFile(generatedBlueprint.log(), config, preparedData.TemplateData)
+		if err != nil {
+			return nil, err
+		}
+
+		finalFileName := config.Path
+		if config.RenameTo.Value != "" {
+			finalFileName = config.RenameTo.Value
+		}
+		if strings.HasSuffix(config.Path, templateExtension) {
+			finalFileName = strings.Replace(finalFileName, templateExtension, "", 1)
+		}
+
+		if skipFile {
+			generatedBlueprint.log().Verbose("[file] skipping file [%s] since it has writeIf value set or is skipped by composed blueprint\n", config.Path)
+			addPlannedFile(PlannedFile{
+				Path:   finalFileName,
+				Source: config.FullPath,
+				Action: PlanActionSkip,
+				Reason: fmt.Sprintf("writeIf [%s] evaluated to false", writeIf),
+			}, "")
+			continue
+		}
+
+		isTemplate := strings.HasSuffix(config.Path, templateExtension)
+		ignoredDir := filepath.Base(filepath.Dir(config.FullPath))
+		if !isTemplate && funk.ContainsString(ignoredPaths, ignoredDir) {
+			// skip files under ignored directories
+			generatedBlueprint.log().Verbose("[file] Skipping file %s because path is under ignored list\n", config.FullPath)
+			addPlannedFile(PlannedFile{
+				Path:   finalFileName,
+				Source: config.FullPath,
+				Action: PlanActionSkip,
+				Reason: fmt.Sprintf("file is under ignored directory %s", ignoredDir),
+			}, "")
+			continue
+		}
+
+		// read template contents
+		generatedBlueprint.log().Verbose("[file] Fetching template file %s from %s\n", config.Path, config.FullPath)
+		templateContent, err := blueprintContext.fetchFileContents(config.FullPath, isTemplate)
+		if err != nil {
+			return nil, err
+		}
+		content := string(*templateContent)
+		renamedFrom := ""
+		if config.RenameTo.Value != "" {
+			renamedFrom = config.Path
+			generatedBlueprint.log().Verbose("[file] Renaming template file %s to %s as it is overridden by composed blueprint\n", config.Path, config.RenameTo.Value)
+		}
+
+		// process the template file (filter based on extension)
+		if isTemplate {
+			generatedBlueprint.log().Verbose("[file] Processing template file %s\n", config.FullPath)
+
+			// read & process the template
+			tmpl, err := template.New(config.Path).Funcs(getFuncMaps()).Parse(content)
+			if err != nil {
+				return nil, err
+			}
+			processedTmpl := &strings.Builder{}
+			err = tmpl.Execute(processedTmpl, preparedData.TemplateData)
+			if err != nil {
+				return nil, err
+			}
+			content = strings.TrimSpace(processedTmpl.String())
+		} else {
+			// non-template files are copied as-it-is
+			generatedBlueprint.log().Verbose("[file] Copying file %s\n", config.FullPath)
+		}
+
+		addPlannedFile(PlannedFile{
+			Path:    finalFileName,
+			Source:  config.FullPath,
+			Action:  PlanActionCreate,
+			Content: content,
+		}, renamedFrom)
+	}
+	return plannedFiles, nil
+}
+
+// planConfigFile renders the config as an xlvals file, or merges it into the existing one
+func planConfigFile(generatedBlueprint *GeneratedBlueprint, fileName string, header string, config map[string]interface{}, reason string) (*PlannedFile, error) {
+	plannedFile := &PlannedFile{
+		Path:   filepath.Join(generatedBlueprint.OutputDir, fileName),
+		Action: PlanActionCreate,
+		Reason: reason,
+	}
+	existingContent, err := readExistingOutputFile(generatedBlueprint, plannedFile.Path)
+	if err != nil {
+		return nil, err
+	}
+	if existingContent == nil {
+		plannedFile.Content, err = renderConfig(header, config)
+		return plannedFile, err
+	}
+
+	content, changedKeys, err := mergeConfig(*existingContent, config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge into existing file %s: %s", plannedFile.Path, err)
+	}
+	plannedFile.Action = PlanActionMerge
+	plannedFile.Content = content
+	plannedFile.Reason = reason + " merged into existing file"
+	if len(changedKeys) > 0 {
+		plannedFile.Reason += fmt.Sprintf(", changes value of %s", strings.Join(changedKeys, ", "))
+	}
+	return plannedFile, nil
+}
+
+// readExistingOutputFile returns the contents of the output file on disk, or nil if it does not exist
+func readExistingOutputFile(generatedBlueprint *GeneratedBlueprint, fileName string) (*string, error) {
+	outputFile := generatedBlueprint.outputPath(fileName)
+	if !generatedBlueprint.pathExists(outputFile, false) {
+		return nil, nil
+	}
+	content, err := generatedBlueprint.fs().ReadFile(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	existingContent := string(content)
+	return &existingContent, nil
+}
+
+// writeBlueprintPlan writes the planned files as a table
+func writeBlueprintPlan(w io.Writer, plannedFiles []PlannedFile) error {
+	return util.WriteFormatted(w, util.OutputFormatTable, plannedFiles, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "ACTION\tPATH\tSOURCE\tREASON")
+		for _, plannedFile := range plannedFiles {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", plannedFile.Action, plannedFile.Path, plannedFile.Source, plannedFile.Reason)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package blueprint
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+func TestInstantiateBlueprint_DryRun(t *testing.T) {
+	SkipFinalPrompt = true
+
+	t.Run("should not write any file in dry-run mode", func(t *testing.T) {
+		gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+		defer gb.Cleanup()
+		data, doc, err := InstantiateBlueprint(
+			BlueprintParams{
+				TemplatePath:       "composed",
+				UseDefaultsAsValue: true,
+				DryRun:             true,
+			},
+			getLocalTestBlueprintContext(t),
+			gb, nil,
+		)
+		require.Nil(t, err)
+		require.NotNil(t, data)
+		require.NotNil(t, doc)
+
+		assert.Empty(t, gb.GeneratedFiles)
+		assert.False(t, util.PathExists("xebialabs", true))
+		assert.False(t, util.PathExists("xld-infrastructure.yml", false))
+		assert.False(t, util.PathExists("xlr-pipeline-4.yml", false))
+	})
+}
+
+func TestPlanBlueprintFiles(t *testing.T) {
+	SkipFinalPrompt = true
+	blueprintContext := getLocalTestBlueprintContext(t)
+	gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+	data, doc, err := InstantiateBlueprint(
+		BlueprintParams{
+			TemplatePath:       "composed",
+			UseDefaultsAsValue: true,
+			DryRun:             true,
+		},
+		blueprintContext,
+		gb, nil,
+	)
+	require.Nil(t, err)
+
+	t.Run("should plan created, renamed and skipped files", func(t *testing.T) {
+		plannedFiles, err := planBlueprintFiles(blueprintContext, doc, data, gb, nil)
+		require.Nil(t, err)
+
+		actions := make(map[string]PlannedFile)
+		for _, plannedFile := range plannedFiles {
+			actions[plannedFile.Path] = plannedFile
+		}
+		assert.Equal(t, PlanActionCreate, actions[path.Join("xebialabs", valuesFile)].Action)
+		assert.Contains(t, actions[path.Join("xebialabs", valuesFile)].Content, "TestCompose = hello")
+		assert.Equal(t, PlanActionCreate, actions[path.Join("xebialabs", gitignoreFile)].Action)
+		assert.Equal(t, PlannedFile{
+			Path:   "xld-environment.yml",
+			Source: "defaults-as-values/xld-environment.yml.tmpl",
+			Action: PlanActionSkip,
+			Reason: "writeIf [!expr false] evaluated to false",
+		}, actions["xld-environment.yml"])
+		assert.Equal(t, PlanActionRename, actions["xlr-pipeline-new.yml"].Action)
+		assert.Equal(t, "renamed from xlr-pipeline.yml by composed blueprint", actions["xlr-pipeline-new.yml"].Reason)
+		assert.Equal(t, "valid-no-prompt/xlr-pipeline.yml", actions["xlr-pipeline-new.yml"].Source)
+		assert.Equal(t, PlanActionCreate, actions["xlr-pipeline-4.yml"].Action)
+		assert.Equal(t, PlanActionOverwrite, actions["xld-infrastructure.yml"].Action)
+		assert.Equal(t, "overwrites a file generated earlier by a composed blueprint", actions["xld-infrastructure.yml"].Reason)
+		assert.Contains(t, actions["xld-infrastructure.yml"].Content, "- name: TestApp-ecs-vpc")
+	})
+
+	t.Run("should plan overwrite of existing files", func(t *testing.T) {
+		require.Nil(t, ioutil.WriteFile("xlr-pipeline-4.yml", []byte("existing"), 0640))
+		defer os.Remove("xlr-pipeline-4.yml")
+
+		plannedFiles, err := planBlueprintFiles(blueprintContext, doc, data, gb, nil)
+		require.Nil(t, err)
+		for _, plannedFile := range plannedFiles {
+			if plannedFile.Path == "xlr-pipeline-4.yml" {
+				assert.Equal(t, PlanActionOverwrite, plannedFile.Action)
+				assert.Equal(t, "overwrites existing file", plannedFile.Reason)
+			}
+		}
+	})
+
+	t.Run("should write plan as table", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := writeBlueprintPlan(out, []PlannedFile{
+			{Path: "a.yml", Source: "bp/a.yml.tmpl", Action: PlanActionCreate},
+			{Path: "b.yml", Source: "bp/b.yml", Action: PlanActionSkip, Reason: "writeIf [B] evaluated to false"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, "ACTION   PATH    SOURCE          REASON\ncreate   a.yml   bp/a.yml.tmpl   \nskip     b.yml   bp/b.yml        writeIf [B] evaluated to false\n", out.String())
+	})
+}
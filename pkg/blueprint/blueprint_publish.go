@@ -0,0 +1,236 @@
+package blueprint
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository/http"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository/local"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+var regExPublishVersion = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._-]*$`)
+
+// PublishOptions configures PublishBlueprints. The blueprints of the local repository in RepoDir are published
+// to OutputDir as version Version, directories named in IgnoredDirs and files named in IgnoredFiles are skipped
+type PublishOptions struct {
+	RepoDir      string
+	OutputDir    string
+	Version      string
+	IgnoredDirs  []string
+	IgnoredFiles []string
+}
+
+// PublishResult is the index written when publishing a repository with the paths of the index and archive files,
+// and the problems found while validating the blueprints
+type PublishResult struct {
+	Index       *repository.RepoIndex `json:"index" yaml:"index"`
+	IndexPath   string                `json:"indexPath" yaml:"indexPath"`
+	ArchivePath string                `json:"archivePath" yaml:"archivePath"`
+	Problems    []ValidationProblem   `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+// ArchiveFileName returns the name of the zip archive of a published repository version
+func ArchiveFileName(version string) string {
+	return fmt.Sprintf("blueprints-%s.zip", version)
+}
+
+// PublishBlueprints validates the blueprints of a local repository and publishes them to the output directory:
+// the blueprint files are copied next to an index.json with the metadata, file lists and SHA-256 digests of the
+// blueprints, so that the directory can be served as an HTTP repository, and the same files are written to a
+// versioned zip archive that can be used as a zip repository. Nothing is written when a blueprint has validation errors
+func PublishBlueprints(options PublishOptions) (*PublishResult, error) {
+	if !regExPublishVersion.MatchString(options.Version) {
+		return nil, fmt.Errorf("invalid version [%s], it must start with a letter or digit and contain only letters, digits, dots, dashes and underscores", options.Version)
+	}
+	if entries, err := ioutil.ReadDir(options.OutputDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("directory %s is not empty", options.OutputDir)
+	}
+	repoDir, err := filepath.Abs(options.RepoDir)
+	if err != nil {
+		return nil, err
+	}
+	outputDir, err := filepath.Abs(options.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(repoDir, outputDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("output directory %s must not be inside the repository directory %s", options.OutputDir, options.RepoDir)
+	}
+
+	var localRepo repository.BlueprintRepository
+	localRepo, err = local.NewLocalBlueprintRepository(map[string]string{
+		"type":          models.ProviderLocal,
+		"name":          "publish",
+		"path":          repoDir,
+		"ignored-dirs":  strings.Join(options.IgnoredDirs, ","),
+		"ignored-files": strings.Join(options.IgnoredFiles, ","),
+	})
+	if err != nil {
+		return nil, err
+	}
+	blueprintContext := &BlueprintContext{
+		ActiveRepo:   &localRepo,
+		DefinedRepos: []*repository.BlueprintRepository{&localRepo},
+	}
+
+	result := &PublishResult{}
+	if result.Problems, err = blueprintContext.ValidateBlueprints(); err != nil {
+		return nil, err
+	}
+	if HasValidationErrors(result.Problems) {
+		return result, fmt.Errorf("the repository has blueprints with validation errors, nothing was published")
+	}
+
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+	if len(blueprints) == 0 {
+		return nil, fmt.Errorf("no blueprints found in %s", options.RepoDir)
+	}
+	var blueprintPaths []string
+	for blueprintPath := range blueprints {
+		blueprintPaths = append(blueprintPaths, blueprintPath)
+	}
+	sort.Strings(blueprintPaths)
+
+	// contents of the published files by their slash separated path in the repository
+	contents := make(map[string][]byte)
+	result.Index = &repository.RepoIndex{IndexVersion: repository.RepoIndexVersion, Version: options.Version}
+	for _, blueprintPath := range blueprintPaths {
+		entry, err := blueprintContext.indexBlueprint(blueprints[blueprintPath], contents)
+		if err != nil {
+			return nil, fmt.Errorf("error while publishing blueprint %s: %s", blueprintPath, err.Error())
+		}
+		result.Index.Blueprints = append(result.Index.Blueprints, *entry)
+	}
+	index, err := json.MarshalIndent(result.Index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for filePath := range contents {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	contents[http.RepoIndexFileName] = index
+	paths = append(paths, http.RepoIndexFileName)
+
+	for _, filePath := range paths {
+		target := filepath.Join(outputDir, filepath.FromSlash(filePath))
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(target, contents[filePath], 0640); err != nil {
+			return nil, err
+		}
+	}
+	result.IndexPath = filepath.Join(options.OutputDir, http.RepoIndexFileName)
+	result.ArchivePath = filepath.Join(options.OutputDir, ArchiveFileName(options.Version))
+	if err := writePublishArchive(result.ArchivePath, paths, contents); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// indexBlueprint returns the index entry of a blueprint and adds the contents of its files to contents
+func (blueprintContext *BlueprintContext) indexBlueprint(blueprint *models.BlueprintRemote, contents map[string][]byte) (*repository.BlueprintIndexEntry, error) {
+	blueprintPath := filepath.ToSlash(blueprint.Path)
+	readFile := func(remoteFile models.RemoteFile) (repository.IndexedFile, error) {
+		content, err := blueprintContext.fetchFileContents(remoteFile.Path, false)
+		if err != nil {
+			return repository.IndexedFile{}, err
+		}
+		filePath := filepath.ToSlash(remoteFile.Path)
+		contents[filePath] = *content
+		return repository.NewIndexedFile(strings.TrimPrefix(filePath, blueprintPath+"/"), *content), nil
+	}
+
+	definitionFile, err := readFile(blueprint.DefinitionFile)
+	if err != nil {
+		return nil, err
+	}
+	definition := contents[path.Join(blueprintPath, definitionFile.Path)]
+	blueprintDoc, err := parseTemplateMetadata(&definition, blueprint.Path, blueprintContext)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &repository.BlueprintIndexEntry{
+		Path:           blueprintPath,
+		DefinitionFile: definitionFile,
+		Metadata: repository.BlueprintIndexMetadata{
+			Name:        blueprintDoc.Metadata.Name,
+			Description: blueprintDoc.Metadata.Description,
+			Author:      blueprintDoc.Metadata.Author,
+			Version:     blueprintDoc.Metadata.Version,
+		},
+		Files: []repository.IndexedFile{},
+	}
+	for _, remoteFile := range blueprint.Files {
+		file, err := readFile(remoteFile)
+		if err != nil {
+			return nil, err
+		}
+		entry.Files = append(entry.Files, file)
+	}
+	sort.Slice(entry.Files, func(i, j int) bool {
+		return entry.Files[i].Path < entry.Files[j].Path
+	})
+	return entry, nil
+}
+
+// writePublishArchive writes the files to a zip archive with the blueprint directories at its root, as expected by
+// the zip repository. Directory entries are written before the files, since extracting creates the parent directories
+// of a file with the mode of the file
+func writePublishArchive(archivePath string, paths []string, contents map[string][]byte) error {
+	archiveFile, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	defer archiveFile.Close()
+
+	writer := zip.NewWriter(archiveFile)
+	dirs := make(map[string]bool)
+	for _, filePath := range paths {
+		var parents []string
+		for dir := path.Dir(filePath); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+			parents = append([]string{dir}, parents...)
+		}
+		for _, dir := range parents {
+			header := &zip.FileHeader{Name: dir + "/"}
+			header.SetMode(os.ModeDir | 0755)
+			if _, err := writer.CreateHeader(header); err != nil {
+				return err
+			}
+		}
+
+		header := &zip.FileHeader{Name: filePath, Method: zip.Deflate}
+		header.SetMode(0644)
+		fileWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fileWriter.Write(contents[filePath]); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	util.Verbose("[publish] Archive %s written with %d files\n", archivePath, len(paths))
+	return archiveFile.Close()
+}
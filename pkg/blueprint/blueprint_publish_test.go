@@ -0,0 +1,160 @@
+package blueprint
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+)
+
+const publishedBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Published
+  description: A published blueprint
+  author: XebiaLabs
+  version: "1.2"
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+  files:
+  - path: app.yaml.tmpl
+  - path: config/notes.txt
+`
+
+func TestPublishBlueprints(t *testing.T) {
+	newRepo := func(t *testing.T, files map[string]string) (string, string) {
+		repoDir, err := ioutil.TempDir("", "publishrepo")
+		require.Nil(t, err)
+		t.Cleanup(func() { os.RemoveAll(repoDir) })
+		writeUpgradeTestRepo(t, repoDir, files)
+		outputDir, err := ioutil.TempDir("", "publishout")
+		require.Nil(t, err)
+		t.Cleanup(func() { os.RemoveAll(outputDir) })
+		return repoDir, outputDir
+	}
+
+	t.Run("should write the index, the blueprint files and the archive", func(t *testing.T) {
+		repoDir, outputDir := newRepo(t, map[string]string{
+			"aws/published/blueprint.yaml":   publishedBlueprintYaml,
+			"aws/published/app.yaml.tmpl":    "app: {{.AppName}}\n",
+			"aws/published/config/notes.txt": "notes\n",
+			"aws/published/.DS_Store":        "ignored",
+			"other/blueprint.yml":            "apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Other\nspec:\n  files:\n  - path: other.txt\n",
+			"other/other.txt":                "other\n",
+			".git/config":                    "ignored",
+		})
+
+		result, err := PublishBlueprints(PublishOptions{
+			RepoDir:      repoDir,
+			OutputDir:    outputDir,
+			Version:      "9.1.0",
+			IgnoredDirs:  []string{".git"},
+			IgnoredFiles: []string{".DS_Store"},
+		})
+		require.Nil(t, err)
+		assert.Empty(t, result.Problems)
+		assert.Equal(t, filepath.Join(outputDir, "index.json"), result.IndexPath)
+		assert.Equal(t, filepath.Join(outputDir, "blueprints-9.1.0.zip"), result.ArchivePath)
+
+		expected := &repository.RepoIndex{
+			IndexVersion: 2,
+			Version:      "9.1.0",
+			Blueprints: []repository.BlueprintIndexEntry{
+				{
+					Path:           "aws/published",
+					DefinitionFile: repository.NewIndexedFile("blueprint.yaml", []byte(publishedBlueprintYaml)),
+					Metadata: repository.BlueprintIndexMetadata{
+						Name:        "Published",
+						Description: "A published blueprint",
+						Author:      "XebiaLabs",
+						Version:     "1.2",
+					},
+					Files: []repository.IndexedFile{
+						repository.NewIndexedFile("app.yaml.tmpl", []byte("app: {{.AppName}}\n")),
+						repository.NewIndexedFile("config/notes.txt", []byte("notes\n")),
+					},
+				},
+				{
+					Path:           "other",
+					DefinitionFile: repository.NewIndexedFile("blueprint.yml", []byte("apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Other\nspec:\n  files:\n  - path: other.txt\n")),
+					Metadata:       repository.BlueprintIndexMetadata{Name: "Other"},
+					Files:          []repository.IndexedFile{repository.NewIndexedFile("other.txt", []byte("other\n"))},
+				},
+			},
+		}
+		assert.Equal(t, expected, result.Index)
+
+		var written repository.RepoIndex
+		content, err := ioutil.ReadFile(result.IndexPath)
+		require.Nil(t, err)
+		require.Nil(t, json.Unmarshal(content, &written))
+		assert.Equal(t, *expected, written)
+
+		assert.Equal(t, "notes\n", GetFileContent(filepath.Join(outputDir, "aws", "published", "config", "notes.txt")))
+		assert.False(t, fileExists(filepath.Join(outputDir, "aws", "published", ".DS_Store")))
+		assert.False(t, fileExists(filepath.Join(outputDir, ".git", "config")))
+
+		archive, err := zip.OpenReader(result.ArchivePath)
+		require.Nil(t, err)
+		defer archive.Close()
+		var names []string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+			if file.FileInfo().IsDir() {
+				assert.Equal(t, os.ModeDir|0755, file.Mode())
+			}
+		}
+		assert.Equal(t, []string{
+			"aws/",
+			"aws/published/",
+			"aws/published/app.yaml.tmpl",
+			"aws/published/blueprint.yaml",
+			"aws/published/config/",
+			"aws/published/config/notes.txt",
+			"other/",
+			"other/blueprint.yml",
+			"other/other.txt",
+			"index.json",
+		}, names)
+	})
+
+	t.Run("should publish nothing when a blueprint has validation errors", func(t *testing.T) {
+		repoDir, outputDir := newRepo(t, map[string]string{
+			"broken/blueprint.yaml": "apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Broken\nspec:\n  files:\n  - path: missing.txt\n",
+		})
+
+		result, err := PublishBlueprints(PublishOptions{RepoDir: repoDir, OutputDir: outputDir, Version: "1.0.0"})
+		require.NotNil(t, err)
+		assert.Equal(t, "the repository has blueprints with validation errors, nothing was published", err.Error())
+		assert.True(t, HasValidationErrors(result.Problems))
+		entries, err := ioutil.ReadDir(outputDir)
+		require.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should fail when the output directory is inside the repository", func(t *testing.T) {
+		repoDir, _ := newRepo(t, map[string]string{
+			"other/blueprint.yml": "apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Other\n",
+		})
+
+		_, err := PublishBlueprints(PublishOptions{RepoDir: repoDir, OutputDir: filepath.Join(repoDir, "dist"), Version: "1.0.0"})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "must not be inside the repository directory")
+	})
+
+	t.Run("should fail on an invalid version", func(t *testing.T) {
+		_, err := PublishBlueprints(PublishOptions{RepoDir: ".", OutputDir: "out", Version: "../1.0"})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid version [../1.0]")
+	})
+}
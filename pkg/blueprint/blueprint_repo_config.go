@@ -0,0 +1,151 @@
+package blueprint
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// RepositoryDefinitionFields are the known repository definition fields besides name & type, used by one or more providers
+var RepositoryDefinitionFields = []string{"url", "path", "owner", "repo-name", "branch", "project-key", "user", "username", "password", "token", "ignored-dirs", "ignored-files"}
+
+// secret repository definition fields are masked when shown to the user
+var secretRepositoryFields = []string{"password", "token"}
+
+const maskedValue = "*****"
+
+// ReadBlueprintConfData reads the blueprint repository configuration from the given config file,
+// the default repository is always part of the result
+func ReadBlueprintConfData(configPath string) (ConfData, error) {
+	v, err := readViperConfigFile(configPath)
+	if err != nil {
+		return ConfData{}, err
+	}
+	currentRepo := v.GetString(ViperKeyBlueprintCurrentRepository)
+	if currentRepo == "" {
+		currentRepo = models.DefaultBlueprintRepositoryName
+	}
+	return ConfData{CurrentRepo: currentRepo, Repositories: GetRepositoriesWithDefault(v)}, nil
+}
+
+// WriteBlueprintConfData writes the blueprint repository configuration to the given config file,
+// other settings found in the file are kept as they are
+func WriteBlueprintConfData(confData ConfData, configPath string) error {
+	v, err := readViperConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	v.Set(ViperKeyBlueprintCurrentRepository, confData.CurrentRepo)
+	v.Set(RepositoryConfigKey, confData.Repositories)
+
+	if !util.PathExists(filepath.Dir(configPath), true) {
+		if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
+			return err
+		}
+	}
+	util.Verbose("Writing blueprint configuration to %s\n", configPath)
+	return writeViperConfigFile(v, configPath)
+}
+
+func readViperConfigFile(configPath string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if util.PathExists(configPath, false) {
+		content, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.ReadConfig(bytes.NewBuffer(content)); err != nil {
+			return nil, fmt.Errorf("cannot read config file %s: %s", configPath, err.Error())
+		}
+	}
+	return v, nil
+}
+
+// GetRepository returns the repository definition with the given name
+func (confData *ConfData) GetRepository(name string) (ConfMap, error) {
+	i := confData.findRepository(name)
+	if i == -1 {
+		return nil, fmt.Errorf("repository with name '%s' is not defined", name)
+	}
+	return confData.Repositories[i], nil
+}
+
+// AddRepository validates the definition with the matching repository provider and adds it to the configuration
+func (confData *ConfData) AddRepository(repoDefinition ConfMap, CLIVersion string) error {
+	// Validate mandatory fields for all repository types
+	if !util.MapContainsKeyWithVal(repoDefinition, "type") || !util.MapContainsKeyWithVal(repoDefinition, "name") {
+		return fmt.Errorf("repository doesn't have all mandatory fields set [type, name]")
+	}
+	if confData.findRepository(repoDefinition["name"]) != -1 {
+		return fmt.Errorf("repository with name '%s' is already defined", repoDefinition["name"])
+	}
+	repoProvider, err := models.GetRepoProvider(repoDefinition["type"])
+	if err != nil {
+		return err
+	}
+	repoDefinition["type"] = repoProvider
+
+	if _, err := NewBlueprintRepository(repoDefinition, CLIVersion); err != nil {
+		return fmt.Errorf("invalid %s repository definition: %s", repoProvider, err.Error())
+	}
+	confData.Repositories = append(confData.Repositories, repoDefinition)
+	return nil
+}
+
+// RemoveRepository removes the repository with the given name from the configuration
+func (confData *ConfData) RemoveRepository(name string) error {
+	i := confData.findRepository(name)
+	if i == -1 {
+		return fmt.Errorf("repository with name '%s' is not defined", name)
+	}
+	repoName := confData.Repositories[i]["name"]
+	if repoName == models.DefaultBlueprintRepositoryName {
+		return fmt.Errorf("default repository '%s' cannot be removed", repoName)
+	}
+	if strings.EqualFold(repoName, confData.CurrentRepo) {
+		return fmt.Errorf("repository '%s' is the current repository, switch to another repository before removing it", repoName)
+	}
+	confData.Repositories = append(confData.Repositories[:i], confData.Repositories[i+1:]...)
+	return nil
+}
+
+// UseRepository sets the repository with the given name as the current repository
+func (confData *ConfData) UseRepository(name string) error {
+	repoDefinition, err := confData.GetRepository(name)
+	if err != nil {
+		return err
+	}
+	confData.CurrentRepo = repoDefinition["name"]
+	return nil
+}
+
+func (confData *ConfData) findRepository(name string) int {
+	for i, repoDefinition := range confData.Repositories {
+		if strings.EqualFold(repoDefinition["name"], name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// MaskSecretFields returns a copy of the repository definition with secret field values masked
+func MaskSecretFields(repoDefinition ConfMap) ConfMap {
+	masked := make(ConfMap, len(repoDefinition))
+	for k, v := range repoDefinition {
+		if util.IsStringInSlice(strings.ToLower(k), secretRepositoryFields) && v != "" {
+			masked[k] = maskedValue
+		} else {
+			masked[k] = v
+		}
+	}
+	return masked
+}
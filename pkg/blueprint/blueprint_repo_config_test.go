@@ -0,0 +1,178 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/yaml"
+)
+
+const repoConfigYaml = `
+xl-deploy:
+  url: http://localhost:4516/
+blueprint:
+  current-repository: XL Blueprints
+  repositories:
+  - name: XL Blueprints
+    type: http
+    url: https://dist.xebialabs.com/public/blueprints/${CLIVersion}/
+  - name: My Github
+    type: github
+    owner: xebialabs
+    repo-name: blueprints
+    token: secret-token
+`
+
+func writeRepoConfigFile(t *testing.T) (string, func()) {
+	configDir, err := ioutil.TempDir("", "xebialabsconfig")
+	require.Nil(t, err)
+	configFile := filepath.Join(configDir, "config.yaml")
+	require.Nil(t, ioutil.WriteFile(configFile, []byte(repoConfigYaml), 0640))
+	return configFile, func() { os.RemoveAll(configDir) }
+}
+
+func TestReadBlueprintConfData(t *testing.T) {
+	t.Run("should read repositories from config file", func(t *testing.T) {
+		configFile, cleanup := writeRepoConfigFile(t)
+		defer cleanup()
+
+		confData, err := ReadBlueprintConfData(configFile)
+		require.Nil(t, err)
+		assert.Equal(t, models.DefaultBlueprintRepositoryName, confData.CurrentRepo)
+		require.Len(t, confData.Repositories, 2)
+		assert.Equal(t, "My Github", confData.Repositories[1]["name"])
+	})
+
+	t.Run("should return default configuration when config file is not there", func(t *testing.T) {
+		confData, err := ReadBlueprintConfData(filepath.Join(os.TempDir(), "not-there", "config.yaml"))
+		require.Nil(t, err)
+		assert.Equal(t, GetDefaultBlueprintConfData(), confData)
+	})
+}
+
+func TestWriteBlueprintConfData(t *testing.T) {
+	t.Run("should persist repository changes and keep other settings", func(t *testing.T) {
+		configFile, cleanup := writeRepoConfigFile(t)
+		defer cleanup()
+
+		confData, err := ReadBlueprintConfData(configFile)
+		require.Nil(t, err)
+		err = confData.AddRepository(ConfMap{"name": "Local", "type": "Local", "path": GetTestTemplateDir("")}, DummyCLIVersion)
+		require.Nil(t, err)
+		require.Nil(t, confData.UseRepository("local"))
+		require.Nil(t, WriteBlueprintConfData(confData, configFile))
+
+		content, err := ioutil.ReadFile(configFile)
+		require.Nil(t, err)
+		parsed := make(map[string]interface{})
+		require.Nil(t, yaml.Unmarshal(content, &parsed))
+		assert.Contains(t, parsed, "xl-deploy")
+
+		reread, err := ReadBlueprintConfData(configFile)
+		require.Nil(t, err)
+		assert.Equal(t, "Local", reread.CurrentRepo)
+		require.Len(t, reread.Repositories, 3)
+		assert.Equal(t, ConfMap{"name": "Local", "type": models.ProviderLocal, "path": GetTestTemplateDir("")}, reread.Repositories[2])
+	})
+}
+
+func TestConfData_AddRepository(t *testing.T) {
+	t.Run("should error when mandatory fields are missing", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.AddRepository(ConfMap{"name": "test"}, DummyCLIVersion)
+		require.NotNil(t, err)
+		assert.Equal(t, "repository doesn't have all mandatory fields set [type, name]", err.Error())
+	})
+	t.Run("should error when name is already defined", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.AddRepository(ConfMap{"name": "xl blueprints", "type": "http", "url": "http://localhost/"}, DummyCLIVersion)
+		require.NotNil(t, err)
+		assert.Equal(t, "repository with name 'xl blueprints' is already defined", err.Error())
+	})
+	t.Run("should error on unknown provider", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.AddRepository(ConfMap{"name": "test", "type": "svn"}, DummyCLIVersion)
+		require.NotNil(t, err)
+		assert.Equal(t, "svn is not supported as repository provider", err.Error())
+	})
+	t.Run("should error when provider specific fields are missing", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.AddRepository(ConfMap{"name": "test", "type": "github", "owner": "xebialabs"}, DummyCLIVersion)
+		require.NotNil(t, err)
+		assert.Equal(t, "invalid github repository definition: 'repo-name' config field must be set for GitHub repository type", err.Error())
+		assert.Len(t, confData.Repositories, 1)
+	})
+	t.Run("should add valid repository", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.AddRepository(ConfMap{"name": "test", "type": "http", "url": "http://localhost/blueprints/"}, DummyCLIVersion)
+		require.Nil(t, err)
+		require.Len(t, confData.Repositories, 2)
+		assert.Equal(t, "test", confData.Repositories[1]["name"])
+	})
+}
+
+func TestConfData_RemoveRepository(t *testing.T) {
+	getConfData := func() ConfData {
+		return ConfData{
+			CurrentRepo: "Current",
+			Repositories: []ConfMap{
+				defaultBlueprintRepo,
+				{"name": "Current", "type": "http", "url": "http://localhost/current/"},
+				{"name": "Other", "type": "http", "url": "http://localhost/other/"},
+			},
+		}
+	}
+	t.Run("should error when repository is not defined", func(t *testing.T) {
+		confData := getConfData()
+		err := confData.RemoveRepository("unknown")
+		require.NotNil(t, err)
+		assert.Equal(t, "repository with name 'unknown' is not defined", err.Error())
+	})
+	t.Run("should not remove default repository", func(t *testing.T) {
+		confData := getConfData()
+		err := confData.RemoveRepository(models.DefaultBlueprintRepositoryName)
+		require.NotNil(t, err)
+		assert.Equal(t, "default repository 'XL Blueprints' cannot be removed", err.Error())
+	})
+	t.Run("should not remove current repository", func(t *testing.T) {
+		confData := getConfData()
+		err := confData.RemoveRepository("current")
+		require.NotNil(t, err)
+		assert.Equal(t, "repository 'Current' is the current repository, switch to another repository before removing it", err.Error())
+	})
+	t.Run("should remove repository", func(t *testing.T) {
+		confData := getConfData()
+		require.Nil(t, confData.RemoveRepository("other"))
+		require.Len(t, confData.Repositories, 2)
+		assert.Equal(t, "Current", confData.Repositories[1]["name"])
+	})
+}
+
+func TestConfData_UseRepository(t *testing.T) {
+	t.Run("should set current repository with defined name", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		confData.Repositories = append(confData.Repositories, ConfMap{"name": "Other", "type": "http", "url": "http://localhost/"})
+		require.Nil(t, confData.UseRepository("OTHER"))
+		assert.Equal(t, "Other", confData.CurrentRepo)
+	})
+	t.Run("should error when repository is not defined", func(t *testing.T) {
+		confData := GetDefaultBlueprintConfData()
+		err := confData.UseRepository("Other")
+		require.NotNil(t, err)
+		assert.Equal(t, models.DefaultBlueprintRepositoryName, confData.CurrentRepo)
+	})
+}
+
+func TestMaskSecretFields(t *testing.T) {
+	t.Run("should mask secret fields only", func(t *testing.T) {
+		original := ConfMap{"name": "test", "token": "abc", "password": ""}
+		masked := MaskSecretFields(original)
+		assert.Equal(t, ConfMap{"name": "test", "token": "*****", "password": ""}, masked)
+		assert.Equal(t, "abc", original["token"])
+	})
+}
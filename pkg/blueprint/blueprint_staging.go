@@ -0,0 +1,325 @@
+package blueprint
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+const (
+	stagingDirPrefix = ".blueprint-staging-"
+	stagedFilesDir   = "files"
+	journalBackupDir = "backup"
+)
+
+// Actions recorded in the rollback journal while promoting staged files
+const (
+	journalCreateDir = iota
+	journalCreateFile
+	journalReplaceFile
+	journalRemoveFile
+)
+
+// journalEntry is a single step of the promotion of staged files, it holds what is needed to undo the step
+type journalEntry struct {
+	action     int
+	path       string
+	backupPath string
+}
+
+// BeginStaging makes all output files to be written into a staging directory under the root directory,
+// they are moved into the output tree only once all of them are written with Commit
+func (generatedBlueprint *GeneratedBlueprint) BeginStaging() error {
+	rootDir := generatedBlueprint.RootDir
+	if rootDir == "" {
+		rootDir = "."
+	}
+	dirsBefore := len(generatedBlueprint.GeneratedFiles)
+	if err := generatedBlueprint.createDirectoryIfNeeded(rootDir); err != nil {
+		return err
+	}
+	generatedBlueprint.journalCreatedDirs(dirsBefore)
+
+	stagingDir := filepath.Join(rootDir, fmt.Sprintf("%s%d", stagingDirPrefix, time.Now().UnixNano()))
+	if err := generatedBlueprint.fs().Mkdir(stagingDir, os.ModePerm); err != nil {
+		return err
+	}
+	generatedBlueprint.log().Verbose("[file] Staging output files in %s\n", stagingDir)
+	generatedBlueprint.stagingDir = stagingDir
+	generatedBlueprint.stagedFiles = make(map[string]string)
+	return nil
+}
+
+// getStagedFile creates the staged version of the output file and registers the output file as generated
+func (generatedBlueprint *GeneratedBlueprint) getStagedFile(fileName string, outputFile string) (io.WriteCloser, error) {
+	generatedBlueprint.mutex.Lock()
+	defer generatedBlueprint.mutex.Unlock()
+
+	if err := generatedBlueprint.interruptedError(); err != nil {
+		return nil, err
+	}
+	stagedFile := filepath.Join(generatedBlueprint.stagingDir, stagedFilesDir, fileName)
+	if err := generatedBlueprint.fs().MkdirAll(filepath.Dir(stagedFile), os.ModePerm); err != nil {
+		return nil, err
+	}
+	generatedBlueprint.log().Verbose("[file] Staging file %s\n", outputFile)
+	file, err := generatedBlueprint.fs().Create(stagedFile)
+	if err != nil {
+		return nil, err
+	}
+	generatedBlueprint.registerOutputFile(outputFile)
+	if _, ok := generatedBlueprint.stagedFiles[outputFile]; !ok && !generatedBlueprint.stagedRemovals[outputFile] {
+		generatedBlueprint.stagedOrder = append(generatedBlueprint.stagedOrder, outputFile)
+	}
+	generatedBlueprint.stagedFiles[outputFile] = stagedFile
+	delete(generatedBlueprint.stagedRemovals, outputFile)
+	return file, nil
+}
+
+// stageRemoval makes the output file to be removed along with the promotion of the staged files
+func (generatedBlueprint *GeneratedBlueprint) stageRemoval(fileName string) {
+	generatedBlueprint.mutex.Lock()
+	defer generatedBlueprint.mutex.Unlock()
+
+	outputFile := generatedBlueprint.outputPath(fileName)
+	if generatedBlueprint.stagedRemovals == nil {
+		generatedBlueprint.stagedRemovals = make(map[string]bool)
+	}
+	if _, ok := generatedBlueprint.stagedFiles[outputFile]; !ok && !generatedBlueprint.stagedRemovals[outputFile] {
+		generatedBlueprint.stagedOrder = append(generatedBlueprint.stagedOrder, outputFile)
+	}
+	delete(generatedBlueprint.stagedFiles, outputFile)
+	generatedBlueprint.stagedRemovals[outputFile] = true
+}
+
+// writtenPath returns where the content of the output file currently is, which is the staged file until it is promoted
+func (generatedBlueprint *GeneratedBlueprint) writtenPath(outputFile string) string {
+	if stagedFile, ok := generatedBlueprint.stagedFiles[outputFile]; ok {
+		return stagedFile
+	}
+	return outputFile
+}
+
+// Commit promotes all staged files into the output tree. Every step is recorded in a journal,
+// so that the output tree is restored to its previous state if promoting any of the files fails
+func (generatedBlueprint *GeneratedBlueprint) Commit() error {
+	if generatedBlueprint.stagingDir == "" {
+		return nil
+	}
+	for _, outputFile := range generatedBlueprint.stagedOrder {
+		if err := generatedBlueprint.promote(outputFile); err != nil {
+			if rollbackErr := generatedBlueprint.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("%s, rollback failed: %s", err, rollbackErr)
+			}
+			return err
+		}
+	}
+
+	generatedBlueprint.mutex.Lock()
+	defer generatedBlueprint.mutex.Unlock()
+	err := generatedBlueprint.fs().RemoveAll(generatedBlueprint.stagingDir)
+	generatedBlueprint.resetStaging()
+	return err
+}
+
+// promote moves a staged file to its place in the output tree, the existing file is kept as backup until the commit is done
+func (generatedBlueprint *GeneratedBlueprint) promote(outputFile string) error {
+	generatedBlueprint.mutex.Lock()
+	defer generatedBlueprint.mutex.Unlock()
+
+	if generatedBlueprint.stagingDir == "" {
+		return fmt.Errorf("blueprint generation was rolled back")
+	}
+	if err := generatedBlueprint.interruptedError(); err != nil {
+		return err
+	}
+	if generatedBlueprint.stagedRemovals[outputFile] {
+		return generatedBlueprint.removeFile(outputFile)
+	}
+	stagedFile := generatedBlueprint.stagedFiles[outputFile]
+
+	dirsBefore := len(generatedBlueprint.GeneratedFiles)
+	if err := generatedBlueprint.createDirectoryIfNeeded(filepath.Dir(outputFile)); err != nil {
+		return err
+	}
+	generatedBlueprint.journalCreatedDirs(dirsBefore)
+
+	if util.IsStringInSlice(outputFile, generatedBlueprint.backupFiles) && generatedBlueprint.pathExists(outputFile, false) {
+		// the user asked to keep a copy of the existing file next to the generated one
+		existingContent, err := generatedBlueprint.fs().ReadFile(outputFile)
+		if err != nil {
+			return err
+		}
+		if err := generatedBlueprint.writeFile(stagedFile+backupExtension, existingContent); err != nil {
+			return err
+		}
+		if err := generatedBlueprint.promoteFile(outputFile+backupExtension, stagedFile+backupExtension); err != nil {
+			return err
+		}
+	}
+	return generatedBlueprint.promoteFile(outputFile, stagedFile)
+}
+
+func (generatedBlueprint *GeneratedBlueprint) promoteFile(outputFile string, stagedFile string) error {
+	generatedBlueprint.log().Verbose("[file] Promoting file %s\n", outputFile)
+	if generatedBlueprint.pathExists(outputFile, false) {
+		backupFile := filepath.Join(generatedBlueprint.stagingDir, journalBackupDir, strconv.Itoa(len(generatedBlueprint.journal)))
+		if err := generatedBlueprint.fs().MkdirAll(filepath.Dir(backupFile), os.ModePerm); err != nil {
+			return err
+		}
+		if err := generatedBlueprint.fs().Rename(outputFile, backupFile); err != nil {
+			return err
+		}
+		generatedBlueprint.journal = append(generatedBlueprint.journal, journalEntry{action: journalReplaceFile, path: outputFile, backupPath: backupFile})
+	} else {
+		generatedBlueprint.journal = append(generatedBlueprint.journal, journalEntry{action: journalCreateFile, path: outputFile})
+	}
+	return generatedBlueprint.fs().Rename(stagedFile, outputFile)
+}
+
+// removeFile moves the output file to the backups of the journal, so that it is restored on rollback
+func (generatedBlueprint *GeneratedBlueprint) removeFile(outputFile string) error {
+	if !generatedBlueprint.pathExists(outputFile, false) {
+		return nil
+	}
+	generatedBlueprint.log().Verbose("[file] Removing file %s\n", outputFile)
+	backupFile := filepath.Join(generatedBlueprint.stagingDir, journalBackupDir, strconv.Itoa(len(generatedBlueprint.journal)))
+	if err := generatedBlueprint.fs().MkdirAll(filepath.Dir(backupFile), os.ModePerm); err != nil {
+		return err
+	}
+	if err := generatedBlueprint.fs().Rename(outputFile, backupFile); err != nil {
+		return err
+	}
+	generatedBlueprint.journal = append(generatedBlueprint.journal, journalEntry{action: journalRemoveFile, path: outputFile, backupPath: backupFile})
+	return nil
+}
+
+// writeFile writes the contents to a file in the output filesystem, without registering it as generated
+func (generatedBlueprint *GeneratedBlueprint) writeFile(fileName string, content []byte) error {
+	file, err := generatedBlueprint.fs().Create(fileName)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// journalCreatedDirs records the directories created since the given number of generated files in the journal
+func (generatedBlueprint *GeneratedBlueprint) journalCreatedDirs(generatedFilesBefore int) {
+	for _, dir := range generatedBlueprint.GeneratedFiles[generatedFilesBefore:] {
+		generatedBlueprint.journal = append(generatedBlueprint.journal, journalEntry{action: journalCreateDir, path: dir})
+	}
+}
+
+// Rollback undoes everything done since BeginStaging: promoted files are removed or restored from their backup, as are removed files,
+// created directories are removed and the staging directory is discarded. It does nothing once committed
+func (generatedBlueprint *GeneratedBlueprint) Rollback() error {
+	generatedBlueprint.mutex.Lock()
+	defer generatedBlueprint.mutex.Unlock()
+
+	if generatedBlueprint.stagingDir == "" {
+		return nil
+	}
+	generatedBlueprint.log().Verbose("[file] Rolling back blueprint output files\n")
+
+	var errs []error
+	var createdDirs []string
+	for i := len(generatedBlueprint.journal) - 1; i >= 0; i-- {
+		entry := generatedBlueprint.journal[i]
+		switch entry.action {
+		case journalCreateFile:
+			if err := generatedBlueprint.fs().Remove(entry.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		case journalReplaceFile, journalRemoveFile:
+			if err := generatedBlueprint.fs().Rename(entry.backupPath, entry.path); err != nil {
+				errs = append(errs, err)
+			}
+		case journalCreateDir:
+			createdDirs = append(createdDirs, entry.path)
+		}
+	}
+	// backups live in the staging directory, so it can only be removed once all files are restored
+	if err := generatedBlueprint.fs().RemoveAll(generatedBlueprint.stagingDir); err != nil {
+		errs = append(errs, err)
+	}
+	for _, dir := range createdDirs {
+		if err := generatedBlueprint.fs().Remove(dir); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	generatedBlueprint.resetStaging()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors while rolling back: %v", len(errs), errs)
+	}
+	return nil
+}
+
+func (generatedBlueprint *GeneratedBlueprint) resetStaging() {
+	generatedBlueprint.stagingDir = ""
+	generatedBlueprint.stagedFiles = nil
+	generatedBlueprint.stagedOrder = nil
+	generatedBlueprint.stagedRemovals = nil
+	generatedBlueprint.backupFiles = nil
+	generatedBlueprint.journal = nil
+}
+
+// interruptOnSignal makes the generation fail once the process is interrupted or terminated, so that the caller
+// rolls it back when it is not committed yet. The returned function stops listening for the signals and returns
+// the signal received meanwhile, if any
+func (generatedBlueprint *GeneratedBlueprint) interruptOnSignal() func() os.Signal {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupt := func(sig os.Signal) {
+		generatedBlueprint.mutex.Lock()
+		defer generatedBlueprint.mutex.Unlock()
+		generatedBlueprint.interruptedBy = sig
+	}
+	go func() {
+		defer close(stopped)
+		select {
+		case sig := <-signals:
+			interrupt(sig)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() os.Signal {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			<-stopped
+			select {
+			case sig := <-signals:
+				interrupt(sig)
+			default:
+			}
+		})
+		generatedBlueprint.mutex.Lock()
+		defer generatedBlueprint.mutex.Unlock()
+		return generatedBlueprint.interruptedBy
+	}
+}
+
+// interruptedError returns an error once the generation is interrupted, nothing is staged or promoted after that
+func (generatedBlueprint *GeneratedBlueprint) interruptedError() error {
+	if generatedBlueprint.interruptedBy != nil {
+		return fmt.Errorf("blueprint generation interrupted by %s", generatedBlueprint.interruptedBy)
+	}
+	return nil
+}
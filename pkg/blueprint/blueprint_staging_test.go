@@ -0,0 +1,147 @@
+package blueprint
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStagedTestFile(t *testing.T, gb *GeneratedBlueprint, fileName string, content string) {
+	file, err := gb.GetOutputFile(fileName)
+	require.Nil(t, err)
+	defer file.Close()
+	_, err = io.WriteString(file, content)
+	require.Nil(t, err)
+}
+
+func stagingDirs(t *testing.T, rootDir string) []string {
+	dirs, err := filepath.Glob(filepath.Join(rootDir, stagingDirPrefix+"*"))
+	require.Nil(t, err)
+	return dirs
+}
+
+func TestGeneratedBlueprint_Staging(t *testing.T) {
+	t.Run("should only write output files on commit", func(t *testing.T) {
+		rootDir, err := ioutil.TempDir("", "staging")
+		require.Nil(t, err)
+		defer os.RemoveAll(rootDir)
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "existing.txt"), []byte("old"), 0640))
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "removed.txt"), []byte("removed"), 0640))
+
+		gb := &GeneratedBlueprint{RootDir: rootDir, OutputDir: "xebialabs"}
+		require.Nil(t, gb.BeginStaging())
+		writeStagedTestFile(t, gb, "existing.txt", "new")
+		writeStagedTestFile(t, gb, filepath.Join("xebialabs", "values.xlvals"), "key=value")
+		gb.stageRemoval("removed.txt")
+
+		assert.Equal(t, "old", GetFileContent(filepath.Join(rootDir, "existing.txt")))
+		assert.True(t, fileExists(filepath.Join(rootDir, "removed.txt")))
+		assert.False(t, fileExists(filepath.Join(rootDir, "xebialabs")))
+		assert.Equal(t, "key=value", GetFileContent(gb.writtenPath(filepath.Join(rootDir, "xebialabs", "values.xlvals"))))
+
+		require.Nil(t, gb.Commit())
+		assert.Equal(t, "new", GetFileContent(filepath.Join(rootDir, "existing.txt")))
+		assert.Equal(t, "key=value", GetFileContent(filepath.Join(rootDir, "xebialabs", "values.xlvals")))
+		assert.False(t, fileExists(filepath.Join(rootDir, "removed.txt")))
+		assert.Empty(t, stagingDirs(t, rootDir))
+		assert.Equal(t, []string{filepath.Join(rootDir, "existing.txt")}, gb.PreExistingFiles)
+
+		// nothing to roll back once committed
+		require.Nil(t, gb.Rollback())
+		assert.Equal(t, "new", GetFileContent(filepath.Join(rootDir, "existing.txt")))
+	})
+
+	t.Run("should remove staged files and created directories on rollback", func(t *testing.T) {
+		parentDir, err := ioutil.TempDir("", "staging")
+		require.Nil(t, err)
+		defer os.RemoveAll(parentDir)
+		rootDir := filepath.Join(parentDir, "project")
+
+		gb := &GeneratedBlueprint{RootDir: rootDir, OutputDir: "xebialabs"}
+		require.Nil(t, gb.BeginStaging())
+		writeStagedTestFile(t, gb, "app.yaml", "app")
+		require.Nil(t, gb.Rollback())
+
+		assert.False(t, fileExists(rootDir))
+		require.Nil(t, gb.Commit())
+		assert.False(t, fileExists(rootDir))
+	})
+
+	t.Run("should restore the output tree when promotion fails", func(t *testing.T) {
+		rootDir, err := ioutil.TempDir("", "staging")
+		require.Nil(t, err)
+		defer os.RemoveAll(rootDir)
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "existing.txt"), []byte("old"), 0640))
+		// a file where a directory is expected makes the promotion fail
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "blocker"), []byte("blocker"), 0640))
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "removed.txt"), []byte("removed"), 0640))
+
+		gb := &GeneratedBlueprint{RootDir: rootDir, OutputDir: "xebialabs"}
+		require.Nil(t, gb.BeginStaging())
+		writeStagedTestFile(t, gb, "existing.txt", "new")
+		writeStagedTestFile(t, gb, filepath.Join("xebialabs", "values.xlvals"), "key=value")
+		gb.stageRemoval("removed.txt")
+		writeStagedTestFile(t, gb, filepath.Join("blocker", "file.txt"), "content")
+
+		err = gb.Commit()
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "exists but is not a directory")
+
+		assert.Equal(t, "old", GetFileContent(filepath.Join(rootDir, "existing.txt")))
+		assert.Equal(t, "blocker", GetFileContent(filepath.Join(rootDir, "blocker")))
+		assert.Equal(t, "removed", GetFileContent(filepath.Join(rootDir, "removed.txt")))
+		assert.False(t, fileExists(filepath.Join(rootDir, "xebialabs")))
+		assert.Empty(t, stagingDirs(t, rootDir))
+	})
+
+	t.Run("should fail staging and commit once interrupted by a signal", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("signals cannot be sent to the process on windows")
+		}
+		rootDir, err := ioutil.TempDir("", "staging")
+		require.Nil(t, err)
+		defer os.RemoveAll(rootDir)
+		require.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, "existing.txt"), []byte("old"), 0640))
+
+		gb := &GeneratedBlueprint{RootDir: rootDir, OutputDir: "xebialabs"}
+		require.Nil(t, gb.BeginStaging())
+		writeStagedTestFile(t, gb, "existing.txt", "new")
+
+		stopInterruptOnSignal := gb.interruptOnSignal()
+		defer stopInterruptOnSignal()
+		process, err := os.FindProcess(os.Getpid())
+		require.Nil(t, err)
+		require.Nil(t, process.Signal(syscall.SIGTERM))
+		require.Eventually(t, func() bool {
+			gb.mutex.Lock()
+			defer gb.mutex.Unlock()
+			return gb.interruptedBy != nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = gb.GetOutputFile("app.yaml")
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint generation interrupted by terminated", err.Error())
+		err = gb.Commit()
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint generation interrupted by terminated", err.Error())
+		assert.Equal(t, syscall.SIGTERM, stopInterruptOnSignal())
+
+		assert.Equal(t, "old", GetFileContent(filepath.Join(rootDir, "existing.txt")))
+		assert.Empty(t, stagingDirs(t, rootDir))
+	})
+
+	t.Run("should return no signal when not interrupted", func(t *testing.T) {
+		gb := &GeneratedBlueprint{}
+		stopInterruptOnSignal := gb.interruptOnSignal()
+		assert.Nil(t, stopInterruptOnSignal())
+		assert.Nil(t, stopInterruptOnSignal())
+	})
+}
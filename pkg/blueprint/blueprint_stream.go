@@ -0,0 +1,70 @@
+package blueprint
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// OutputFormatStdout is the format of the stream written by WriteBlueprintStream
+const OutputFormatStdout = "stdout"
+
+var yamlExtensions = []string{".yaml", ".yml"}
+
+// WriteBlueprintStream renders the blueprint templates in memory and writes the YAML files among them to the writer
+// as one stream of YAML documents, each preceded by a comment with the path of the file. Nothing is written to disk.
+// Secret parameters are rendered as !value tags unless they have replaceAsIs set, and the values and secrets files
+// are not written at all
+func WriteBlueprintStream(
+	w io.Writer,
+	params BlueprintParams,
+	blueprintContext *BlueprintContext,
+	overrideFns ExpressionOverrideFn,
+	surveyOpts ...survey.AskOpt,
+) (*PreparedData, error) {
+	// nothing on disk is consulted or changed
+	generatedBlueprint := &GeneratedBlueprint{OutputDir: models.BlueprintOutputDir, FS: NewMemoryFS()}
+	params.OutputRoot = ""
+	// there is nothing to confirm either, as with a dry run
+	params.DryRun = true
+	preparedData, blueprintDoc, _, err := prepareBlueprint(newCLISession(surveyOpts...), &params, blueprintContext, generatedBlueprint, overrideFns)
+	if err != nil {
+		return nil, err
+	}
+	plannedFiles, err := planBlueprintFiles(blueprintContext, blueprintDoc, preparedData, generatedBlueprint, overrideFns)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the order of the templates, a file generated again by a composed blueprint replaces the earlier one
+	var documents []PlannedFile
+	documentIndex := make(map[string]int)
+	for _, plannedFile := range plannedFiles {
+		if plannedFile.Action == PlanActionSkip || plannedFile.Source == "" {
+			continue
+		}
+		if !util.IsStringInSlice(strings.ToLower(filepath.Ext(plannedFile.Path)), yamlExtensions) {
+			generatedBlueprint.log().Verbose("[file] Skipping file %s from the output stream because it is not a YAML file\n", plannedFile.Path)
+			continue
+		}
+		if i, ok := documentIndex[plannedFile.Path]; ok {
+			documents[i] = plannedFile
+		} else {
+			documentIndex[plannedFile.Path] = len(documents)
+			documents = append(documents, plannedFile)
+		}
+	}
+
+	for _, document := range documents {
+		content := strings.TrimSuffix(document.Content, "\n")
+		if _, err := fmt.Fprintf(w, "---\n# Source: %s\n%s\n", filepath.ToSlash(document.Path), content); err != nil {
+			return nil, err
+		}
+	}
+	return preparedData, nil
+}
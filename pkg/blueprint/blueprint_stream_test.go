@@ -0,0 +1,78 @@
+package blueprint
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamedBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Streamed
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+  - name: Password
+    type: SecretInput
+    prompt: Password?
+  - name: Token
+    type: SecretInput
+    prompt: Token?
+    replaceAsIs: true
+  files:
+  - path: deployment.yaml.tmpl
+  - path: notes.txt
+  - path: k8s/service.yml
+`
+
+func TestWriteBlueprintStream(t *testing.T) {
+	SkipFinalPrompt = false
+	defer func() { SkipFinalPrompt = true }()
+	repoDir, err := ioutil.TempDir("", "streamrepo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+	outputRoot, err := ioutil.TempDir("", "streamoutput")
+	require.Nil(t, err)
+	defer os.RemoveAll(outputRoot)
+
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"streamed/blueprint.yaml":       streamedBlueprintYaml,
+		"streamed/deployment.yaml.tmpl": "name: {{.AppName}}\npassword: {{.Password}}\ntoken: {{.Token}}\n",
+		"streamed/notes.txt":            "notes\n",
+		"streamed/k8s/service.yml":      "kind: Service\n",
+	})
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+
+	out := &bytes.Buffer{}
+	preparedData, err := WriteBlueprintStream(
+		out,
+		BlueprintParams{
+			TemplatePath:  "streamed",
+			AnswersMap:    map[string]string{"AppName": "my-app", "Password": "secret", "Token": "raw-token"},
+			StrictAnswers: true,
+			OutputRoot:    outputRoot,
+		},
+		blueprintContext,
+		nil,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, "my-app", preparedData.Answers["AppName"])
+
+	assert.Equal(t, "---\n# Source: deployment.yaml\nname: my-app\npassword: !value Password\ntoken: raw-token\n"+
+		"---\n# Source: k8s/service.yml\nkind: Service\n", out.String())
+
+	files, err := ioutil.ReadDir(outputRoot)
+	require.Nil(t, err)
+	assert.Empty(t, files)
+	assert.False(t, fileExists(filepath.Join("xebialabs", secretsFile)))
+}
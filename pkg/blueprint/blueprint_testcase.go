@@ -0,0 +1,288 @@
+package blueprint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+	"github.com/xebialabs/yaml"
+)
+
+const testCaseDir = "__test__"
+
+var regExTestCaseFile = regexp.MustCompile(`^test-case-.*\.ya?ml$`)
+
+// BlueprintTestCase is a test case shipped in the __test__ directory of a blueprint
+type BlueprintTestCase struct {
+	AnswersFile       string            `yaml:"answers-file"`
+	ExpectedFiles     []string          `yaml:"expected-files"`
+	NotExpectedFiles  []string          `yaml:"not-expected-files"`
+	ExpectedXlValues  map[string]string `yaml:"expected-xl-values"`
+	ExpectedXlSecrets map[string]string `yaml:"expected-xl-secrets"`
+}
+
+// BlueprintTestResult is the outcome of running a single blueprint test case
+type BlueprintTestResult struct {
+	Blueprint string        `json:"blueprint" yaml:"blueprint"`
+	TestCase  string        `json:"testCase" yaml:"testCase"`
+	Duration  time.Duration `json:"duration" yaml:"duration"`
+	Error     string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Failures  []string      `json:"failures,omitempty" yaml:"failures,omitempty"`
+}
+
+// Passed returns true when the test case ran without errors and all expectations were met
+func (result *BlueprintTestResult) Passed() bool {
+	return result.Error == "" && len(result.Failures) == 0
+}
+
+// RunBlueprintTests runs the test cases of the given blueprints in the active repository,
+// all blueprints shipping test cases are tested when no template path is given.
+// Each test case generates the blueprint in a temporary directory using the answers file in strict mode
+func (blueprintContext *BlueprintContext) RunBlueprintTests(templatePaths ...string) ([]BlueprintTestResult, error) {
+	util.Verbose("[test] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(templatePaths) == 0 {
+		for k, blueprint := range blueprints {
+			if len(findTestCaseFiles(blueprint)) > 0 {
+				templatePaths = append(templatePaths, k)
+			}
+		}
+		sort.Strings(templatePaths)
+	}
+
+	var results []BlueprintTestResult
+	for _, templatePath := range templatePaths {
+		blueprint := blueprints[templatePath]
+		if blueprint == nil {
+			return nil, fmt.Errorf("blueprint [%s] not found in repository %s", templatePath, (*blueprintContext.ActiveRepo).GetName())
+		}
+		testCaseFiles := findTestCaseFiles(blueprint)
+		if len(testCaseFiles) == 0 {
+			util.Info("No test cases found for blueprint [%s]\n", templatePath)
+		}
+		for _, testCaseFile := range testCaseFiles {
+			results = append(results, blueprintContext.runBlueprintTestCase(templatePath, testCaseFile))
+		}
+	}
+	return results, nil
+}
+
+// findTestCaseFiles returns the sorted paths of the test case files in the __test__ directory of the blueprint
+func findTestCaseFiles(blueprint *models.BlueprintRemote) []string {
+	var testCaseFiles []string
+	for _, file := range blueprint.Files {
+		filePath := filepath.ToSlash(file.Path)
+		if path.Base(path.Dir(filePath)) == testCaseDir && regExTestCaseFile.MatchString(file.Filename) {
+			testCaseFiles = append(testCaseFiles, filePath)
+		}
+	}
+	sort.Strings(testCaseFiles)
+	return testCaseFiles
+}
+
+func (blueprintContext *BlueprintContext) runBlueprintTestCase(templatePath string, testCaseFile string) BlueprintTestResult {
+	util.Verbose("[test] Running test case %s\n", testCaseFile)
+	start := time.Now()
+	result := BlueprintTestResult{Blueprint: templatePath, TestCase: path.Base(testCaseFile)}
+	failures, err := blueprintContext.executeBlueprintTestCase(templatePath, testCaseFile)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Failures = failures
+	return result
+}
+
+func (blueprintContext *BlueprintContext) executeBlueprintTestCase(templatePath string, testCaseFile string) ([]string, error) {
+	content, err := blueprintContext.fetchFileContents(testCaseFile, false)
+	if err != nil {
+		return nil, err
+	}
+	testCase := BlueprintTestCase{}
+	if err := yaml.Unmarshal(*content, &testCase); err != nil {
+		return nil, fmt.Errorf("cannot parse test case %s: %s", testCaseFile, err.Error())
+	}
+	if testCase.AnswersFile == "" {
+		return nil, fmt.Errorf("'answers-file' is not set in test case %s", testCaseFile)
+	}
+
+	answersContent, err := blueprintContext.fetchFileContents(path.Join(path.Dir(testCaseFile), testCase.AnswersFile), false)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := parseAnswers(*answersContent)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse answers file %s: %s", testCase.AnswersFile, err.Error())
+	}
+
+	outputDir, err := ioutil.TempDir("", "blueprint-test")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(outputDir)
+
+	isQuiet := util.IsQuiet
+	util.IsQuiet = !util.IsVerbose
+	params := BlueprintParams{TemplatePath: templatePath, AnswersMap: answers, StrictAnswers: true, OutputRoot: outputDir}
+	generatedBlueprint := &GeneratedBlueprint{OutputDir: models.BlueprintOutputDir}
+	_, _, err = InstantiateBlueprint(params, blueprintContext, generatedBlueprint, nil)
+	util.IsQuiet = isQuiet
+	if err != nil {
+		return nil, err
+	}
+
+	return testCase.verify(outputDir)
+}
+
+// verify checks the expectations of the test case against the blueprint generated in the output directory
+func (testCase *BlueprintTestCase) verify(outputDir string) ([]string, error) {
+	var failures []string
+	for _, file := range testCase.ExpectedFiles {
+		if !util.PathExists(filepath.Join(outputDir, file), false) {
+			failures = append(failures, fmt.Sprintf("expected file [%s] was not generated", file))
+		}
+	}
+	for _, file := range testCase.NotExpectedFiles {
+		if util.PathExists(filepath.Join(outputDir, file), false) {
+			failures = append(failures, fmt.Sprintf("file [%s] was generated but was not expected", file))
+		}
+	}
+
+	valueFailures, err := verifyXlvals(filepath.Join(outputDir, models.BlueprintOutputDir, valuesFile), testCase.ExpectedXlValues)
+	if err != nil {
+		return failures, err
+	}
+	secretFailures, err := verifyXlvals(filepath.Join(outputDir, models.BlueprintOutputDir, secretsFile), testCase.ExpectedXlSecrets)
+	if err != nil {
+		return failures, err
+	}
+	return append(append(failures, valueFailures...), secretFailures...), nil
+}
+
+func verifyXlvals(fileName string, expected map[string]string) ([]string, error) {
+	if len(expected) == 0 {
+		return nil, nil
+	}
+	if !util.PathExists(fileName, false) {
+		return []string{fmt.Sprintf("expected file [%s] was not generated", filepath.Join(models.BlueprintOutputDir, filepath.Base(fileName)))}, nil
+	}
+	props, err := properties.LoadFile(fileName, properties.UTF8)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var failures []string
+	for _, k := range keys {
+		actual, ok := props.Get(k)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("expected key [%s] is missing in %s", k, filepath.Base(fileName)))
+		} else if actual != expected[k] {
+			failures = append(failures, fmt.Sprintf("expected value [%s] for key [%s] in %s, got [%s]", expected[k], k, filepath.Base(fileName), actual))
+		}
+	}
+	return failures, nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Tests   int              `xml:"tests,attr"`
+	Errors  int              `xml:"errors,attr"`
+	Failure int              `xml:"failures,attr"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the blueprint test results as JUnit XML, with one test suite per blueprint
+func WriteJUnitReport(w io.Writer, results []BlueprintTestResult) error {
+	report := junitTestSuites{}
+	suites := make(map[string]*junitTestSuite)
+	var durations = make(map[string]time.Duration)
+	var suiteNames []string
+	for _, result := range results {
+		suite, ok := suites[result.Blueprint]
+		if !ok {
+			suite = &junitTestSuite{Name: result.Blueprint}
+			suites[result.Blueprint] = suite
+			suiteNames = append(suiteNames, result.Blueprint)
+		}
+		testCase := junitTestCase{
+			Name:      result.TestCase,
+			ClassName: result.Blueprint,
+			Time:      formatSeconds(result.Duration),
+		}
+		if result.Error != "" {
+			testCase.Error = &junitMessage{Message: result.Error, Text: result.Error}
+			suite.Errors++
+			report.Errors++
+		} else if len(result.Failures) > 0 {
+			testCase.Failure = &junitMessage{Message: result.Failures[0], Text: strings.Join(result.Failures, "\n")}
+			suite.Failures++
+			report.Failure++
+		}
+		suite.Tests++
+		report.Tests++
+		durations[result.Blueprint] += result.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	for _, name := range suiteNames {
+		suites[name].Time = formatSeconds(durations[name])
+		report.Suites = append(report.Suites, *suites[name])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
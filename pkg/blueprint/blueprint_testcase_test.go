@@ -0,0 +1,153 @@
+package blueprint
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testedBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Tested
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+    saveInXlvals: true
+  - name: Password
+    type: SecretInput
+    prompt: Password?
+  - name: WithPipeline
+    type: Confirm
+    prompt: Create pipeline?
+  files:
+  - path: xebialabs.yaml.tmpl
+  - path: pipeline.yaml
+    writeIf: !expr "WithPipeline"
+`
+
+func writeTestCaseRepo(t *testing.T) (string, func()) {
+	repoDir, err := ioutil.TempDir("", "testcaserepo")
+	require.Nil(t, err)
+	files := map[string]string{
+		"tested/blueprint.yaml":                testedBlueprintYaml,
+		"tested/xebialabs.yaml.tmpl":           "name: {{.AppName}}\n",
+		"tested/pipeline.yaml":                 "kind: Pipeline\n",
+		"tested/__test__/answers-1.yaml":       "AppName: my-app\nPassword: secret\nWithPipeline: true\n",
+		"tested/__test__/answers-2.yaml":       "AppName: other-app\nPassword: secret\nWithPipeline: false\n",
+		"tested/__test__/answers-partial.yaml": "AppName: other-app\n",
+		"tested/__test__/test-case-1.yaml": `
+answers-file: answers-1.yaml
+expected-files:
+- xebialabs.yaml
+- pipeline.yaml
+- xebialabs/values.xlvals
+expected-xl-values:
+  AppName: my-app
+expected-xl-secrets:
+  Password: secret
+`,
+		"tested/__test__/test-case-2.yaml": `
+answers-file: answers-2.yaml
+expected-files:
+- pipeline.yaml
+not-expected-files:
+- xebialabs.yaml
+expected-xl-values:
+  AppName: my-app
+  Missing: value
+`,
+		"tested/__test__/test-case-3.yaml": `
+answers-file: answers-partial.yaml
+`,
+		"untested/blueprint.yaml": testedBlueprintYaml,
+	}
+	for name, content := range files {
+		filePath := filepath.Join(repoDir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0750))
+		require.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0640))
+	}
+	return repoDir, func() { os.RemoveAll(repoDir) }
+}
+
+func TestBlueprintContext_RunBlueprintTests(t *testing.T) {
+	repoDir, cleanup := writeTestCaseRepo(t)
+	defer cleanup()
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+
+	t.Run("should run all test cases of blueprints with tests", func(t *testing.T) {
+		workDir, _ := os.Getwd()
+		results, err := blueprintContext.RunBlueprintTests()
+		require.Nil(t, err)
+		currentDir, _ := os.Getwd()
+		assert.Equal(t, workDir, currentDir)
+		require.Len(t, results, 3)
+
+		assert.Equal(t, "tested", results[0].Blueprint)
+		assert.Equal(t, "test-case-1.yaml", results[0].TestCase)
+		assert.True(t, results[0].Passed())
+
+		assert.Equal(t, "test-case-2.yaml", results[1].TestCase)
+		assert.False(t, results[1].Passed())
+		assert.Empty(t, results[1].Error)
+		assert.Equal(t, []string{
+			"expected file [pipeline.yaml] was not generated",
+			"file [xebialabs.yaml] was generated but was not expected",
+			"expected value [my-app] for key [AppName] in values.xlvals, got [other-app]",
+			"expected key [Missing] is missing in values.xlvals",
+		}, results[1].Failures)
+
+		assert.Equal(t, "test-case-3.yaml", results[2].TestCase)
+		assert.False(t, results[2].Passed())
+		assert.NotEmpty(t, results[2].Error)
+	})
+
+	t.Run("should error on unknown blueprint", func(t *testing.T) {
+		_, err := blueprintContext.RunBlueprintTests("not-there")
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint [not-there] not found in repository cmd-arg", err.Error())
+	})
+
+	t.Run("should return no results for blueprint without tests", func(t *testing.T) {
+		results, err := blueprintContext.RunBlueprintTests("untested")
+		require.Nil(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	t.Run("should write one test suite per blueprint", func(t *testing.T) {
+		results := []BlueprintTestResult{
+			{Blueprint: "a", TestCase: "test-case-1.yaml", Duration: 1500 * time.Millisecond},
+			{Blueprint: "a", TestCase: "test-case-2.yaml", Duration: time.Second, Failures: []string{"first", "second"}},
+			{Blueprint: "b", TestCase: "test-case-1.yaml", Error: "boom"},
+		}
+		out := &bytes.Buffer{}
+		require.Nil(t, WriteJUnitReport(out, results))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" errors="1" failures="1">
+  <testsuite name="a" tests="2" errors="0" failures="1" time="2.500">
+    <testcase name="test-case-1.yaml" classname="a" time="1.500"></testcase>
+    <testcase name="test-case-2.yaml" classname="a" time="1.000">
+      <failure message="first">first&#xA;second</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="b" tests="1" errors="1" failures="0" time="0.000">
+    <testcase name="test-case-1.yaml" classname="b" time="0.000">
+      <error message="boom">boom</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, out.String())
+	})
+}
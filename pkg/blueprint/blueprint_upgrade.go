@@ -0,0 +1,383 @@
+package blueprint
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/magiconair/properties"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Statuses of the project files after an upgrade
+const (
+	UpgradeStatusUnchanged = "unchanged"
+	UpgradeStatusUpdated   = "updated"
+	UpgradeStatusMerged    = "merged"
+	UpgradeStatusConflict  = "conflict"
+	UpgradeStatusAdded     = "added"
+	UpgradeStatusRemoved   = "removed"
+	UpgradeStatusKept      = "kept"
+)
+
+const (
+	// generated file contents are kept under this directory as the base for later upgrades
+	baseDir = ".blueprint/base"
+	// keeps the base copies out of GIT, upgrades fall back to the manifest checksums without them
+	baseGitignoreFile = ".blueprint/.gitignore"
+)
+
+// Labels of the conflict markers written in upgraded files
+const (
+	upgradeLabelCurrent   = "current"
+	upgradeLabelBlueprint = "blueprint"
+)
+
+// BlueprintUpgrade is the outcome of upgrading a project to the current version of its blueprint
+type BlueprintUpgrade struct {
+	Blueprint   string         `json:"blueprint" yaml:"blueprint"`
+	FromVersion string         `json:"fromVersion,omitempty" yaml:"fromVersion,omitempty"`
+	ToVersion   string         `json:"toVersion,omitempty" yaml:"toVersion,omitempty"`
+	Files       []UpgradedFile `json:"files" yaml:"files"`
+}
+
+// UpgradedFile is a project file along with what the upgrade did to it
+type UpgradedFile struct {
+	Path   string `json:"path" yaml:"path"`
+	Status string `json:"status" yaml:"status"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// HasConflicts returns true when at least one of the upgraded files has conflict markers
+func (upgrade *BlueprintUpgrade) HasConflicts() bool {
+	for _, file := range upgrade.Files {
+		if file.Status == UpgradeStatusConflict {
+			return true
+		}
+	}
+	return false
+}
+
+// UpgradeBlueprint generates the blueprint recorded in the manifest of the project directory again, using the current
+// version of the blueprint and the recorded answers, so only parameters without an answer are asked.
+// Each generated file is three-way merged with the previously generated version and the version on disk.
+// In non-interactive mode nothing is asked, parameters without an answer are reported at once
+func (blueprintContext *BlueprintContext) UpgradeBlueprint(projectDir string, nonInteractive bool, overrideFns ExpressionOverrideFn, surveyOpts ...survey.AskOpt) (*BlueprintUpgrade, error) {
+	oldManifest, err := ReadBlueprintManifest(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	s := newCLISession(surveyOpts...)
+	blueprintContext, err = blueprintContext.upgradeRepoContext(s, oldManifest, nonInteractive)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchDir, err := ioutil.TempDir("", "blueprint-upgrade")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratchDir)
+
+	answers, err := blueprintContext.getUpgradeAnswers(projectDir, oldManifest, scratchDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// generate the current version of the blueprint in a scratch directory
+	outputDir := filepath.Join(scratchDir, "output")
+	// without confirmation, and only printing what is generated in verbose mode
+	s.skipFinalPrompt, s.logger = true, verboseLogger{}
+	params := BlueprintParams{TemplatePath: oldManifest.Blueprint, AnswersMap: answers, OutputRoot: outputDir, NonInteractive: nonInteractive}
+	generatedBlueprint := &GeneratedBlueprint{OutputDir: models.BlueprintOutputDir}
+	_, _, err = instantiateBlueprint(s, params, blueprintContext, generatedBlueprint, overrideFns)
+	if err != nil {
+		return nil, err
+	}
+	newManifest, err := ReadBlueprintManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// merged files are staged and promoted along with the new base and manifest once all of them are merged,
+	// so that the project is left as it was when the upgrade fails or is interrupted
+	project := &GeneratedBlueprint{RootDir: projectDir, Logger: s.logger}
+	if err := project.BeginStaging(); err != nil {
+		return nil, err
+	}
+	defer project.Rollback()
+	if s.handleSignals {
+		stopInterruptOnSignal := project.interruptOnSignal()
+		defer stopInterruptOnSignal()
+	}
+
+	upgrade := &BlueprintUpgrade{Blueprint: oldManifest.Blueprint, FromVersion: oldManifest.Version, ToVersion: newManifest.Version}
+	oldChecksums := make(map[string]string)
+	for _, file := range oldManifest.Files {
+		oldChecksums[file.Path] = file.SHA256
+	}
+	newPaths := make(map[string]bool)
+	for _, file := range newManifest.Files {
+		newPaths[file.Path] = true
+		upgradedFile, err := upgradeFile(project, outputDir, file.Path, oldChecksums)
+		if err != nil {
+			return nil, err
+		}
+		upgrade.Files = append(upgrade.Files, *upgradedFile)
+	}
+	for _, file := range oldManifest.Files {
+		if !newPaths[file.Path] {
+			upgradedFile, err := removeUpgradedFile(project, file)
+			if err != nil {
+				return nil, err
+			}
+			upgrade.Files = append(upgrade.Files, *upgradedFile)
+		}
+	}
+	sort.Slice(upgrade.Files, func(i, j int) bool {
+		return upgrade.Files[i].Path < upgrade.Files[j].Path
+	})
+
+	// the newly generated files are the base for the next upgrade
+	oldBaseFiles, err := listUpgradeFiles(filepath.Join(projectDir, models.BlueprintOutputDir), baseDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range oldBaseFiles {
+		project.stageRemoval(filepath.Join(models.BlueprintOutputDir, file))
+	}
+	for _, relPath := range []string{baseDir, baseGitignoreFile, manifestFile} {
+		if err := copyUpgradeFiles(project, filepath.Join(outputDir, models.BlueprintOutputDir), relPath); err != nil {
+			return nil, err
+		}
+	}
+	if err := project.Commit(); err != nil {
+		return nil, err
+	}
+	return upgrade, nil
+}
+
+// writeUpgradeBase keeps a copy of the generated files, except the secrets file, as the base of the three-way merge
+// of a later upgrade
+func writeUpgradeBase(generatedBlueprint *GeneratedBlueprint, plannedFiles []PlannedFile) error {
+	for _, plannedFile := range plannedFiles {
+		if plannedFile.Action == PlanActionSkip || plannedFile.Path == filepath.Join(generatedBlueprint.OutputDir, secretsFile) {
+			continue
+		}
+		file, err := generatedBlueprint.GetOutputFile(filepath.Join(generatedBlueprint.OutputDir, baseDir, plannedFile.Path))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(file, plannedFile.Content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	ignoreAll := "*\n"
+	return writeDataToFile(generatedBlueprint, filepath.Join(generatedBlueprint.OutputDir, baseGitignoreFile), &ignoreAll)
+}
+
+// upgradeRepoContext returns the context to upgrade the blueprint from. The repository the blueprint was generated from
+// is used when it is defined, upgrading from the active repository instead has to be confirmed, which fails in non-interactive mode
+func (blueprintContext *BlueprintContext) upgradeRepoContext(s *session, manifest *BlueprintManifest, nonInteractive bool) (*BlueprintContext, error) {
+	if blueprintContext.ActiveRepo == nil || manifest.Repository.Name == "" {
+		return blueprintContext, nil
+	}
+	activeRepoName := (*blueprintContext.ActiveRepo).GetName()
+	if strings.EqualFold(manifest.Repository.Name, activeRepoName) {
+		return blueprintContext, nil
+	}
+	if repoContext, err := blueprintContext.WithActiveRepo(manifest.Repository.Name); err == nil {
+		s.logger.Info("Blueprint [%s] was generated from repository %s, upgrading from the same repository instead of %s\n", manifest.Blueprint, manifest.Repository.Name, activeRepoName)
+		return repoContext, nil
+	}
+
+	if nonInteractive {
+		return nil, fmt.Errorf("blueprint [%s] was generated from repository %s, which is not defined, upgrading from repository %s cannot be confirmed in non-interactive mode", manifest.Blueprint, manifest.Repository.Name, activeRepoName)
+	}
+	confirmed, err := s.confirm(fmt.Sprintf("Blueprint [%s] was generated from repository %s, which is not defined. Upgrade from repository %s?", manifest.Blueprint, manifest.Repository.Name, activeRepoName), false)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, fmt.Errorf("blueprint [%s] was generated from repository %s, which is not defined", manifest.Blueprint, manifest.Repository.Name)
+	}
+	return blueprintContext, nil
+}
+
+// getUpgradeAnswers returns the recorded answers along with the secrets from the project secrets file,
+// contents of secret files are written to the scratch directory as file type answers are file paths
+func (blueprintContext *BlueprintContext) getUpgradeAnswers(projectDir string, manifest *BlueprintManifest, scratchDir string) (map[string]string, error) {
+	answers := make(map[string]string)
+	for k, v := range manifest.Answers {
+		answers[k] = fmt.Sprintf("%v", v)
+	}
+
+	secretsPath := filepath.Join(projectDir, models.BlueprintOutputDir, secretsFile)
+	if !util.PathExists(secretsPath, false) {
+		return answers, nil
+	}
+	secrets, err := properties.LoadFile(secretsPath, properties.UTF8)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+	blueprintDocs, _, err := getBlueprintConfig(verboseLogger{}, blueprintContext, blueprints, manifest.Blueprint, []VarField{{}}, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, blueprintDoc := range blueprintDocs {
+		for _, variable := range blueprintDoc.BlueprintConfig.Variables {
+			secret, ok := secrets.Get(variable.Name.Value)
+			if !ok || !IsSecretType(variable.Type.Value) || util.MapContainsKeyWithVal(answers, variable.Name.Value) {
+				continue
+			}
+			if variable.Type.Value == TypeSecretFile {
+				secretFilePath := filepath.Join(scratchDir, "secret-"+variable.Name.Value)
+				if err := ioutil.WriteFile(secretFilePath, []byte(secret), 0600); err != nil {
+					return nil, err
+				}
+				secret = secretFilePath
+			}
+			answers[variable.Name.Value] = secret
+		}
+	}
+	return answers, nil
+}
+
+// upgradeFile stages the merge of the newly generated file into the project directory
+func upgradeFile(project *GeneratedBlueprint, outputDir string, filePath string, oldChecksums map[string]string) (*UpgradedFile, error) {
+	upgradedFile := &UpgradedFile{Path: filePath}
+	newContent, err := ioutil.ReadFile(filepath.Join(outputDir, filePath))
+	if err != nil {
+		return nil, err
+	}
+	currentPath := project.outputPath(filePath)
+	oldChecksum, generatedBefore := oldChecksums[filePath]
+
+	if !util.PathExists(currentPath, false) {
+		if generatedBefore {
+			upgradedFile.Status = UpgradeStatusKept
+			upgradedFile.Reason = "removed locally, not generated again"
+			return upgradedFile, nil
+		}
+		upgradedFile.Status = UpgradeStatusAdded
+		return upgradedFile, writeUpgradedFile(project, filePath, string(newContent))
+	}
+
+	currentContent, err := ioutil.ReadFile(currentPath)
+	if err != nil {
+		return nil, err
+	}
+	if string(currentContent) == string(newContent) {
+		upgradedFile.Status = UpgradeStatusUnchanged
+		return upgradedFile, nil
+	}
+
+	basePath := project.outputPath(filepath.Join(models.BlueprintOutputDir, baseDir, filePath))
+	var baseContent string
+	if util.PathExists(basePath, false) {
+		content, err := ioutil.ReadFile(basePath)
+		if err != nil {
+			return nil, err
+		}
+		baseContent = string(content)
+	} else {
+		checksum, err := fileChecksum(currentPath)
+		if err != nil {
+			return nil, err
+		}
+		if checksum == oldChecksum {
+			// not changed locally, so the file on disk is the previously generated version
+			baseContent = string(currentContent)
+		} else {
+			upgradedFile.Reason = "no previously generated version found"
+		}
+	}
+
+	if baseContent == string(currentContent) {
+		upgradedFile.Status = UpgradeStatusUpdated
+		return upgradedFile, writeUpgradedFile(project, filePath, string(newContent))
+	}
+	merged, hasConflicts := util.MergeText(baseContent, string(currentContent), string(newContent), upgradeLabelCurrent, upgradeLabelBlueprint)
+	upgradedFile.Status = UpgradeStatusMerged
+	if hasConflicts {
+		upgradedFile.Status = UpgradeStatusConflict
+	}
+	return upgradedFile, writeUpgradedFile(project, filePath, merged)
+}
+
+// removeUpgradedFile stages the removal of a file which is not generated by the blueprint anymore, unless it has local changes
+func removeUpgradedFile(project *GeneratedBlueprint, file ManifestFile) (*UpgradedFile, error) {
+	upgradedFile := &UpgradedFile{Path: file.Path, Status: UpgradeStatusRemoved}
+	currentPath := project.outputPath(file.Path)
+	if !util.PathExists(currentPath, false) {
+		return upgradedFile, nil
+	}
+	checksum, err := fileChecksum(currentPath)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != file.SHA256 {
+		upgradedFile.Status = UpgradeStatusKept
+		upgradedFile.Reason = "not generated anymore, kept as it has local changes"
+		return upgradedFile, nil
+	}
+	project.stageRemoval(file.Path)
+	return upgradedFile, nil
+}
+
+func writeUpgradedFile(project *GeneratedBlueprint, filePath string, content string) error {
+	return writeDataToFile(project, filePath, &content)
+}
+
+// listUpgradeFiles returns the paths, relative to the directory, of the files at relPath in the directory
+func listUpgradeFiles(dir string, relPath string) ([]string, error) {
+	var files []string
+	if !util.PathExists(filepath.Join(dir, relPath), false) {
+		return files, nil
+	}
+	err := filepath.Walk(filepath.Join(dir, relPath), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		filePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filePath)
+		return nil
+	})
+	return files, err
+}
+
+// copyUpgradeFiles stages copies of the files at relPath in the source directory into the xebialabs folder of the project
+func copyUpgradeFiles(project *GeneratedBlueprint, sourceDir string, relPath string) error {
+	files, err := listUpgradeFiles(sourceDir, relPath)
+	if err != nil {
+		return err
+	}
+	for _, filePath := range files {
+		content, err := ioutil.ReadFile(filepath.Join(sourceDir, filePath))
+		if err != nil {
+			return err
+		}
+		if err := writeUpgradedFile(project, filepath.Join(models.BlueprintOutputDir, filePath), string(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
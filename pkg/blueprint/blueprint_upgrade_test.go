@@ -0,0 +1,270 @@
+package blueprint
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository/local"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+)
+
+const upgradedBlueprintYamlV1 = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Upgraded
+  version: "1.0"
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+    saveInXlvals: true
+  - name: Password
+    type: SecretInput
+    prompt: Password?
+  files:
+  - path: app.yaml.tmpl
+  - path: notes.txt
+  - path: old.txt
+`
+
+const upgradedBlueprintYamlV2 = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Upgraded
+  version: "2.0"
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+    saveInXlvals: true
+  - name: Password
+    type: SecretInput
+    prompt: Password?
+  - name: Region
+    type: Input
+    value: eu-west-1
+  files:
+  - path: app.yaml.tmpl
+  - path: notes.txt
+  - path: new.txt
+`
+
+func writeUpgradeTestRepo(t *testing.T, repoDir string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(repoDir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0750))
+		require.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0640))
+	}
+}
+
+func TestBlueprintContext_UpgradeBlueprint(t *testing.T) {
+	SkipFinalPrompt = true
+	repoDir, err := ioutil.TempDir("", "upgraderepo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+	projectDir, err := ioutil.TempDir("", "upgradeproject")
+	require.Nil(t, err)
+	defer os.RemoveAll(projectDir)
+
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"upgraded/blueprint.yaml": upgradedBlueprintYamlV1,
+		"upgraded/app.yaml.tmpl":  "name: {{.AppName}}\nreplicas: 1\nimage: app\nport: 8080\n",
+		"upgraded/notes.txt":      "hello\n",
+		"upgraded/old.txt":        "old\n",
+	})
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+	_, _, err = InstantiateBlueprint(
+		BlueprintParams{
+			TemplatePath:  "upgraded",
+			AnswersMap:    map[string]string{"AppName": "my-app", "Password": "secret"},
+			StrictAnswers: true,
+			OutputRoot:    projectDir,
+		},
+		blueprintContext,
+		&GeneratedBlueprint{OutputDir: "xebialabs"},
+		nil,
+	)
+	require.Nil(t, err)
+	assert.FileExists(t, filepath.Join(projectDir, "xebialabs", baseDir, "app.yaml"))
+	assert.False(t, fileExists(filepath.Join(projectDir, "xebialabs", baseDir, "xebialabs", secretsFile)))
+	assert.Equal(t, "*\n", GetFileContent(filepath.Join(projectDir, "xebialabs", baseGitignoreFile)))
+
+	// local changes
+	writeUpgradeTestRepo(t, projectDir, map[string]string{
+		"app.yaml":  "name: my-app\nreplicas: 3\nimage: app\nport: 8080",
+		"notes.txt": "hello local\n",
+	})
+
+	// new version of the blueprint
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"upgraded/blueprint.yaml": upgradedBlueprintYamlV2,
+		"upgraded/app.yaml.tmpl":  "name: {{.AppName}}\nreplicas: 1\nimage: app\nport: 9090\nregion: {{.Region}}\n",
+		"upgraded/notes.txt":      "hello blueprint\n",
+		"upgraded/new.txt":        "new\n",
+	})
+	require.Nil(t, os.Remove(filepath.Join(repoDir, "upgraded", "old.txt")))
+
+	blueprintContext, err = ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+
+	t.Run("should leave the project as it was when the upgrade fails", func(t *testing.T) {
+		// a directory where the blueprint now generates a file cannot be merged
+		require.Nil(t, os.Mkdir(filepath.Join(projectDir, "new.txt"), 0750))
+		defer os.Remove(filepath.Join(projectDir, "new.txt"))
+
+		_, err := blueprintContext.UpgradeBlueprint(projectDir, false, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, "name: my-app\nreplicas: 3\nimage: app\nport: 8080", GetFileContent(filepath.Join(projectDir, "app.yaml")))
+		assert.True(t, fileExists(filepath.Join(projectDir, "old.txt")))
+		manifest, err := ReadBlueprintManifest(projectDir)
+		require.Nil(t, err)
+		assert.Equal(t, "1.0", manifest.Version)
+		assert.True(t, fileExists(filepath.Join(projectDir, "xebialabs", baseDir, "old.txt")))
+		entries, err := filepath.Glob(filepath.Join(projectDir, stagingDirPrefix+"*"))
+		require.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+
+	upgrade, err := blueprintContext.UpgradeBlueprint(projectDir, false, nil)
+	require.Nil(t, err)
+
+	t.Run("should report the upgraded files", func(t *testing.T) {
+		assert.Equal(t, "upgraded", upgrade.Blueprint)
+		assert.Equal(t, "1.0", upgrade.FromVersion)
+		assert.Equal(t, "2.0", upgrade.ToVersion)
+		assert.True(t, upgrade.HasConflicts())
+
+		statuses := make(map[string]string)
+		for _, file := range upgrade.Files {
+			statuses[file.Path] = file.Status
+		}
+		assert.Equal(t, map[string]string{
+			"app.yaml":                 UpgradeStatusMerged,
+			"notes.txt":                UpgradeStatusConflict,
+			"new.txt":                  UpgradeStatusAdded,
+			"old.txt":                  UpgradeStatusRemoved,
+			"xebialabs/.gitignore":     UpgradeStatusUnchanged,
+			"xebialabs/secrets.xlvals": UpgradeStatusUnchanged,
+			"xebialabs/values.xlvals":  UpgradeStatusUnchanged,
+		}, statuses)
+	})
+
+	t.Run("should merge local and blueprint changes", func(t *testing.T) {
+		assert.Equal(t, "name: my-app\nreplicas: 3\nimage: app\nport: 9090\nregion: eu-west-1", GetFileContent(filepath.Join(projectDir, "app.yaml")))
+		assert.Equal(t, "<<<<<<< current\nhello local\n=======\nhello blueprint\n>>>>>>> blueprint\n", GetFileContent(filepath.Join(projectDir, "notes.txt")))
+		assert.Equal(t, "new\n", GetFileContent(filepath.Join(projectDir, "new.txt")))
+		assert.False(t, fileExists(filepath.Join(projectDir, "old.txt")))
+	})
+
+	t.Run("should replace the manifest and the base files", func(t *testing.T) {
+		manifest, err := ReadBlueprintManifest(projectDir)
+		require.Nil(t, err)
+		assert.Equal(t, "2.0", manifest.Version)
+		assert.Equal(t, "new\n", GetFileContent(filepath.Join(projectDir, "xebialabs", baseDir, "new.txt")))
+		assert.False(t, fileExists(filepath.Join(projectDir, "xebialabs", baseDir, "old.txt")))
+		entries, err := filepath.Glob(filepath.Join(projectDir, stagingDirPrefix+"*"))
+		require.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestBlueprintContext_UpgradeBlueprint_NonInteractive(t *testing.T) {
+	SkipFinalPrompt = true
+	repoDir, err := ioutil.TempDir("", "upgraderepo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+	projectDir, err := ioutil.TempDir("", "upgradeproject")
+	require.Nil(t, err)
+	defer os.RemoveAll(projectDir)
+
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"upgraded/blueprint.yaml": "apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Upgraded\nspec:\n  files:\n  - path: notes.txt\n",
+		"upgraded/notes.txt":      "hello\n",
+	})
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+	_, _, err = InstantiateBlueprint(BlueprintParams{TemplatePath: "upgraded", OutputRoot: projectDir}, blueprintContext, &GeneratedBlueprint{OutputDir: "xebialabs"}, nil)
+	require.Nil(t, err)
+
+	// the new version has a parameter without answer
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"upgraded/blueprint.yaml": "apiVersion: xl/v2\nkind: Blueprint\nmetadata:\n  name: Upgraded\nspec:\n  parameters:\n  - name: Region\n    type: Input\n    prompt: Region?\n  files:\n  - path: notes.txt\n",
+	})
+	_, err = blueprintContext.UpgradeBlueprint(projectDir, true, nil)
+	require.NotNil(t, err)
+	var missingAnswersErr *MissingAnswersError
+	require.True(t, errors.As(err, &missingAnswersErr))
+	assert.Equal(t, []MissingAnswer{{Blueprint: "upgraded", Name: "Region", Prompt: "Region?", Type: TypeInput}}, missingAnswersErr.Answers)
+}
+
+func TestBlueprintContext_upgradeRepoContext(t *testing.T) {
+	var repos []*repository.BlueprintRepository
+	for _, name := range []string{"first", "second"} {
+		var repo repository.BlueprintRepository
+		repo, err := local.NewLocalBlueprintRepository(map[string]string{"type": models.ProviderLocal, "name": name, "path": "."})
+		require.Nil(t, err)
+		repos = append(repos, &repo)
+	}
+	blueprintContext := &BlueprintContext{ActiveRepo: repos[1], DefinedRepos: repos}
+	manifest := func(repoName string) *BlueprintManifest {
+		return &BlueprintManifest{Blueprint: "upgraded", Repository: ManifestRepository{Name: repoName, Provider: models.ProviderLocal}}
+	}
+
+	t.Run("should keep the active repository when the blueprint was generated from it", func(t *testing.T) {
+		s := &session{logger: discardLogger{}, answers: &testAnswerProvider{}}
+		repoContext, err := blueprintContext.upgradeRepoContext(s, manifest("second"), false)
+		require.Nil(t, err)
+		assert.Equal(t, blueprintContext, repoContext)
+	})
+
+	t.Run("should switch to the repository the blueprint was generated from", func(t *testing.T) {
+		answers := &testAnswerProvider{}
+		s := &session{logger: discardLogger{}, answers: answers}
+		repoContext, err := blueprintContext.upgradeRepoContext(s, manifest("first"), false)
+		require.Nil(t, err)
+		assert.Equal(t, "first", (*repoContext.ActiveRepo).GetName())
+		assert.Empty(t, answers.questions)
+	})
+
+	t.Run("should upgrade from the active repository when confirmed", func(t *testing.T) {
+		answers := &testAnswerProvider{answers: map[string]interface{}{"": true}}
+		s := &session{logger: discardLogger{}, answers: answers}
+		repoContext, err := blueprintContext.upgradeRepoContext(s, manifest("removed"), false)
+		require.Nil(t, err)
+		assert.Equal(t, "second", (*repoContext.ActiveRepo).GetName())
+		require.Len(t, answers.questions, 1)
+		assert.Equal(t, "Blueprint [upgraded] was generated from repository removed, which is not defined. Upgrade from repository second?", answers.questions[0].Message)
+	})
+
+	t.Run("should fail without asking in non-interactive mode", func(t *testing.T) {
+		answers := &testAnswerProvider{answers: map[string]interface{}{"": true}}
+		s := &session{logger: discardLogger{}, answers: answers}
+		_, err := blueprintContext.upgradeRepoContext(s, manifest("removed"), true)
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint [upgraded] was generated from repository removed, which is not defined, upgrading from repository second cannot be confirmed in non-interactive mode", err.Error())
+		assert.Empty(t, answers.questions)
+	})
+
+	t.Run("should fail when upgrading from the active repository is not confirmed", func(t *testing.T) {
+		s := &session{logger: discardLogger{}, answers: &testAnswerProvider{answers: map[string]interface{}{"": false}}}
+		_, err := blueprintContext.upgradeRepoContext(s, manifest("removed"), false)
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint [upgraded] was generated from repository removed, which is not defined", err.Error())
+	})
+}
+
+func fileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
@@ -0,0 +1,177 @@
+package blueprint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Validation problem severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationProblem is a single problem found while validating a blueprint
+type ValidationProblem struct {
+	Severity  string `json:"severity" yaml:"severity"`
+	Blueprint string `json:"blueprint" yaml:"blueprint"`
+	File      string `json:"file,omitempty" yaml:"file,omitempty"`
+	Message   string `json:"message" yaml:"message"`
+}
+
+// HasValidationErrors returns true when at least one of the problems is an error
+func HasValidationErrors(problems []ValidationProblem) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type blueprintValidator struct {
+	blueprintContext *BlueprintContext
+	blueprints       map[string]*models.BlueprintRemote
+	docs             map[string]*BlueprintConfig
+	validated        map[string]bool
+	problems         []ValidationProblem
+}
+
+// ValidateBlueprints validates the given blueprints of the active repository without generating any files,
+// all blueprints of the repository are validated when no template path is given.
+// Every problem found is returned, not only the first one
+func (blueprintContext *BlueprintContext) ValidateBlueprints(templatePaths ...string) ([]ValidationProblem, error) {
+	util.Verbose("[validate] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
+	blueprints, err := blueprintContext.initCurrentRepoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(templatePaths) == 0 {
+		for k := range blueprints {
+			templatePaths = append(templatePaths, k)
+		}
+		sort.Strings(templatePaths)
+	}
+
+	validator := &blueprintValidator{
+		blueprintContext: blueprintContext,
+		blueprints:       blueprints,
+		docs:             make(map[string]*BlueprintConfig),
+		validated:        make(map[string]bool),
+	}
+	for _, templatePath := range templatePaths {
+		validator.validateBlueprint(templatePath)
+	}
+	return validator.problems, nil
+}
+
+func (validator *blueprintValidator) addProblem(severity string, templatePath string, file string, format string, a ...interface{}) {
+	validator.problems = append(validator.problems, ValidationProblem{
+		Severity:  severity,
+		Blueprint: templatePath,
+		File:      file,
+		Message:   fmt.Sprintf(format, a...),
+	})
+}
+
+func (validator *blueprintValidator) errorCount() int {
+	count := 0
+	for _, problem := range validator.problems {
+		if problem.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+func (validator *blueprintValidator) validateBlueprint(templatePath string) {
+	if validator.validated[templatePath] {
+		return
+	}
+	validator.validated[templatePath] = true
+	util.Verbose("[validate] Validating blueprint %s\n", templatePath)
+
+	blueprint := validator.blueprints[templatePath]
+	if blueprint == nil {
+		validator.addProblem(SeverityError, templatePath, "", "blueprint [%s] not found in repository %s", templatePath, (*validator.blueprintContext.ActiveRepo).GetName())
+		return
+	}
+	definitionFile := blueprint.DefinitionFile.Path
+	errorsBefore := validator.errorCount()
+
+	ymlContent, err := validator.blueprintContext.fetchFileContents(definitionFile, false)
+	if err != nil {
+		validator.addProblem(SeverityError, templatePath, definitionFile, "%s", err.Error())
+		return
+	}
+	blueprintDoc, errs := parseTemplateMetadataErrors(ymlContent)
+	for _, err := range errs {
+		validator.addProblem(SeverityError, templatePath, definitionFile, "%s", err.Error())
+	}
+	if blueprintDoc == nil {
+		return
+	}
+	validator.docs[templatePath] = blueprintDoc
+
+	for _, config := range blueprintDoc.TemplateConfigs {
+		if !util.IsStringEmpty(config.Path) {
+			validator.validateTemplateFile(templatePath, config)
+		}
+	}
+
+	for _, included := range blueprintDoc.Include {
+		if validator.blueprints[included.Blueprint] == nil {
+			validator.addProblem(SeverityError, templatePath, definitionFile, "included blueprint [%s] not found in repository %s", included.Blueprint, (*validator.blueprintContext.ActiveRepo).GetName())
+			continue
+		}
+		validator.validateBlueprint(included.Blueprint)
+		validator.validateOverrides(templatePath, definitionFile, included)
+	}
+
+	// resolve the composed blueprint the same way as the generation does, problems already reported are not repeated
+	if validator.errorCount() == errorsBefore {
+		if _, _, err := getBlueprintConfig(cliLogger{}, validator.blueprintContext, validator.blueprints, templatePath, []VarField{{}}, ""); err != nil {
+			validator.addProblem(SeverityError, templatePath, definitionFile, "%s", err.Error())
+		}
+	}
+}
+
+// validateOverrides reports parameter and file overrides which don't match anything in the included blueprint
+func (validator *blueprintValidator) validateOverrides(templatePath string, definitionFile string, included IncludedBlueprintProcessed) {
+	includedDoc := validator.docs[included.Blueprint]
+	if includedDoc == nil {
+		return
+	}
+	for _, override := range included.ParameterOverrides {
+		if findParameter(includedDoc.Variables, override.Name) == -1 {
+			validator.addProblem(SeverityWarning, templatePath, definitionFile, "parameter override [%s] does not match any parameter of included blueprint [%s]", override.Name.Value, included.Blueprint)
+		}
+	}
+	for _, override := range included.FileOverrides {
+		if findTemplateConfig(includedDoc.TemplateConfigs, override.Path) == -1 {
+			validator.addProblem(SeverityWarning, templatePath, definitionFile, "file override [%s] does not match any file of included blueprint [%s]", override.Path, included.Blueprint)
+		}
+	}
+}
+
+// validateTemplateFile checks that the file exists and, for template files, that it can be parsed
+func (validator *blueprintValidator) validateTemplateFile(templatePath string, config TemplateConfig) {
+	fullPath := path.Join(templatePath, config.Path)
+	content, err := validator.blueprintContext.fetchFileContents(fullPath, strings.HasSuffix(config.Path, templateExtension))
+	if err != nil {
+		validator.addProblem(SeverityError, templatePath, fullPath, "%s", err.Error())
+		return
+	}
+	if strings.HasSuffix(config.Path, templateExtension) {
+		if _, err := template.New(config.Path).Funcs(getFuncMaps()).Parse(string(*content)); err != nil {
+			validator.addProblem(SeverityError, templatePath, fullPath, "%s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokenBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Broken
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+  - name: Region
+    type: Select
+    prompt: Region?
+  - name: Region
+    type: Input
+    prompt: Region again?
+  files:
+  - path: app.yaml.tmpl
+  - path: missing.yaml
+  includeAfter:
+  - blueprint: not-there
+  - blueprint: other
+    parameterOverrides:
+    - name: Unknown
+      value: test
+`
+
+const otherBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Other
+spec:
+  parameters:
+  - name: Name
+    type: Input
+    prompt: Name?
+`
+
+func writeValidateTestRepo(t *testing.T) (string, func()) {
+	repoDir, err := ioutil.TempDir("", "validaterepo")
+	require.Nil(t, err)
+	files := map[string]string{
+		"broken/blueprint.yaml":  brokenBlueprintYaml,
+		"broken/app.yaml.tmpl":   "name: {{.AppName | nosuchfunc}}\n",
+		"other/blueprint.yaml":   otherBlueprintYaml,
+		"valid/blueprint.yaml":   otherBlueprintYaml,
+		"valid/app.yaml.tmpl":    "name: {{.Name | kebabcase}}\n",
+		"invalid/blueprint.yaml": "apiVersion: xl/v2\nkind: Blueprint\nspec: [\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(repoDir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0750))
+		require.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0640))
+	}
+	return repoDir, func() { os.RemoveAll(repoDir) }
+}
+
+func TestBlueprintContext_ValidateBlueprints(t *testing.T) {
+	repoDir, cleanup := writeValidateTestRepo(t)
+	defer cleanup()
+	blueprintContext, err := ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+
+	t.Run("should not report problems for a valid blueprint", func(t *testing.T) {
+		problems, err := blueprintContext.ValidateBlueprints("valid")
+		require.Nil(t, err)
+		assert.Empty(t, problems)
+		assert.False(t, HasValidationErrors(problems))
+	})
+
+	t.Run("should report every problem of an invalid blueprint", func(t *testing.T) {
+		problems, err := blueprintContext.ValidateBlueprints("broken")
+		require.Nil(t, err)
+		assert.True(t, HasValidationErrors(problems))
+		require.Len(t, problems, 7)
+
+		definitionFile := "broken/blueprint.yaml"
+		assert.Equal(t, []ValidationProblem{
+			{SeverityError, "broken", definitionFile, "parameter AppName must have a 'prompt' field"},
+			{SeverityError, "broken", definitionFile, "at least one option field is need to be set for parameter [Region]"},
+			{SeverityError, "broken", definitionFile, "variable names must be unique within blueprint 'parameters' definition"},
+			{SeverityError, "broken", "broken/app.yaml.tmpl", `template: app.yaml.tmpl:1: function "nosuchfunc" not defined`},
+			{SeverityError, "broken", "broken/missing.yaml", problems[4].Message},
+			{SeverityError, "broken", definitionFile, "included blueprint [not-there] not found in repository cmd-arg"},
+			{SeverityWarning, "broken", definitionFile, "parameter override [Unknown] does not match any parameter of included blueprint [other]"},
+		}, problems)
+	})
+
+	t.Run("should report unparsable definition and validate all blueprints when none is given", func(t *testing.T) {
+		problems, err := blueprintContext.ValidateBlueprints()
+		require.Nil(t, err)
+		var blueprints []string
+		for _, problem := range problems {
+			blueprints = append(blueprints, problem.Blueprint)
+		}
+		assert.Contains(t, blueprints, "broken")
+		assert.Contains(t, blueprints, "invalid")
+		assert.NotContains(t, blueprints, "valid")
+		assert.NotContains(t, blueprints, "other")
+	})
+}
@@ -0,0 +1,143 @@
+package blueprint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/magiconair/properties"
+)
+
+// mergeConfig merges the config into the contents of an existing xlvals file. Existing lines are kept as they are,
+// only the lines of keys whose value changes are replaced and new keys are added sorted after the last key.
+// It returns the merged contents and the keys of which the value changed
+func mergeConfig(existingContent string, config map[string]interface{}) (string, []string, error) {
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadBytes([]byte(existingContent))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var keys []string
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changedKeys []string
+	replacedLines := make(map[string]string)
+	addedLines := &strings.Builder{}
+	for _, k := range keys {
+		line, newValue, err := renderConfigLine(k, config[k])
+		if err != nil {
+			return "", nil, err
+		}
+		if oldValue, exists := props.Get(k); !exists {
+			addedLines.WriteString(line)
+		} else if oldValue != newValue {
+			changedKeys = append(changedKeys, k)
+			replacedLines[k] = line
+		}
+	}
+
+	entries := propertiesEntries(existingContent)
+	entryKeys := make([]string, len(entries))
+	lastKeyEntry := -1
+	for i, entry := range entries {
+		if isPropertiesCommentOrBlank(entry) {
+			continue
+		}
+		entryProps, err := loader.LoadBytes([]byte(entry))
+		if err != nil {
+			return "", nil, err
+		}
+		if entryProps.Len() > 0 {
+			entryKeys[i] = entryProps.Keys()[0]
+			lastKeyEntry = i
+		}
+	}
+
+	sb := &strings.Builder{}
+	for i, entry := range entries {
+		if line, ok := replacedLines[entryKeys[i]]; ok && entryKeys[i] != "" {
+			sb.WriteString(line)
+		} else {
+			sb.WriteString(entry + "\n")
+		}
+		// comments after the last key are not attached to any key, they stay after the added keys
+		if i == lastKeyEntry {
+			sb.WriteString(addedLines.String())
+		}
+	}
+	if lastKeyEntry == -1 {
+		sb.WriteString(addedLines.String())
+	}
+	return sb.String(), changedKeys, nil
+}
+
+// renderConfigLine renders a single key of the config as a properties line, along with its value as read back
+func renderConfigLine(key string, value interface{}) (string, string, error) {
+	props := properties.NewProperties()
+	props.DisableExpansion = true
+	if err := props.SetValue(key, value); err != nil {
+		return "", "", err
+	}
+	sb := &strings.Builder{}
+	if _, err := props.Write(sb, properties.UTF8); err != nil {
+		return "", "", err
+	}
+	renderedValue, _ := props.Get(key)
+	return sb.String(), renderedValue, nil
+}
+
+// propertiesEntries splits the contents of a properties file into logical lines,
+// a line ending with an unescaped backslash continues on the next line unless it is a comment
+func propertiesEntries(content string) []string {
+	if content == "" {
+		return nil
+	}
+	var entries []string
+	var current []string
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		current = append(current, line)
+		trimmed := strings.TrimRight(line, "\r")
+		trailingBackslashes := len(trimmed) - len(strings.TrimRight(trimmed, "\\"))
+		if trailingBackslashes%2 == 1 && !isPropertiesCommentOrBlank(current[0]) {
+			continue
+		}
+		entries = append(entries, strings.Join(current, "\n"))
+		current = nil
+	}
+	if len(current) > 0 {
+		entries = append(entries, strings.Join(current, "\n"))
+	}
+	return entries
+}
+
+func isPropertiesCommentOrBlank(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")
+}
+
+// mergeGitignore appends the lines that are missing from the existing .gitignore contents
+func mergeGitignore(existingContent string, lines ...string) string {
+	existingLines := make(map[string]bool)
+	for _, line := range strings.Split(existingContent, "\n") {
+		existingLines[strings.TrimSpace(line)] = true
+	}
+
+	merged := existingContent
+	for _, line := range lines {
+		if !existingLines[line] {
+			merged = withTrailingNewline(merged) + line + "\n"
+			existingLines[line] = true
+		}
+	}
+	return merged
+}
+
+func withTrailingNewline(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		return content + "\n"
+	}
+	return content
+}
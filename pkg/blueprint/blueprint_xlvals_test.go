@@ -0,0 +1,94 @@
+package blueprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		config      map[string]interface{}
+		want        string
+		wantChanged []string
+	}{
+		{
+			"should add keys to file without keys",
+			"# header\n",
+			map[string]interface{}{"b": 2, "a": "one"},
+			"# header\na = one\nb = 2\n",
+			nil,
+		},
+		{
+			"should keep existing keys and comments and report changed values",
+			"# header\nname = app\n\n# the region\nregion = us-east-1\nunchanged = ${not.expanded}\n# trailing comment\n",
+			map[string]interface{}{"region": "eu-west-1", "unchanged": "${not.expanded}", "added": true},
+			"# header\nname = app\n\n# the region\nregion = eu-west-1\nunchanged = ${not.expanded}\nadded = true\n# trailing comment\n",
+			[]string{"region"},
+		},
+		{
+			"should keep existing lines verbatim",
+			"#no space\nname=app\n\n\n!bang comment\nlong = first \\\n  second\nregion: us-east-1\n\n",
+			map[string]interface{}{"name": "app", "long": "first second", "region": "eu-west-1", "added": 1},
+			"#no space\nname=app\n\n\n!bang comment\nlong = first \\\n  second\nregion = eu-west-1\nadded = 1\n\n",
+			[]string{"region"},
+		},
+		{
+			"should replace continued lines of a changed key",
+			"long = first \\\n  second\n#after\n",
+			map[string]interface{}{"long": "changed"},
+			"long = changed\n#after\n",
+			[]string{"long"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := mergeConfig(tt.existing, tt.config)
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantChanged, changed)
+		})
+	}
+}
+
+func TestMergeGitignore(t *testing.T) {
+	assert.Equal(t, "node_modules\nsecrets.xlvals\n", mergeGitignore("node_modules", "secrets.xlvals"))
+	assert.Equal(t, "secrets.xlvals\n*.log\n", mergeGitignore("secrets.xlvals\n*.log\n", "secrets.xlvals"))
+	assert.Equal(t, "secrets.xlvals\n", mergeGitignore("", "secrets.xlvals"))
+}
+
+func TestInstantiateBlueprint_MergeXlvals(t *testing.T) {
+	SkipFinalPrompt = true
+	outputRoot, err := ioutil.TempDir("", "mergeXlvals")
+	require.Nil(t, err)
+	defer os.RemoveAll(outputRoot)
+
+	xebialabsDir := filepath.Join(outputRoot, "xebialabs")
+	require.Nil(t, os.MkdirAll(xebialabsDir, 0750))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(xebialabsDir, gitignoreFile), []byte("*.log\n"), 0640))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(xebialabsDir, valuesFile), []byte("# from another blueprint\nOther = value\nTestFoo = old\n"), 0640))
+
+	gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+	_, _, err = InstantiateBlueprint(
+		BlueprintParams{
+			TemplatePath:       "composed",
+			UseDefaultsAsValue: true,
+			OutputRoot:         outputRoot,
+			OnConflict:         OnConflictFail,
+		},
+		getLocalTestBlueprintContext(t),
+		gb, nil,
+	)
+	require.Nil(t, err)
+
+	assert.Equal(t, "*.log\nsecrets.xlvals\n", GetFileContent(filepath.Join(xebialabsDir, gitignoreFile)))
+	values := GetFileContent(filepath.Join(xebialabsDir, valuesFile))
+	assert.Contains(t, values, "# from another blueprint\nOther = value\nTestFoo = hello\n")
+	assert.Contains(t, values, "TestCompose = hello\n")
+}
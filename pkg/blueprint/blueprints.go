@@ -2,16 +2,16 @@ package blueprint
 
 import (
     "fmt"
+    "io"
+    "os"
     "github.com/xebialabs/yaml"
     "path"
-    "path/filepath"
     "sort"
     "strings"
 
     "text/template"
 
     "github.com/fatih/color"
-    funk "github.com/thoas/go-funk"
 
     "github.com/magiconair/properties"
     "github.com/xebialabs/blueprint-cli/pkg/models"
@@ -30,6 +30,9 @@ var SkipUpFinalPrompt = false
 // SkipUserInput is used in tests to skip the user input
 var SkipUserInput = false
 
+// DefaultAnswersEnvPrefix is the prefix of the environment variables holding answers in the CLI, as in XL_BP_ANSWER_AppName=my-app
+const DefaultAnswersEnvPrefix = "XL_BP_ANSWER_"
+
 const (
     valuesFile           = "values.xlvals"
     valuesFileHeader     = "# This file includes all non-secret values, you can add variables here and then refer them with '!value' tag in YAML files"
@@ -54,9 +57,9 @@ func getFuncMaps() template.FuncMap {
     return funcMaps
 }
 
-func shouldSkipFile(templateConfig TemplateConfig, parameters map[string]interface{}) (bool, error) {
+func shouldSkipFile(logger Logger, templateConfig TemplateConfig, parameters map[string]interface{}) (bool, error) {
     if !util.IsStringEmpty(templateConfig.DependsOn.Value) {
-        dependsOnVal, err := ParseDependsOnValue(templateConfig.DependsOn, parameters)
+        dependsOnVal, err := parseDependsOnValue(logger, templateConfig.DependsOn, parameters)
         if err != nil {
             return false, err
         }
@@ -69,8 +72,12 @@ func shouldSkipFile(templateConfig TemplateConfig, parameters map[string]interfa
 }
 
 func (config *TemplateConfig) ProcessExpression(parameters map[string]interface{}, overrideFns ExpressionOverrideFn) error {
+    return config.processExpression(cliLogger{}, parameters, overrideFns)
+}
+
+func (config *TemplateConfig) processExpression(logger Logger, parameters map[string]interface{}, overrideFns ExpressionOverrideFn) error {
     fieldsToSkip := []string{""} // these fields have special processing
-    return ProcessExpressionField(config, fieldsToSkip, parameters, config.Path, overrideFns)
+    return processExpressionField(logger, config, fieldsToSkip, parameters, config.Path, overrideFns)
 }
 
 type BlueprintParams struct {
@@ -83,152 +90,193 @@ type BlueprintParams struct {
     ExistingPreparedData *PreparedData
     OverrideDefaults     map[string]string
     AnswersMap           map[string]string
+    DryRun               bool
+    NonInteractive       bool
+    AnswersEnvPrefix     string
+    OutputRoot           string
+    OnConflict           string
 }
 
-// InstantiateBlueprint is entry point for the cli command
-func InstantiateBlueprint(
-    params BlueprintParams,
+// prepareBlueprint resolves the blueprint to use and prepares the data of it and of its composed blueprints,
+// asking the user for the parameters that are not answered yet
+func prepareBlueprint(
+    s *session,
+    params *BlueprintParams,
     blueprintContext *BlueprintContext,
     generatedBlueprint *GeneratedBlueprint,
     overrideFns ExpressionOverrideFn,
-    surveyOpts ...survey.AskOpt,
-) (*PreparedData, *BlueprintConfig, error) {
+) (*PreparedData, *BlueprintConfig, []string, error) {
     var err error
     var blueprints map[string]*models.BlueprintRemote
 
+    // generate all files under the output root directory if given
+    if params.OutputRoot != "" {
+        generatedBlueprint.RootDir = params.OutputRoot
+    }
+
     // initialize repository client
-    util.Verbose("[cmd] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
-    blueprints, err = blueprintContext.initCurrentRepoClient()
+    s.logger.Verbose("[cmd] Reading blueprints from provider: %s\n", (*blueprintContext.ActiveRepo).GetProvider())
+    blueprints, err = s.repositoryTree(blueprintContext)
     if err != nil {
-        return nil, nil, err
+        return nil, nil, nil, err
     }
 
     // if template path is not defined in cmd, get user selection
     if params.TemplatePath == "" {
-        params.TemplatePath, err = blueprintContext.askUserToChooseBlueprint(blueprints, params.TemplatePath, surveyOpts...)
+        if params.NonInteractive {
+            return nil, nil, nil, errNoBlueprintInNonInteractiveMode
+        }
+        params.TemplatePath, err = blueprintContext.askUserToChooseBlueprint(s.answers, blueprints, params.TemplatePath)
         if err != nil {
-            return nil, nil, err
+            return nil, nil, nil, err
         }
     }
 
-    params.OverrideDefaults, err = getBlueprintDefaults(params.TemplatePath, overrideDefaultsFile, params.OverrideDefaults, blueprintContext)
+    params.OverrideDefaults, err = getBlueprintDefaults(s.logger, params.TemplatePath, overrideDefaultsFile, params.OverrideDefaults, blueprintContext)
     if err != nil {
+        return nil, nil, nil, err
+    }
+
+    return prepareMergedTemplateData(s, blueprintContext, blueprints, *params, overrideFns)
+}
+
+// InstantiateBlueprint is entry point for the cli command, questions are asked on the console and messages are printed to stdout.
+// Use an Engine to instantiate blueprints from a library
+func InstantiateBlueprint(
+    params BlueprintParams,
+    blueprintContext *BlueprintContext,
+    generatedBlueprint *GeneratedBlueprint,
+    overrideFns ExpressionOverrideFn,
+    surveyOpts ...survey.AskOpt,
+) (*PreparedData, *BlueprintConfig, error) {
+    return instantiateBlueprint(newCLISession(surveyOpts...), params, blueprintContext, generatedBlueprint, overrideFns)
+}
+
+func instantiateBlueprint(
+    s *session,
+    params BlueprintParams,
+    blueprintContext *BlueprintContext,
+    generatedBlueprint *GeneratedBlueprint,
+    overrideFns ExpressionOverrideFn,
+) (*PreparedData, *BlueprintConfig, error) {
+    if generatedBlueprint.Logger == nil {
+        generatedBlueprint.Logger = s.logger
+    }
+    if err := ValidateOnConflictPolicy(params.OnConflict); err != nil {
         return nil, nil, err
     }
 
-    preparedData, blueprintDoc, err := prepareMergedTemplateData(blueprintContext, blueprints, params, overrideFns, surveyOpts...)
+    preparedData, blueprintDoc, composedBlueprints, err := prepareBlueprint(s, &params, blueprintContext, generatedBlueprint, overrideFns)
     if err != nil {
         return nil, nil, err
     }
-    util.Verbose("[dataPrep] Prepared data: %#v\n", preparedData)
+    s.logger.Verbose("[dataPrep] Prepared data: %#v\n", preparedData)
 
     // Final prompt from user to start generation process
     toContinue := true
     toSaveFiles := true
 
     // if this is from UP command, ask confirmation for xl-up
-    if params.FromUpCommand && params.PrintSummaryTable && !SkipUpFinalPrompt {
+    if params.FromUpCommand && params.PrintSummaryTable && !s.skipUpFinalPrompt && !params.NonInteractive {
 
-        err := survey.AskOne(&survey.Confirm{Message: models.UpFinalPrompt, Default: true}, &toContinue, surveyOpts...)
+        toContinue, err = s.confirm(models.UpFinalPrompt, true)
 
         if err != nil {
             return nil, nil, err
         }
 
         if !toContinue && toSaveFiles {
-            isQuiet := util.IsQuiet
-            util.IsQuiet = false
-            util.Info("%s", util.Green("Generating all files and exiting...\n"))
-            util.IsQuiet = isQuiet
+            fmt.Fprint(s.out, util.Green("Generating all files and exiting...\n"))
         }
     }
 
     if toSaveFiles {
-        createXebiaLabsFolder := !blueprintDoc.Metadata.SuppressXebiaLabsFolder
+        plannedFiles, err := planBlueprintFiles(blueprintContext, blueprintDoc, preparedData, generatedBlueprint, overrideFns)
+        if err != nil {
+            return nil, nil, err
+        }
 
-        // save prepared data to values & secrets files
-        if createXebiaLabsFolder || len(preparedData.Values) != 0 {
-            err = writeConfigToFile(valuesFileHeader, preparedData.Values, generatedBlueprint, filepath.Join(generatedBlueprint.OutputDir, valuesFile))
+        // only report what would be done in dry-run mode
+        if params.DryRun {
+            fmt.Fprint(s.out, "Dry run, no files are written. Planned blueprint output files:\n\n")
+            err = writeBlueprintPlan(s.out, plannedFiles)
             if err != nil {
                 return nil, nil, err
             }
+            return preparedData, blueprintDoc, nil
         }
 
-        if createXebiaLabsFolder || len(preparedData.Secrets) != 0 {
-            err = writeConfigToFile(secretsFileHeader, preparedData.Secrets, generatedBlueprint, filepath.Join(generatedBlueprint.OutputDir, secretsFile))
-            if err != nil {
-                return nil, nil, err
+        // write all files into a staging directory first and promote them into the output tree once all are written,
+        // nothing is left behind when the generation fails or is interrupted before that
+        err = generatedBlueprint.BeginStaging()
+        if err != nil {
+            return nil, nil, err
+        }
+        defer generatedBlueprint.Rollback()
+        stopInterruptOnSignal := func() os.Signal { return nil }
+        if s.handleSignals {
+            stopInterruptOnSignal = generatedBlueprint.interruptOnSignal()
+            defer stopInterruptOnSignal()
+        }
+
+        // existing files cannot be prompted for in non-interactive mode
+        onConflict := params.OnConflict
+        if params.NonInteractive && onConflict == OnConflictPrompt {
+            onConflict = OnConflictFail
+        }
+        for _, plannedFile := range plannedFiles {
+            if plannedFile.Action == PlanActionSkip {
+                continue
             }
-            // generate .gitignore file
-            gitignoreData := secretsFile
-            err = writeDataToFile(generatedBlueprint, filepath.Join(generatedBlueprint.OutputDir, gitignoreFile), &gitignoreData)
+            // merged files already keep what is on disk, other existing files are handled by the conflict policy
+            if plannedFile.Action == PlanActionMerge {
+                s.logger.Info("[file] Merging into existing file '%s': %s\n", generatedBlueprint.outputPath(plannedFile.Path), plannedFile.Reason)
+            } else {
+                toWrite, err := resolveOutputConflict(s, generatedBlueprint, plannedFile, onConflict)
+                if err != nil {
+                    return nil, nil, err
+                }
+                if !toWrite {
+                    continue
+                }
+            }
+            err = writeDataToFile(generatedBlueprint, plannedFile.Path, &plannedFile.Content)
             if err != nil {
                 return nil, nil, err
             }
         }
 
-        // execute each template file found
-        for _, config := range blueprintDoc.TemplateConfigs {
-            config.ProcessExpression(preparedData.TemplateData, overrideFns)
-            skipFile, err := shouldSkipFile(config, preparedData.TemplateData)
+        // record what was generated and how, unless there is no xebialabs folder to keep it in
+        // or the files are generated for the up command, which is not upgraded
+        if !blueprintDoc.Metadata.SuppressXebiaLabsFolder && !params.FromUpCommand {
+            manifest, err := newBlueprintManifest(blueprintContext, params.TemplatePath, blueprintDoc, composedBlueprints, preparedData, generatedBlueprint)
             if err != nil {
                 return nil, nil, err
             }
-
-            if skipFile {
-                util.Verbose("[file] skipping file [%s] since it has writeIf value set or is skipped by composed blueprint\n", config.Path)
-                continue
-            }
-
-            // read template contents
-            util.Verbose("[file] Fetching template file %s from %s\n", config.Path, config.FullPath)
-            templateContent, err := blueprintContext.fetchFileContents(config.FullPath, strings.HasSuffix(config.Path, templateExtension))
+            err = writeBlueprintManifest(generatedBlueprint, manifest)
             if err != nil {
                 return nil, nil, err
             }
-            templateString := string(*templateContent)
-            finalFileName := config.Path
-            if config.RenameTo.Value != "" {
-                finalFileName = config.RenameTo.Value
-                util.Verbose("[file] Renaming template file %s to %s as it is overridden by composed blueprint\n", config.Path, finalFileName)
+            // the upgrade command merges local changes against these copies of what was generated
+            err = writeUpgradeBase(generatedBlueprint, plannedFiles)
+            if err != nil {
+                return nil, nil, err
             }
-
-            // process the template file (filter based on extension)
-            if strings.HasSuffix(config.Path, templateExtension) {
-                util.Verbose("[file] Processing template file %s\n", config.FullPath)
-
-                // read & process the template
-                tmpl := template.Must(template.New(config.Path).Funcs(getFuncMaps()).Parse(templateString))
-                processedTmpl := &strings.Builder{}
-                err = tmpl.Execute(processedTmpl, preparedData.TemplateData)
-                if err != nil {
-                    return nil, nil, err
-                }
-
-                // write the processed template to a file
-                finalTmpl := strings.TrimSpace(processedTmpl.String())
-
-                err = writeDataToFile(generatedBlueprint, strings.Replace(finalFileName, templateExtension, "", 1), &finalTmpl)
-                if err != nil {
-                    return nil, nil, err
-                }
-            } else {
-                if funk.ContainsString(ignoredPaths, filepath.Base(filepath.Dir(config.FullPath))) {
-                    // skip files under ignored directories
-                    util.Verbose("[file] Skipping file %s because path is under ignored list\n", config.FullPath)
-                } else {
-                    // handle non-template files - copy as-it-is
-                    util.Verbose("[file] Copying file %s\n", config.FullPath)
-                    err = writeDataToFile(generatedBlueprint, finalFileName, &templateString)
-                    if err != nil {
-                        return nil, nil, err
-                    }
-                }
+        }
+        err = generatedBlueprint.Commit()
+        if sig := stopInterruptOnSignal(); sig != nil {
+            if err != nil {
+                return nil, nil, fmt.Errorf("%s, no output files were written", err)
             }
+            // the files are committed, removing the new ones now would leave a partial scaffold behind
+            s.logger.Info("Blueprint generation was interrupted by %s after all output files were written\n", sig)
+        }
+        if err != nil {
+            return nil, nil, err
         }
-        util.Info("Please refer to file 'xebialabs/secrets.xlvals' for the default secrets\n")
+        s.logger.Info("Please refer to file '%s' for the default secrets\n", generatedBlueprint.outputPath(path.Join(models.BlueprintOutputDir, secretsFile)))
         if blueprintDoc.Metadata.Instructions != "" {
-            util.Info("\n\n%s\n\n", color.GreenString(blueprintDoc.Metadata.Instructions))
+            s.logger.Info("\n\n%s\n\n", color.GreenString(blueprintDoc.Metadata.Instructions))
         }
     }
 
@@ -240,16 +288,16 @@ func InstantiateBlueprint(
 }
 
 func prepareMergedTemplateData(
+    s *session,
     blueprintContext *BlueprintContext,
     blueprints map[string]*models.BlueprintRemote,
     params BlueprintParams,
     overrideFns ExpressionOverrideFn,
-    surveyOpts ...survey.AskOpt,
-) (*PreparedData, *BlueprintConfig, error) {
+) (*PreparedData, *BlueprintConfig, []string, error) {
     // get blueprint definition
-    blueprintDocs, masterBlueprintDoc, err := getBlueprintConfig(blueprintContext, blueprints, params.TemplatePath, []VarField{VarField{}}, "")
+    blueprintDocs, masterBlueprintDoc, err := getBlueprintConfig(s.logger, blueprintContext, blueprints, params.TemplatePath, []VarField{VarField{}}, "")
     if err != nil {
-        return nil, nil, err
+        return nil, nil, nil, err
     }
 
     mergedData := NewPreparedData()
@@ -259,6 +307,8 @@ func prepareMergedTemplateData(
         util.CopyIntoStringInterfaceMap(params.ExistingPreparedData.SummaryData, mergedData.SummaryData)
         util.CopyIntoStringInterfaceMap(params.ExistingPreparedData.Values, mergedData.Values)
         util.CopyIntoStringInterfaceMap(params.ExistingPreparedData.Secrets, mergedData.Secrets)
+        util.CopyIntoStringInterfaceMap(params.ExistingPreparedData.Answers, mergedData.Answers)
+        util.CopyIntoStringInterfaceMap(params.ExistingPreparedData.SecretAnswers, mergedData.SecretAnswers)
     }
     mergedBlueprintDoc := &BlueprintConfig{
         ApiVersion: masterBlueprintDoc.ApiVersion,
@@ -268,6 +318,8 @@ func prepareMergedTemplateData(
     }
     // A map holding skipped blueprint names
     var skippedBlueprints []string
+    // names of the composed blueprints that are not skipped
+    var composedBlueprints []string
     for _, blueprintDoc := range blueprintDocs {
         var ok = true
         // skip child templates when parents are skipped
@@ -279,16 +331,20 @@ func prepareMergedTemplateData(
         }
         if ok {
             // Evaluate dependsOn
-            ok, err = evaluateAndSkipIfDependsOnIsFalse(blueprintDoc.DependsOn, mergedData, overrideFns)
+            ok, err = evaluateAndSkipIfDependsOnIsFalse(s.logger, blueprintDoc.DependsOn, mergedData, overrideFns)
             if err != nil {
-                return nil, nil, err
+                return nil, nil, nil, err
             }
         }
         if ok {
             // ask for user input
-            preparedData, err := blueprintDoc.BlueprintConfig.prepareTemplateData(params, mergedData, overrideFns, surveyOpts...)
+            missingAnswers := len(s.missingAnswers)
+            preparedData, err := blueprintDoc.BlueprintConfig.prepareTemplateData(s, params, mergedData, overrideFns)
             if err != nil {
-                return nil, nil, err
+                return nil, nil, nil, err
+            }
+            for i := missingAnswers; i < len(s.missingAnswers); i++ {
+                s.missingAnswers[i].Blueprint = blueprintDoc.Name
             }
 
             // merge
@@ -296,6 +352,11 @@ func prepareMergedTemplateData(
             util.CopyIntoStringInterfaceMap(preparedData.SummaryData, mergedData.SummaryData)
             util.CopyIntoStringInterfaceMap(preparedData.Values, mergedData.Values)
             util.CopyIntoStringInterfaceMap(preparedData.Secrets, mergedData.Secrets)
+            util.CopyIntoStringInterfaceMap(preparedData.Answers, mergedData.Answers)
+            util.CopyIntoStringInterfaceMap(preparedData.SecretAnswers, mergedData.SecretAnswers)
+            if blueprintDoc.Name != params.TemplatePath {
+                composedBlueprints = append(composedBlueprints, blueprintDoc.Name)
+            }
             // append params
             mergedBlueprintDoc.Variables = append(mergedBlueprintDoc.Variables, blueprintDoc.BlueprintConfig.Variables...)
             // append files
@@ -305,42 +366,47 @@ func prepareMergedTemplateData(
         }
     }
 
+    // report all parameters without an answer at once
+    if len(s.missingAnswers) > 0 {
+        return nil, nil, nil, &MissingAnswersError{Answers: s.missingAnswers}
+    }
+
     // Print summary table
     if params.PrintSummaryTable {
-        // use util.Print so that this is not skipped in quiet mode
+        // written to the output so that this is not skipped in quiet mode
         if params.UseDefaultsAsValue && params.AnswersFile == "" && params.AnswersMap == nil {
-            util.Print("Using default values:\n")
+            fmt.Fprint(s.out, "Using default values:\n")
         }
 
-        util.Print("%s", util.DataMapTable(&mergedData.SummaryData, util.TableAlignLeft, 30, 50, "\t", 1, params.FromUpCommand))
+        fmt.Fprint(s.out, util.DataMapTable(&mergedData.SummaryData, util.TableAlignLeft, 30, 50, "\t", 1, params.FromUpCommand))
     }
 
-    if !SkipFinalPrompt {
+    // nothing is generated in dry-run mode, so there is nothing to confirm
+    if !s.skipFinalPrompt && !params.DryRun && !params.NonInteractive {
         // Final prompt from user to start generation process
-        toContinue := false
-        err := survey.AskOne(&survey.Confirm{Message: models.BlueprintFinalPrompt, Default: true}, &toContinue, surveyOpts...)
+        toContinue, err := s.confirm(models.BlueprintFinalPrompt, true)
 
         if err != nil {
-            return nil, nil, err
+            return nil, nil, nil, err
         }
         if !toContinue {
-            return nil, nil, fmt.Errorf("blueprint generation cancelled")
+            return nil, nil, nil, fmt.Errorf("blueprint generation cancelled")
         }
     }
 
-    return mergedData, mergedBlueprintDoc, nil
+    return mergedData, mergedBlueprintDoc, composedBlueprints, nil
 }
 
-func evaluateAndSkipIfDependsOnIsFalse(dependsOn []VarField, mergedData *PreparedData, overrideFns ExpressionOverrideFn) (bool, error) {
+func evaluateAndSkipIfDependsOnIsFalse(logger Logger, dependsOn []VarField, mergedData *PreparedData, overrideFns ExpressionOverrideFn) (bool, error) {
     for _, dependOn := range dependsOn {
-        procDependsOn, err := GetProcessedExpressionValue(dependOn, mergedData.TemplateData, overrideFns)
+        procDependsOn, err := getProcessedExpressionValue(logger, dependOn, mergedData.TemplateData, overrideFns)
         if err != nil {
             return false, err
         }
         if util.IsStringEmpty(procDependsOn.Value) {
             continue
         }
-        dependsOnVal, err := ParseDependsOnValue(procDependsOn, mergedData.TemplateData)
+        dependsOnVal, err := parseDependsOnValue(logger, procDependsOn, mergedData.TemplateData)
         if err != nil {
             return false, err
         }
@@ -352,22 +418,26 @@ func evaluateAndSkipIfDependsOnIsFalse(dependsOn []VarField, mergedData *Prepare
 }
 
 func getBlueprintConfig(
+    logger Logger,
     blueprintContext *BlueprintContext,
     blueprints map[string]*models.BlueprintRemote,
     templatePath string,
     dependsOn []VarField,
     parentBlueprint string,
 ) ([]*ComposedBlueprint, *BlueprintConfig, error) {
-    util.Verbose("[cmd] Parsing Blueprint from %s\n", templatePath)
+    logger.Verbose("[cmd] Parsing Blueprint from %s\n", templatePath)
     blueprintDocs := make([]*ComposedBlueprint, 0)
     blueprint := blueprints[templatePath]
     masterBlueprintDoc, err := blueprintContext.parseDefinitionFile(blueprint, templatePath)
     if err != nil {
         return nil, nil, err
     }
+    if masterBlueprintDoc.ApiVersion == models.BlueprintYamlFormatV1 {
+        logger.Info("This blueprint uses a deprecated blueprint.yaml schema for apiVersion %s\n", models.BlueprintYamlFormatV1)
+    }
 
-    util.Verbose("[compose] Found %d included blueprints\n", len(masterBlueprintDoc.Include))
-    blueprintDocs, err = composeBlueprints(templatePath, masterBlueprintDoc, blueprintContext, blueprints, dependsOn, parentBlueprint)
+    logger.Verbose("[compose] Found %d included blueprints\n", len(masterBlueprintDoc.Include))
+    blueprintDocs, err = composeBlueprints(logger, templatePath, masterBlueprintDoc, blueprintContext, blueprints, dependsOn, parentBlueprint)
     if err != nil {
         return nil, nil, err
     }
@@ -375,16 +445,17 @@ func getBlueprintConfig(
 }
 
 func getBlueprintDefaults(
+    logger Logger,
     templatePath string,
     defaultFile string,
     overrideDefaults map[string]string,
     blueprintContext *BlueprintContext,
 ) (map[string]string, error) {
-    util.Verbose("[defaults] Parsing Blueprint defaults from file %s\n", templatePath)
+    logger.Verbose("[defaults] Parsing Blueprint defaults from file %s\n", templatePath)
 
     contents, err := blueprintContext.fetchFileContents(path.Join(templatePath, defaultFile), false)
     if err != nil {
-        util.Verbose("[defaults] Using Blueprint defaults file skipped - no %s file\n", path.Join(templatePath, defaultFile))
+        logger.Verbose("[defaults] Using Blueprint defaults file skipped - no %s file\n", path.Join(templatePath, defaultFile))
         return overrideDefaults, nil
     }
 
@@ -399,12 +470,13 @@ func getBlueprintDefaults(
         overrideDefaultsFromFile[providedKey] = providedValue
     }
 
-    util.Verbose("[defaults] Using Blueprint defaults \n%+v\n", overrideDefaultsFromFile)
+    logger.Verbose("[defaults] Using Blueprint defaults \n%+v\n", overrideDefaultsFromFile)
 
     return overrideDefaultsFromFile, nil
 }
 
 func composeBlueprints(
+    logger Logger,
     blueprintName string,
     blueprintDoc *BlueprintConfig,
     blueprintContext *BlueprintContext,
@@ -416,7 +488,7 @@ func composeBlueprints(
     // add the master blueprint
     blueprintDocs = append(blueprintDocs, &ComposedBlueprint{blueprintName, blueprintDoc, dependsOn, parentBlueprint})
     for _, included := range blueprintDoc.Include {
-        util.Verbose("[compose] Fetch included blueprint %s\n", included.Blueprint)
+        logger.Verbose("[compose] Fetch included blueprint %s\n", included.Blueprint)
 
         // combine parent and child DependsOn fields into a single array
         dependencies := make([]VarField, 0)
@@ -436,7 +508,7 @@ func composeBlueprints(
         }
 
         // fetch blueprint from current repo
-        composedBlueprintDocs, currentBlueprintDoc, err := getBlueprintConfig(blueprintContext, blueprints, included.Blueprint, dependencies, blueprintName)
+        composedBlueprintDocs, currentBlueprintDoc, err := getBlueprintConfig(logger, blueprintContext, blueprints, included.Blueprint, dependencies, blueprintName)
         if err != nil {
             return nil, err
         }
@@ -446,7 +518,7 @@ func composeBlueprints(
                 if targetIndex != -1 {
                     util.MergeStructFields(&(currentBlueprintDoc.Variables[targetIndex]), &override, []string{"Name", "Type"})
                 } else {
-                    util.Verbose("[compose] Could not find parameterOverride for %s\n", override.Name.Value)
+                    logger.Verbose("[compose] Could not find parameterOverride for %s\n", override.Name.Value)
                 }
             }
         }
@@ -456,7 +528,7 @@ func composeBlueprints(
                 if targetIndex != -1 {
                     util.MergeStructFields(&(currentBlueprintDoc.TemplateConfigs[targetIndex]), &override, []string{"Path"})
                 } else {
-                    util.Verbose("[compose] Could not find fileOverride for %s\n", override.Path)
+                    logger.Verbose("[compose] Could not find fileOverride for %s\n", override.Path)
                 }
             }
         }
@@ -496,29 +568,57 @@ func findTemplateConfig(configs []TemplateConfig, path string) int {
 
 // --utility functions
 func writeDataToFile(generatedBlueprint *GeneratedBlueprint, outputFileName string, data *string) error {
-    util.Verbose("[file] Creating blueprint output file %s\n", outputFileName)
+    generatedBlueprint.log().Verbose("[file] Creating blueprint output file %s\n", outputFileName)
     file, err := generatedBlueprint.GetOutputFile(outputFileName)
     if err != nil {
         return err
     }
-    out, err := file.WriteString(*data)
+    out, err := io.WriteString(file, *data)
     if err != nil {
         return err
     }
-    util.Verbose("\tWrote %d bytes \n", out)
-    err = file.Sync()
-    if err != nil {
-        return err
+    generatedBlueprint.log().Verbose("\tWrote %d bytes \n", out)
+    // files on disk are synced, other output filesystems have nothing to sync
+    if syncer, ok := file.(interface{ Sync() error }); ok {
+        err = syncer.Sync()
+        if err != nil {
+            return err
+        }
     }
     err = file.Close()
     if err != nil {
         return err
     }
-    util.Info("[file] Blueprint output file '%s' generated successfully\n", outputFileName)
+    generatedBlueprint.log().Info("[file] Blueprint output file '%s' generated successfully\n", outputFileName)
     return nil
 }
 
+// writeConfigToFile writes the config as properties, merged into the existing file if there is one
 func writeConfigToFile(header string, config map[string]interface{}, generatedBlueprint *GeneratedBlueprint, filename string) error {
+    existingContent, err := readExistingOutputFile(generatedBlueprint, filename)
+    if err != nil {
+        return err
+    }
+    if existingContent == nil {
+        data, err := renderConfig(header, config)
+        if err != nil {
+            return err
+        }
+        return writeDataToFile(generatedBlueprint, filename, &data)
+    }
+
+    data, changedKeys, err := mergeConfig(*existingContent, config)
+    if err != nil {
+        return err
+    }
+    if len(changedKeys) > 0 {
+        generatedBlueprint.log().Info("[file] Changing value of %s in existing file '%s'\n", strings.Join(changedKeys, ", "), filename)
+    }
+    return writeDataToFile(generatedBlueprint, filename, &data)
+}
+
+// renderConfig renders the config as properties sorted by key, preceded by the header
+func renderConfig(header string, config map[string]interface{}) (string, error) {
     props := properties.NewProperties()
 
     // sort based on keys
@@ -530,25 +630,14 @@ func writeConfigToFile(header string, config map[string]interface{}, generatedBl
     for _, k := range keys {
         err := props.SetValue(k, config[k])
         if err != nil {
-            return err
+            return "", err
         }
     }
 
-    // write properties to file
-    f, err := generatedBlueprint.GetOutputFile(filename)
-    if err != nil {
-        return err
+    sb := &strings.Builder{}
+    sb.WriteString(header + "\n")
+    if _, err := props.Write(sb, properties.UTF8); err != nil {
+        return "", err
     }
-    defer f.Close()
-    bytesWrittenHeader, err := f.Write([]byte(header + "\n"))
-    if err != nil {
-        return err
-    }
-    bytesWrittenConfig, err := props.Write(f, properties.UTF8)
-    if err != nil {
-        return err
-    }
-    util.Verbose("\tWrote %d bytes \n", bytesWrittenHeader+bytesWrittenConfig)
-    util.Info("[file] Blueprint output file '%s' generated successfully\n", filename)
-    return nil
+    return sb.String(), nil
 }
@@ -94,6 +94,16 @@ func TestWriteConfigToFile(t *testing.T) {
 		assert.FileExists(t, filePath)
 		assert.Equal(t, "#comment\na = true\nd = 1\nz = test", strings.TrimSpace(GetFileContent(filePath)))
 	})
+	t.Run("should merge config data into existing output file", func(t *testing.T) {
+		filePath := "test.xlvals"
+		require.Nil(t, ioutil.WriteFile(filePath, []byte("#comment\n# about d\nd = 0\nb = kept\n"), 0640))
+		defer os.Remove(filePath)
+		gb := new(GeneratedBlueprint)
+		config := map[string]interface{}{"d": 1, "a": true}
+		err := writeConfigToFile("#comment", config, gb, filePath)
+		require.Nil(t, err)
+		assert.Equal(t, "#comment\n# about d\nd = 1\nb = kept\na = true", strings.TrimSpace(GetFileContent(filePath)))
+	})
 }
 
 func TestInstantiateBlueprint(t *testing.T) {
@@ -603,6 +613,8 @@ func TestInstantiateBlueprint(t *testing.T) {
 		assert.False(t, util.PathExists(path.Join(gb.OutputDir, valuesFile), false))
 		assert.False(t, util.PathExists(path.Join(gb.OutputDir, secretsFile), false))
 		assert.False(t, util.PathExists(path.Join(gb.OutputDir, gitignoreFile), false))
+		assert.False(t, util.PathExists(path.Join(gb.OutputDir, manifestFile), false))
+		assert.False(t, util.PathExists(path.Join(gb.OutputDir, ".blueprint"), true))
 
 		envFile := GetFileContent("xld-environment.yml")
 		assert.Contains(t, envFile, fmt.Sprintf("region: %s", "us-west"))
@@ -882,6 +894,34 @@ func TestInstantiateBlueprint(t *testing.T) {
 		assert.NotContains(t, secretsFileContent, "SuperSecret = invisible")
 
 	})
+
+	t.Run("should create output files under the output root directory", func(t *testing.T) {
+		outputRoot, err := ioutil.TempDir("", "outputRoot")
+		require.Nil(t, err)
+		defer os.RemoveAll(outputRoot)
+
+		gb := &GeneratedBlueprint{OutputDir: "xebialabs"}
+		defer gb.Cleanup()
+		_, _, err = InstantiateBlueprint(
+			BlueprintParams{
+				TemplatePath:       "composed",
+				UseDefaultsAsValue: true,
+				OutputRoot:         outputRoot,
+			},
+			getLocalTestBlueprintContext(t),
+			gb, nil,
+		)
+		require.Nil(t, err)
+
+		// assertions
+		assert.FileExists(t, path.Join(outputRoot, "xld-infrastructure.yml"))
+		assert.FileExists(t, path.Join(outputRoot, "xlr-pipeline-4.yml"))
+		assert.FileExists(t, path.Join(outputRoot, gb.OutputDir, valuesFile))
+		assert.FileExists(t, path.Join(outputRoot, gb.OutputDir, secretsFile))
+		assert.FileExists(t, path.Join(outputRoot, gb.OutputDir, gitignoreFile))
+		assert.False(t, util.PathExists("xld-infrastructure.yml", false))
+		assert.False(t, util.PathExists(gb.OutputDir, true))
+	})
 }
 
 func TestShouldSkipFile(t *testing.T) {
@@ -938,7 +978,7 @@ func TestShouldSkipFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := shouldSkipFile(tt.args.templateConfig, tt.args.params)
+			got, err := shouldSkipFile(cliLogger{}, tt.args.templateConfig, tt.args.params)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("shouldSkipFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1136,7 +1176,7 @@ func Test_getBlueprintConfig(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotArray, got, err := getBlueprintConfig(tt.args.blueprintContext, tt.args.blueprints, tt.args.templatePath, []VarField{tt.args.dependsOn}, tt.args.parentName)
+			gotArray, got, err := getBlueprintConfig(cliLogger{}, tt.args.blueprintContext, tt.args.blueprints, tt.args.templatePath, []VarField{tt.args.dependsOn}, tt.args.parentName)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getBlueprintConfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1549,7 +1589,7 @@ func Test_composeBlueprints(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := composeBlueprints(tt.args.blueprintName, tt.args.blueprintDoc, tt.args.blueprintContext, tt.args.blueprints, []VarField{tt.args.dependsOn}, tt.args.parentName)
+			got, err := composeBlueprints(cliLogger{}, tt.args.blueprintName, tt.args.blueprintDoc, tt.args.blueprintContext, tt.args.blueprints, []VarField{tt.args.dependsOn}, tt.args.parentName)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("composeBlueprints() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1635,7 +1675,7 @@ func Test_evaluateAndSkipIfDependsOnIsFalse(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := evaluateAndSkipIfDependsOnIsFalse([]VarField{tt.args.dependsOn}, tt.args.mergedData, nil)
+			got, err := evaluateAndSkipIfDependsOnIsFalse(cliLogger{}, []VarField{tt.args.dependsOn}, tt.args.mergedData, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("evaluateAndCheckDependsOnIsTrue() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1699,10 +1739,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 				[]survey.AskOpt{},
 			},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"Test": "testing"},
-				SummaryData:  map[string]interface{}{"Test": "testing"},
-				Secrets:      map[string]interface{}{},
-				Values:       map[string]interface{}{"Test": "testing"},
+				TemplateData:  map[string]interface{}{"Test": "testing"},
+				SummaryData:   map[string]interface{}{"Test": "testing"},
+				Secrets:       map[string]interface{}{},
+				Values:        map[string]interface{}{"Test": "testing"},
+				Answers:       map[string]interface{}{},
+				SecretAnswers: map[string]interface{}{},
 			},
 			&BlueprintConfig{
 				ApiVersion: "xl/v2",
@@ -1740,10 +1782,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 				[]survey.AskOpt{},
 			},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello", "Foo2": "hello2", "Test2": "hello2"},
-				SummaryData:  map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello", "Foo2": "hello2", "Test2": "hello2"},
-				Secrets:      map[string]interface{}{},
-				Values:       map[string]interface{}{"Test": "hello", "Test2": "hello2"},
+				TemplateData:  map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello", "Foo2": "hello2", "Test2": "hello2"},
+				SummaryData:   map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello", "Foo2": "hello2", "Test2": "hello2"},
+				Secrets:       map[string]interface{}{},
+				Values:        map[string]interface{}{"Test": "hello", "Test2": "hello2"},
+				Answers:       map[string]interface{}{},
+				SecretAnswers: map[string]interface{}{},
 			},
 			&BlueprintConfig{
 				ApiVersion: "xl/v2",
@@ -1818,10 +1862,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 				[]survey.AskOpt{},
 			},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello"},
-				SummaryData:  map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello"},
-				Secrets:      map[string]interface{}{},
-				Values:       map[string]interface{}{"Test": "hello"},
+				TemplateData:  map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello"},
+				SummaryData:   map[string]interface{}{"Bar": "testing", "Foo": "hello", "Test": "hello"},
+				Secrets:       map[string]interface{}{},
+				Values:        map[string]interface{}{"Test": "hello"},
+				Answers:       map[string]interface{}{},
+				SecretAnswers: map[string]interface{}{},
 			},
 			&BlueprintConfig{
 				ApiVersion: "xl/v2",
@@ -1896,10 +1942,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 				[]survey.AskOpt{},
 			},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"Bar": "testing"},
-				SummaryData:  map[string]interface{}{"Bar": "testing"},
-				Secrets:      map[string]interface{}{},
-				Values:       map[string]interface{}{},
+				TemplateData:  map[string]interface{}{"Bar": "testing"},
+				SummaryData:   map[string]interface{}{"Bar": "testing"},
+				Secrets:       map[string]interface{}{},
+				Values:        map[string]interface{}{},
+				Answers:       map[string]interface{}{},
+				SecretAnswers: map[string]interface{}{},
 			},
 			&BlueprintConfig{
 				ApiVersion: "xl/v2",
@@ -1972,10 +2020,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 				[]survey.AskOpt{},
 			},
 			&PreparedData{
-				TemplateData: map[string]interface{}{"Bar": "hello", "Test": "hello"},
-				SummaryData:  map[string]interface{}{"Bar": "hello", "Test": "hello"},
-				Secrets:      map[string]interface{}{},
-				Values:       map[string]interface{}{"Test": "hello"},
+				TemplateData:  map[string]interface{}{"Bar": "hello", "Test": "hello"},
+				SummaryData:   map[string]interface{}{"Bar": "hello", "Test": "hello"},
+				Secrets:       map[string]interface{}{},
+				Values:        map[string]interface{}{"Test": "hello"},
+				Answers:       map[string]interface{}{},
+				SecretAnswers: map[string]interface{}{},
 			},
 			&BlueprintConfig{
 				ApiVersion: "xl/v2",
@@ -2024,12 +2074,12 @@ func Test_prepareMergedTemplateData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, err := prepareMergedTemplateData(
+			got, got1, _, err := prepareMergedTemplateData(
+				newCLISession(tt.args.surveyOpts...),
 				tt.args.blueprintContext,
 				tt.args.blueprints,
 				tt.args.params,
 				nil,
-				tt.args.surveyOpts...,
 			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("prepareMergedTemplateData() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,255 @@
+package blueprint
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+// Logger receives the messages of a blueprint run, Verbose messages are only of interest when debugging
+type Logger interface {
+	Verbose(format string, a ...interface{})
+	Info(format string, a ...interface{})
+}
+
+// Question is a question asked for a blueprint parameter that has no answer yet, or a confirmation asked during the
+// blueprint run, in which case Name is empty. Type is one of the parameter types and the answer of a TypeConfirm question
+// is a bool, all other answers are strings. Validate, when set, returns an error for answers that are not valid
+type Question struct {
+	Name     string
+	Type     string
+	Message  string
+	Help     string
+	Default  interface{}
+	Options  []string
+	Validate func(answer interface{}) error
+}
+
+// AnswerProvider answers the questions of a blueprint run
+type AnswerProvider interface {
+	Ask(question Question) (interface{}, error)
+}
+
+// cliLogger prints the messages to stdout, following the verbose and quiet flags of the CLI
+type cliLogger struct{}
+
+func (cliLogger) Verbose(format string, a ...interface{}) {
+	util.Verbose(format, a...)
+}
+
+func (cliLogger) Info(format string, a ...interface{}) {
+	util.Info(format, a...)
+}
+
+// verboseLogger prints all messages to stdout in verbose mode only
+type verboseLogger struct{}
+
+func (verboseLogger) Verbose(format string, a ...interface{}) {
+	util.Verbose(format, a...)
+}
+
+func (verboseLogger) Info(format string, a ...interface{}) {
+	util.Verbose(format, a...)
+}
+
+// discardLogger drops all messages
+type discardLogger struct{}
+
+func (discardLogger) Verbose(format string, a ...interface{}) {}
+
+func (discardLogger) Info(format string, a ...interface{}) {}
+
+// surveyAnswerProvider asks the questions on the console
+type surveyAnswerProvider struct {
+	surveyOpts []survey.AskOpt
+}
+
+// NewSurveyAnswerProvider returns an AnswerProvider asking the questions on the console, as the CLI does
+func NewSurveyAnswerProvider(surveyOpts ...survey.AskOpt) AnswerProvider {
+	return &surveyAnswerProvider{surveyOpts: surveyOpts}
+}
+
+func (provider *surveyAnswerProvider) Ask(question Question) (interface{}, error) {
+	surveyOpts := provider.surveyOpts
+	if question.Validate != nil {
+		surveyOpts = append(surveyOpts[:len(surveyOpts):len(surveyOpts)], survey.WithValidator(question.Validate))
+	}
+	defaultStr := ""
+	if question.Default != nil {
+		defaultStr = fmt.Sprintf("%v", question.Default)
+	}
+
+	var answer string
+	var err error
+	switch question.Type {
+	case TypeConfirm:
+		confirm, _ := question.Default.(bool)
+		err = survey.AskOne(&survey.Confirm{Message: question.Message, Default: confirm, Help: question.Help}, &confirm, surveyOpts...)
+		return confirm, err
+	case TypeSecret:
+		err = survey.AskOne(&survey.Password{Message: question.Message, Help: question.Help}, &answer, surveyOpts...)
+	case TypeEditor, TypeSecretEditor:
+		err = survey.AskOne(
+			&survey.Editor{
+				Message:       question.Message,
+				Default:       defaultStr,
+				HideDefault:   true,
+				AppendDefault: true,
+				Help:          question.Help,
+			},
+			&answer,
+			surveyOpts...,
+		)
+	case TypeSelect:
+		err = survey.AskOne(
+			&survey.Select{
+				Message:  question.Message,
+				Options:  question.Options,
+				Default:  defaultStr,
+				PageSize: 10,
+				Help:     question.Help,
+			},
+			&answer,
+			surveyOpts...,
+		)
+	default:
+		err = survey.AskOne(&survey.Input{Message: question.Message, Default: defaultStr, Help: question.Help}, &answer, surveyOpts...)
+	}
+	return answer, err
+}
+
+// noAnswerProvider fails on every question
+type noAnswerProvider struct{}
+
+func (noAnswerProvider) Ask(question Question) (interface{}, error) {
+	if question.Name != "" {
+		return nil, fmt.Errorf("no answer given for parameter [%s]", question.Name)
+	}
+	return nil, fmt.Errorf("no answer given for question [%s]", question.Message)
+}
+
+// session holds everything a single blueprint run needs besides its parameters, so that runs do not share any state
+type session struct {
+	logger            Logger
+	answers           AnswerProvider
+	out               io.Writer
+	skipFinalPrompt   bool
+	skipUpFinalPrompt bool
+	skipUserInput     bool
+	handleSignals     bool
+	listBlueprints    func() (map[string]*models.BlueprintRemote, error)
+	missingAnswers    []MissingAnswer
+}
+
+// newCLISession returns a session asking the questions on the console and printing to stdout,
+// following the package level switches used by the CLI and its tests
+func newCLISession(surveyOpts ...survey.AskOpt) *session {
+	return &session{
+		logger:            cliLogger{},
+		answers:           NewSurveyAnswerProvider(surveyOpts...),
+		out:               os.Stdout,
+		skipFinalPrompt:   SkipFinalPrompt,
+		skipUpFinalPrompt: SkipUpFinalPrompt,
+		skipUserInput:     SkipUserInput,
+		handleSignals:     true,
+	}
+}
+
+// repositoryTree returns the blueprints of the active repository
+func (s *session) repositoryTree(blueprintContext *BlueprintContext) (map[string]*models.BlueprintRemote, error) {
+	if s.listBlueprints != nil {
+		return s.listBlueprints()
+	}
+	return blueprintContext.initCurrentRepoClient()
+}
+
+// confirm asks a yes/no question through the answer provider
+func (s *session) confirm(message string, defaultVal bool) (bool, error) {
+	answer, err := s.answers.Ask(Question{Type: TypeConfirm, Message: message, Default: defaultVal})
+	if err != nil {
+		return false, err
+	}
+	confirmed, _ := answer.(bool)
+	return confirmed, nil
+}
+
+// EngineOptions configures an Engine. Context is the blueprint repository to instantiate blueprints from.
+// Questions for parameters without an answer in the parameters of the run are asked from Answers, every such question
+// fails when it is not set. Messages go to Logger and are dropped when it is not set, the summary table and the dry run
+// report are written to Out, when set. When SkipFinalPrompt is set, the generation is not confirmed through Answers
+type EngineOptions struct {
+	Context         *BlueprintContext
+	Answers         AnswerProvider
+	Logger          Logger
+	Out             io.Writer
+	OverrideFns     ExpressionOverrideFn
+	SkipFinalPrompt bool
+}
+
+// Engine instantiates blueprints without relying on package level state, printing to the console or exiting the process.
+// An Engine is safe for concurrent use, each run writes through the filesystem of its own GeneratedBlueprint
+type Engine struct {
+	options   EngineOptions
+	initOnce  sync.Once
+	initErr   error
+	repoMutex sync.Mutex
+}
+
+// NewEngine returns an Engine with the given options
+func NewEngine(options EngineOptions) (*Engine, error) {
+	if options.Context == nil || options.Context.ActiveRepo == nil {
+		return nil, fmt.Errorf("a blueprint context with an active repository is needed")
+	}
+	if options.Answers == nil {
+		options.Answers = noAnswerProvider{}
+	}
+	if options.Logger == nil {
+		options.Logger = discardLogger{}
+	}
+	if options.Out == nil {
+		options.Out = ioutil.Discard
+	}
+	return &Engine{options: options}, nil
+}
+
+// Instantiate generates the blueprint with the given parameters. Output files are written through the filesystem of
+// the generated blueprint, which is the local filesystem when it is not set. Files of a failed run are rolled back
+func (engine *Engine) Instantiate(params BlueprintParams, generatedBlueprint *GeneratedBlueprint) (*PreparedData, *BlueprintConfig, error) {
+	if generatedBlueprint.Logger == nil {
+		generatedBlueprint.Logger = engine.options.Logger
+	}
+	return instantiateBlueprint(engine.newSession(), params, engine.options.Context, generatedBlueprint, engine.options.OverrideFns)
+}
+
+func (engine *Engine) newSession() *session {
+	return &session{
+		logger:            engine.options.Logger,
+		answers:           engine.options.Answers,
+		out:               engine.options.Out,
+		skipFinalPrompt:   engine.options.SkipFinalPrompt,
+		skipUpFinalPrompt: engine.options.SkipFinalPrompt,
+		listBlueprints:    engine.listBlueprints,
+	}
+}
+
+// listBlueprints lists the blueprints of the repository, repository clients keep state while listing,
+// so the repository is initialized once and listed by one run at a time
+func (engine *Engine) listBlueprints() (map[string]*models.BlueprintRemote, error) {
+	repo := *engine.options.Context.ActiveRepo
+	engine.initOnce.Do(func() {
+		engine.initErr = repo.Initialize()
+	})
+	if engine.initErr != nil {
+		return nil, engine.initErr
+	}
+	engine.repoMutex.Lock()
+	defer engine.repoMutex.Unlock()
+	engine.options.Logger.Verbose("Using active blueprint repo\n%s\n", repo.GetInfo())
+	return engine.options.Context.parseRepositoryTree()
+}
@@ -0,0 +1,264 @@
+package blueprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository/http"
+	"github.com/xebialabs/blueprint-cli/pkg/models"
+	"github.com/xebialabs/blueprint-cli/pkg/util"
+)
+
+const engineBlueprintYaml = `
+apiVersion: xl/v2
+kind: Blueprint
+metadata:
+  name: Engine
+spec:
+  parameters:
+  - name: AppName
+    type: Input
+    prompt: Application name?
+  - name: Replicas
+    type: Select
+    prompt: How many replicas?
+    options:
+    - "1"
+    - "3"
+  - name: Public
+    type: Confirm
+    prompt: Expose the application?
+  files:
+  - path: app.yaml.tmpl
+`
+
+type testAnswerProvider struct {
+	mutex     sync.Mutex
+	answers   map[string]interface{}
+	questions []Question
+}
+
+func (provider *testAnswerProvider) Ask(question Question) (interface{}, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.questions = append(provider.questions, question)
+	if answer, ok := provider.answers[question.Name]; ok {
+		return answer, nil
+	}
+	return nil, fmt.Errorf("unexpected question [%s]", question.Message)
+}
+
+type testLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (logger *testLogger) Verbose(format string, a ...interface{}) {}
+
+func (logger *testLogger) Info(format string, a ...interface{}) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	logger.messages = append(logger.messages, fmt.Sprintf(format, a...))
+}
+
+func newTestEngine(t *testing.T, options EngineOptions) *Engine {
+	repoDir, err := ioutil.TempDir("", "enginerepo")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(repoDir) })
+	writeUpgradeTestRepo(t, repoDir, map[string]string{
+		"engine/blueprint.yaml": engineBlueprintYaml,
+		"engine/app.yaml.tmpl":  "name: {{.AppName}}\nreplicas: {{.Replicas}}\npublic: {{.Public}}\n",
+	})
+	options.Context, err = ConstructLocalBlueprintContext(repoDir)
+	require.Nil(t, err)
+	engine, err := NewEngine(options)
+	require.Nil(t, err)
+	return engine
+}
+
+// newTestHttpEngine returns an engine for the engine blueprint served by a mocked HTTP repository with a v2 index
+func newTestHttpEngine(t *testing.T, options EngineOptions) *Engine {
+	const mockEndpoint = "http://mock.repo.server.com/"
+	template := "name: {{.AppName}}\nreplicas: {{.Replicas}}\npublic: {{.Public}}\n"
+	index, err := json.Marshal(repository.RepoIndex{
+		IndexVersion: repository.RepoIndexVersion,
+		Version:      "9.1.0",
+		Blueprints: []repository.BlueprintIndexEntry{{
+			Path:           "engine",
+			DefinitionFile: repository.NewIndexedFile("blueprint.yaml", []byte(engineBlueprintYaml)),
+			Metadata:       repository.BlueprintIndexMetadata{Name: "Engine"},
+			Files:          []repository.IndexedFile{repository.NewIndexedFile("app.yaml.tmpl", []byte(template))},
+		}},
+	})
+	require.Nil(t, err)
+	httpmock.Activate()
+	t.Cleanup(httpmock.DeactivateAndReset)
+	httpmock.RegisterResponder("GET", mockEndpoint+http.RepoIndexFileName, httpmock.NewBytesResponder(200, index))
+	httpmock.RegisterResponder("GET", mockEndpoint+"engine/blueprint.yaml", httpmock.NewStringResponder(200, engineBlueprintYaml))
+	httpmock.RegisterResponder("GET", mockEndpoint+"engine/app.yaml.tmpl", httpmock.NewStringResponder(200, template))
+
+	var repo repository.BlueprintRepository
+	repo, err = http.NewHttpBlueprintRepository(map[string]string{"type": models.ProviderHttp, "name": "engine", "url": mockEndpoint}, DummyCLIVersion)
+	require.Nil(t, err)
+	options.Context = &BlueprintContext{ActiveRepo: &repo, DefinedRepos: []*repository.BlueprintRepository{&repo}}
+	engine, err := NewEngine(options)
+	require.Nil(t, err)
+	return engine
+}
+
+func TestEngine_Instantiate(t *testing.T) {
+	t.Run("should ask unanswered parameters from the answer provider", func(t *testing.T) {
+		answers := &testAnswerProvider{answers: map[string]interface{}{"Replicas": "3", "Public": true}}
+		logger := &testLogger{}
+		out := &bytes.Buffer{}
+		engine := newTestEngine(t, EngineOptions{Answers: answers, Logger: logger, Out: out, SkipFinalPrompt: true})
+
+		fs := NewMemoryFS()
+		preparedData, _, err := engine.Instantiate(
+			BlueprintParams{TemplatePath: "engine", AnswersMap: map[string]string{"AppName": "my-app"}, PrintSummaryTable: true},
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: fs},
+		)
+		require.Nil(t, err)
+		assert.Equal(t, "3", preparedData.TemplateData["Replicas"])
+
+		require.Len(t, answers.questions, 2)
+		assert.Equal(t, Question{
+			Name:    "Replicas",
+			Type:    TypeSelect,
+			Message: "How many replicas?",
+			Default: "1",
+			Options: []string{"1", "3"},
+		}, withoutValidate(answers.questions[0]))
+		assert.Equal(t, "Expose the application?", answers.questions[1].Message)
+
+		content, err := fs.ReadFile("app.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, "name: my-app\nreplicas: 3\npublic: true", string(content))
+		assert.Contains(t, logger.messages, "[file] Blueprint output file 'app.yaml' generated successfully\n")
+		assert.Contains(t, out.String(), "my-app")
+	})
+
+	t.Run("should fail on unanswered parameters without an answer provider", func(t *testing.T) {
+		engine := newTestEngine(t, EngineOptions{SkipFinalPrompt: true})
+
+		fs := NewMemoryFS()
+		_, _, err := engine.Instantiate(
+			BlueprintParams{TemplatePath: "engine", AnswersMap: map[string]string{"AppName": "my-app"}},
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: fs},
+		)
+		require.NotNil(t, err)
+		assert.Equal(t, "error rendering 'How many replicas?', for the field Replicas: no answer given for parameter [Replicas]", err.Error())
+		assert.Empty(t, fs.Files())
+	})
+
+	t.Run("should confirm the generation through the answer provider", func(t *testing.T) {
+		answers := &testAnswerProvider{answers: map[string]interface{}{"": false}}
+		engine := newTestEngine(t, EngineOptions{Answers: answers})
+
+		_, _, err := engine.Instantiate(
+			BlueprintParams{TemplatePath: "engine", AnswersMap: map[string]string{"AppName": "my-app", "Replicas": "1", "Public": "false"}},
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: NewMemoryFS()},
+		)
+		require.NotNil(t, err)
+		assert.Equal(t, "blueprint generation cancelled", err.Error())
+	})
+
+	t.Run("should instantiate blueprints from concurrent goroutines", func(t *testing.T) {
+		engine := newTestEngine(t, EngineOptions{
+			Answers:         &testAnswerProvider{answers: map[string]interface{}{"Public": true}},
+			Logger:          &testLogger{},
+			SkipFinalPrompt: true,
+		})
+		instantiateConcurrently(t, engine)
+	})
+
+	t.Run("should instantiate blueprints of an HTTP repository from concurrent goroutines", func(t *testing.T) {
+		engine := newTestHttpEngine(t, EngineOptions{
+			Answers:         &testAnswerProvider{answers: map[string]interface{}{"Public": true}},
+			Logger:          &testLogger{},
+			SkipFinalPrompt: true,
+		})
+		instantiateConcurrently(t, engine)
+	})
+}
+
+// instantiateConcurrently generates the engine blueprint from several goroutines, each with its own application name
+func instantiateConcurrently(t *testing.T, engine *Engine) {
+	runs := 8
+	filesystems := make([]*MemoryFS, runs)
+	errs := make([]error, runs)
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		filesystems[i] = NewMemoryFS()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = engine.Instantiate(
+				BlueprintParams{TemplatePath: "engine", AnswersMap: map[string]string{"AppName": fmt.Sprintf("app-%d", i), "Replicas": "1"}},
+				&GeneratedBlueprint{OutputDir: "xebialabs", FS: filesystems[i]},
+			)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < runs; i++ {
+		require.Nil(t, errs[i])
+		content, err := filesystems[i].ReadFile("app.yaml")
+		require.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("name: app-%d\nreplicas: 1\npublic: true", i), string(content))
+	}
+}
+
+func TestEngine_Instantiate_Stdout(t *testing.T) {
+	logger := &testLogger{}
+	engine, err := NewEngine(EngineOptions{Context: getLocalTestBlueprintContext(t), Logger: logger, SkipFinalPrompt: true})
+	require.Nil(t, err)
+
+	// everything the package level logging functions would print, even in verbose mode
+	util.IsVerbose = true
+	defer func() { util.IsVerbose = false }()
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.Nil(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+	printed := make(chan string)
+	go func() {
+		content, _ := ioutil.ReadAll(reader)
+		printed <- string(content)
+	}()
+
+	// composed blueprints with expressions and a blueprint with the deprecated v1 schema
+	for _, templatePath := range []string{"composed", "valid-no-prompt-v1"} {
+		_, _, err := engine.Instantiate(
+			BlueprintParams{TemplatePath: templatePath, UseDefaultsAsValue: true, PrintSummaryTable: true},
+			&GeneratedBlueprint{OutputDir: "xebialabs", FS: NewMemoryFS()},
+		)
+		require.Nil(t, err)
+	}
+
+	os.Stdout = stdout
+	require.Nil(t, writer.Close())
+	assert.Empty(t, <-printed)
+	assert.Contains(t, logger.messages, fmt.Sprintf("This blueprint uses a deprecated blueprint.yaml schema for apiVersion %s\n", models.BlueprintYamlFormatV1))
+}
+
+func TestNewEngine(t *testing.T) {
+	_, err := NewEngine(EngineOptions{})
+	require.NotNil(t, err)
+	assert.Equal(t, "a blueprint context with an active repository is needed", err.Error())
+}
+
+func withoutValidate(question Question) Question {
+	question.Validate = nil
+	return question
+}
@@ -127,13 +127,14 @@ func (repo *BitbucketBlueprintRepository) ListBlueprintsFromRepo() (map[string]*
 			blueprintDirs = append(blueprintDirs, currentPath)
 
 			// Add remote definition file to blueprint
-			blueprints[currentPath].DefinitionFile = repository.GenerateBlueprintFileDefinition(
+			definitionFile := repository.GenerateBlueprintFileDefinition(
 				blueprints,
 				currentPath,
 				filename,
 				entry.Path,
 				parsedUrl,
 			)
+			blueprints[currentPath].DefinitionFile = definitionFile
 		} else {
 			if currentPath != "." && path.Dir(entry.Path) != "." {
 				// Add remote template file to blueprint
@@ -135,13 +135,14 @@ func (repo *BitbucketServerBlueprintRepository) ListBlueprintsFromRepo() (map[st
 			blueprintDirs = append(blueprintDirs, currentPath)
 
 			// Add remote definition file to blueprint
-			blueprints[currentPath].DefinitionFile = repository.GenerateBlueprintFileDefinition(
+			definitionFile := repository.GenerateBlueprintFileDefinition(
 				blueprints,
 				currentPath,
 				filename,
 				entry,
 				parsedUrl,
 			)
+			blueprints[currentPath].DefinitionFile = definitionFile
 		} else {
 			if currentPath != "." && path.Dir(entry) != "." {
 				// Add remote template file to blueprint
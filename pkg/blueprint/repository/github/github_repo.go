@@ -123,13 +123,14 @@ func (repo *GitHubBlueprintRepository) ListBlueprintsFromRepo() (map[string]*mod
 			blueprintDirs = append(blueprintDirs, currentPath)
 
 			// Add remote definition file to blueprint
-			blueprints[currentPath].DefinitionFile = repository.GenerateBlueprintFileDefinition(
+			definitionFile := repository.GenerateBlueprintFileDefinition(
 				blueprints,
 				currentPath,
 				filename,
 				entry.GetPath(),
 				parsedUrl,
 			)
+			blueprints[currentPath].DefinitionFile = definitionFile
 		} else if entry.GetType() == "tree" {
 			// pass
 		} else {
@@ -146,13 +146,14 @@ func (repo *GitLabBlueprintRepository) ListBlueprintsFromRepo() (map[string]*mod
 			blueprintDirs = append(blueprintDirs, currentPath)
 
 			// Add remote definition file to blueprint
-			blueprints[currentPath].DefinitionFile = repository.GenerateBlueprintFileDefinition(
+			definitionFile := repository.GenerateBlueprintFileDefinition(
 				blueprints,
 				currentPath,
 				filename,
 				entry.Path,
 				parsedUrl,
 			)
+			blueprints[currentPath].DefinitionFile = definitionFile
 		} else if entry.Type == "tree" {
 			// pass
 		} else {
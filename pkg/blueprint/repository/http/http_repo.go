@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
@@ -13,6 +14,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -27,6 +29,12 @@ type HttpBlueprintRepository struct {
 	RepoUrl  *url.URL
 	Username string
 	Password string
+
+	// version of the repository and SHA-256 digests of its files, known when the index is in the v2 format. They are
+	// replaced together when the repository is listed again, while files may be read concurrently
+	indexLock sync.RWMutex
+	version   string
+	digests   map[string]string
 }
 
 func NewHttpBlueprintRepository(confMap map[string]string, CLIVersion string) (*HttpBlueprintRepository, error) {
@@ -69,28 +77,45 @@ func (repo *HttpBlueprintRepository) GetProvider() string {
 }
 
 func (repo *HttpBlueprintRepository) GetInfo() string {
-	return fmt.Sprintf(
+	info := fmt.Sprintf(
 		"Provider: %s\n  Name: %s\n  Repository URL: %s\n  Username: %s",
 		repo.GetProvider(),
 		repo.Name,
 		repo.RepoUrl.String(),
 		repo.Username,
 	)
+	if version := repo.GetVersion(); version != "" {
+		info += fmt.Sprintf("\n  Repository version: %s", version)
+	}
+	return info
+}
+
+// GetVersion returns the version of the repository, which is only known when its index is in the v2 format
+func (repo *HttpBlueprintRepository) GetVersion() string {
+	repo.indexLock.RLock()
+	defer repo.indexLock.RUnlock()
+	return repo.version
 }
 
 func (repo *HttpBlueprintRepository) ListBlueprintsFromRepo() (map[string]*models.BlueprintRemote, []string, error) {
 	blueprints := make(map[string]*models.BlueprintRemote)
 	var blueprintDirs []string
 
-	// Read repository index file
+	// Read repository index file, the index of a previous listing is kept until the new one is read
 	contents, err := repo.GetFileContents(RepoIndexFileName)
 	if err != nil {
 		return nil, nil, err
 	}
+	if isRepoIndexV2(*contents) {
+		return repo.listBlueprintsFromIndex(*contents)
+	}
+
+	// Fall back to the plain array of blueprint directories
 	err = json.Unmarshal(*contents, &blueprintDirs)
 	if err != nil {
 		return nil, nil, err
 	}
+	repo.setIndex("", nil)
 
 	// Create list of blueprint remote definitions based on index file
 	for _, blueprintDir := range blueprintDirs {
@@ -125,10 +150,84 @@ func (repo *HttpBlueprintRepository) GetFileContents(filePath string) (*[]byte,
 	if err != nil {
 		return nil, err
 	}
+	if digest, ok := repo.fileDigest(filePath); ok && repository.Sha256Digest(body) != digest {
+		return nil, fmt.Errorf("SHA-256 digest of remote http file [%s] does not match the repository index", filePath)
+	}
 	return &body, nil
 }
 
 // Utility functions
+func isRepoIndexV2(contents []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(contents), []byte("{"))
+}
+
+// listBlueprintsFromIndex lists the blueprints of a v2 index, which has the definition file, files and metadata of
+// every blueprint, so no other request is needed
+func (repo *HttpBlueprintRepository) listBlueprintsFromIndex(contents []byte) (map[string]*models.BlueprintRemote, []string, error) {
+	blueprints := make(map[string]*models.BlueprintRemote)
+	var blueprintDirs []string
+
+	index := repository.RepoIndex{}
+	if err := json.Unmarshal(contents, &index); err != nil {
+		return nil, nil, err
+	}
+	if index.IndexVersion != repository.RepoIndexVersion {
+		return nil, nil, fmt.Errorf("unsupported repository index version %d", index.IndexVersion)
+	}
+
+	digests := make(map[string]string)
+	remoteFile := func(blueprintDir string, file repository.IndexedFile) models.RemoteFile {
+		filePath := path.Join(blueprintDir, file.Path)
+		if file.Sha256 != "" {
+			digests[filePath] = file.Sha256
+		}
+		return models.RemoteFile{
+			Filename: path.Base(file.Path),
+			Path:     filePath,
+		}
+	}
+	for _, entry := range index.Blueprints {
+		if entry.Path == "" || !repository.CheckIfBlueprintDefinitionFile(entry.DefinitionFile.Path) {
+			return nil, nil, fmt.Errorf("repository index has an invalid blueprint entry [%s]", entry.Path)
+		}
+		blueprint := &models.BlueprintRemote{
+			Name:           entry.Path,
+			Path:           entry.Path,
+			DefinitionFile: remoteFile(entry.Path, entry.DefinitionFile),
+			Files:          []models.RemoteFile{},
+			Metadata: &models.RemoteMetadata{
+				Name:        entry.Metadata.Name,
+				Description: entry.Metadata.Description,
+				Author:      entry.Metadata.Author,
+				Version:     entry.Metadata.Version,
+			},
+		}
+		for _, file := range entry.Files {
+			blueprint.AddFile(remoteFile(entry.Path, file))
+		}
+		blueprints[entry.Path] = blueprint
+		blueprintDirs = append(blueprintDirs, entry.Path)
+	}
+
+	repo.setIndex(index.Version, digests)
+	util.Verbose("[http-repo] Read repository index version %d of repository version %s\n", index.IndexVersion, index.Version)
+	return blueprints, blueprintDirs, nil
+}
+
+func (repo *HttpBlueprintRepository) setIndex(version string, digests map[string]string) {
+	repo.indexLock.Lock()
+	defer repo.indexLock.Unlock()
+	repo.version = version
+	repo.digests = digests
+}
+
+func (repo *HttpBlueprintRepository) fileDigest(filePath string) (string, bool) {
+	repo.indexLock.RLock()
+	defer repo.indexLock.RUnlock()
+	digest, ok := repo.digests[filePath]
+	return digest, ok
+}
+
 func (repo *HttpBlueprintRepository) checkBlueprintDefinitionFile(blueprintDir string) (string, error) {
 	for _, validExtension := range repository.BlueprintMetadataFileExtensions {
 		blueprintDefFileName := repository.BlueprintMetadataFileName + validExtension
@@ -7,6 +7,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xebialabs/blueprint-cli/pkg/blueprint/repository"
 	"github.com/xebialabs/blueprint-cli/pkg/models"
 )
 
@@ -128,6 +129,46 @@ with a new line`),
 	)
 }
 
+func mockHttpSuccessIndexV2() {
+	httpmock.Activate()
+	httpmock.RegisterResponder(
+		"GET",
+		mockEndpoint+RepoIndexFileName,
+		httpmock.NewStringResponder(200, `{
+            "indexVersion": 2,
+            "version": "9.1.0",
+            "blueprints": [
+                {
+                    "path": "aws/monolith",
+                    "definitionFile": {"path": "blueprint.yml", "size": 16, "sha256": "`+repository.Sha256Digest([]byte("sample test text"))+`"},
+                    "metadata": {"name": "Monolith", "description": "A monolith", "author": "XebiaLabs", "version": "1.0"},
+                    "files": [
+                        {"path": "test.txt", "size": 32, "sha256": "`+repository.Sha256Digest([]byte("sample test text\nwith a new line"))+`"},
+                        {"path": "config/tampered.txt", "size": 16, "sha256": "`+repository.Sha256Digest([]byte("sample test text"))+`"}
+                    ]
+                },
+                {
+                    "path": "docker/simple-demo-app",
+                    "definitionFile": {"path": "blueprint.yaml", "size": 16, "sha256": "`+repository.Sha256Digest([]byte("sample test text"))+`"},
+                    "metadata": {"name": "Demo"},
+                    "files": []
+                }
+            ]
+        }`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		mockEndpoint+"aws/monolith/test.txt",
+		httpmock.NewStringResponder(200, `sample test text
+with a new line`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		mockEndpoint+"aws/monolith/config/tampered.txt",
+		httpmock.NewStringResponder(200, `tampered text`),
+	)
+}
+
 func TestHttpBlueprintRepository_ListBlueprintsFromRepo(t *testing.T) {
 	repo, err := NewHttpBlueprintRepository(getDefaultConfMap(), DummyCLIVersion)
 	require.Nil(t, err)
@@ -152,6 +193,59 @@ func TestHttpBlueprintRepository_ListBlueprintsFromRepo(t *testing.T) {
 		require.NotNil(t, blueprints)
 		assert.Len(t, blueprints, 4)
 		assert.Len(t, blueprintDirs, 4)
+		assert.Equal(t, "", repo.GetVersion())
+	})
+
+	t.Run("should list blueprints of a v2 index with a single request", func(t *testing.T) {
+		defer httpmock.DeactivateAndReset()
+		mockHttpSuccessIndexV2()
+
+		blueprints, blueprintDirs, err := repo.ListBlueprintsFromRepo()
+		require.Nil(t, err)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+		assert.Equal(t, []string{"aws/monolith", "docker/simple-demo-app"}, blueprintDirs)
+		assert.Equal(t, "9.1.0", repo.GetVersion())
+		assert.Contains(t, repo.GetInfo(), "Repository version: 9.1.0")
+		assert.Equal(t, &models.BlueprintRemote{
+			Name:           "aws/monolith",
+			Path:           "aws/monolith",
+			DefinitionFile: models.RemoteFile{Filename: "blueprint.yml", Path: "aws/monolith/blueprint.yml"},
+			Files: []models.RemoteFile{
+				{Filename: "test.txt", Path: "aws/monolith/test.txt"},
+				{Filename: "tampered.txt", Path: "aws/monolith/config/tampered.txt"},
+			},
+			Metadata: &models.RemoteMetadata{Name: "Monolith", Description: "A monolith", Author: "XebiaLabs", Version: "1.0"},
+		}, blueprints["aws/monolith"])
+		assert.Equal(t, "blueprint.yaml", blueprints["docker/simple-demo-app"].DefinitionFile.Filename)
+		assert.Empty(t, blueprints["docker/simple-demo-app"].Files)
+	})
+
+	t.Run("should error on unsupported index version", func(t *testing.T) {
+		defer httpmock.DeactivateAndReset()
+		httpmock.Activate()
+		httpmock.RegisterResponder(
+			"GET",
+			mockEndpoint+RepoIndexFileName,
+			httpmock.NewStringResponder(200, `{"indexVersion": 3, "blueprints": []}`),
+		)
+
+		_, _, err := repo.ListBlueprintsFromRepo()
+		require.NotNil(t, err)
+		assert.Equal(t, "unsupported repository index version 3", err.Error())
+	})
+
+	t.Run("should error on a blueprint without definition file in the index", func(t *testing.T) {
+		defer httpmock.DeactivateAndReset()
+		httpmock.Activate()
+		httpmock.RegisterResponder(
+			"GET",
+			mockEndpoint+RepoIndexFileName,
+			httpmock.NewStringResponder(200, `{"indexVersion": 2, "blueprints": [{"path": "aws/monolith", "definitionFile": {"path": "README.md"}}]}`),
+		)
+
+		_, _, err := repo.ListBlueprintsFromRepo()
+		require.NotNil(t, err)
+		assert.Equal(t, "repository index has an invalid blueprint entry [aws/monolith]", err.Error())
 	})
 }
 
@@ -183,6 +277,21 @@ func TestHttpBlueprintRepository_GetFileContents(t *testing.T) {
 		require.NotNil(t, content)
 		assert.Equal(t, "sample test text\nwith a new line", string(*content))
 	})
+
+	t.Run("should verify the SHA-256 digests of the files in a v2 index", func(t *testing.T) {
+		defer httpmock.DeactivateAndReset()
+		mockHttpSuccessIndexV2()
+		_, _, err := repo.ListBlueprintsFromRepo()
+		require.Nil(t, err)
+
+		content, err := repo.GetFileContents("aws/monolith/test.txt")
+		require.Nil(t, err)
+		assert.Equal(t, "sample test text\nwith a new line", string(*content))
+
+		_, err = repo.GetFileContents("aws/monolith/config/tampered.txt")
+		require.NotNil(t, err)
+		assert.Equal(t, "SHA-256 digest of remote http file [aws/monolith/config/tampered.txt] does not match the repository index", err.Error())
+	})
 }
 
 func TestHttpBlueprintRepository_checkBlueprintDefinitionFile(t *testing.T) {
@@ -132,7 +132,9 @@ func (repo *LocalBlueprintRepository) ListBlueprintsFromRepo() (map[string]*mode
 	blueprints := make(map[string]*models.BlueprintRemote)
 	var blueprintDirs []string
 
-	// walk root directory
+	// walk root directory, starting from scratch when the repository is listed again
+	repo.LocalFiles = []string{}
+	repo.BlueprintDirs = []string{}
 	err := filepath.Walk(repo.Path, repo.traversePath)
 	if err != nil {
 		return nil, nil, err
@@ -144,15 +146,18 @@ func (repo *LocalBlueprintRepository) ListBlueprintsFromRepo() (map[string]*mode
 			// if local file is within any valid blueprint directory
 			filename := filepath.Base(file)
 			currentPath, _ := filepath.Rel(repo.Path, blueprintDir)
-			filePath := filepath.Join(currentPath, filename)
+			// keep sub directories of the blueprint in the file path
+			filePath, _ := filepath.Rel(repo.Path, file)
 			if repository.CheckIfBlueprintDefinitionFile(filename) {
-				blueprints[currentPath].DefinitionFile = repository.GenerateBlueprintFileDefinition(
+				// the map entry is created by GenerateBlueprintFileDefinition, so it has to run before the assignment
+				definitionFile := repository.GenerateBlueprintFileDefinition(
 					blueprints,
 					currentPath,
 					filename,
 					filePath,
 					nil,
 				)
+				blueprints[currentPath].DefinitionFile = definitionFile
 				blueprintDirs = append(blueprintDirs, currentPath)
 			} else {
 				fileDef := repository.GenerateBlueprintFileDefinition(blueprints, currentPath, filename, filePath, nil)
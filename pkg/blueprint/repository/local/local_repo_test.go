@@ -124,6 +124,27 @@ func TestListBlueprintsFromRepo(t *testing.T) {
 		assert.Len(t, validNoPromptBlueprint.Files, 5)
 	})
 
+	t.Run("should keep sub directories in file paths and not duplicate files when listed again", func(t *testing.T) {
+		repo, err := NewLocalBlueprintRepository(map[string]string{
+			"name": "test",
+			"type": repoType,
+			"path": blueprintDir,
+		})
+		require.Nil(t, err)
+		_, _, err = repo.ListBlueprintsFromRepo()
+		require.Nil(t, err)
+		blueprints, _, err := repo.ListBlueprintsFromRepo()
+		require.Nil(t, err)
+
+		answerInputBlueprint := blueprints["answer-input"]
+		require.Len(t, answerInputBlueprint.Files, 4)
+		var paths []string
+		for _, file := range answerInputBlueprint.Files {
+			paths = append(paths, file.Path)
+		}
+		assert.Contains(t, paths, filepath.Join("answer-input", "__test__", "test-case-1.yaml"))
+	})
+
 	t.Run("should list empty blueprints list from local dir", func(t *testing.T) {
 		repo, err := NewLocalBlueprintRepository(map[string]string{
 			"name": "test",
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// RepoIndexVersion is the version of the repository index format with blueprint metadata, file lists and checksums.
+// The first format is a plain JSON array of blueprint directories
+const RepoIndexVersion = 2
+
+// RepoIndex is the index of a published blueprint repository
+type RepoIndex struct {
+	IndexVersion int                   `json:"indexVersion"`
+	Version      string                `json:"version"`
+	Blueprints   []BlueprintIndexEntry `json:"blueprints"`
+}
+
+// BlueprintIndexEntry is a blueprint of a repository index, the paths of its files are relative to the blueprint Path
+type BlueprintIndexEntry struct {
+	Path           string                 `json:"path"`
+	DefinitionFile IndexedFile            `json:"definitionFile"`
+	Metadata       BlueprintIndexMetadata `json:"metadata"`
+	Files          []IndexedFile          `json:"files"`
+}
+
+// BlueprintIndexMetadata is the metadata of a blueprint as found in its definition
+type BlueprintIndexMetadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+// IndexedFile is a file of a blueprint with the hex encoded SHA-256 digest of its content
+type IndexedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// NewIndexedFile returns the indexed file for the given path and content
+func NewIndexedFile(path string, content []byte) IndexedFile {
+	return IndexedFile{
+		Path:   path,
+		Size:   int64(len(content)),
+		Sha256: Sha256Digest(content),
+	}
+}
+
+// Sha256Digest returns the hex encoded SHA-256 digest of the content
+func Sha256Digest(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
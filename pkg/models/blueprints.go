@@ -24,6 +24,16 @@ type BlueprintRemote struct {
 	Path           string
 	DefinitionFile RemoteFile
 	Files          []RemoteFile
+	// Metadata is set when the repository provides it without reading the definition file
+	Metadata *RemoteMetadata
+}
+
+// Blueprint metadata as provided by a repository index
+type RemoteMetadata struct {
+	Name        string
+	Description string
+	Author      string
+	Version     string
 }
 
 func NewBlueprintRemote(name string, path string) *BlueprintRemote {
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// UnifiedDiff returns the unified diff of two texts with 3 lines of context, or an empty string when they are the same
+func UnifiedDiff(from, to string, fromFile, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(from),
+		B:        diffLines(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// diffLines splits the text in lines, a missing newline at the end is marked the same way as diff does
+func diffLines(text string) []string {
+	lines := SplitLinesKeepEOL(text)
+	if last := len(lines) - 1; last >= 0 && !strings.HasSuffix(lines[last], "\n") {
+		lines[last] += "\n" + noNewlineMarker
+	}
+	return lines
+}
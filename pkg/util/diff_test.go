@@ -0,0 +1,28 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Run("should return unified diff of changed lines", func(t *testing.T) {
+		diff, err := UnifiedDiff("a\nb\nc\n", "a\nB\nc\n", "current", "blueprint")
+		require.Nil(t, err)
+		assert.Equal(t, "--- current\n+++ blueprint\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", diff)
+	})
+
+	t.Run("should mark missing newline at end of file", func(t *testing.T) {
+		diff, err := UnifiedDiff("a\nb", "a\nb\n", "current", "blueprint")
+		require.Nil(t, err)
+		assert.Equal(t, "--- current\n+++ blueprint\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n", diff)
+	})
+
+	t.Run("should return empty diff for same texts", func(t *testing.T) {
+		diff, err := UnifiedDiff("a\nb\n", "a\nb\n", "current", "blueprint")
+		require.Nil(t, err)
+		assert.Equal(t, "", diff)
+	})
+}
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Conflict markers written by MergeText
+const (
+	ConflictMarkerStart  = "<<<<<<<"
+	ConflictMarkerMiddle = "======="
+	ConflictMarkerEnd    = ">>>>>>>"
+)
+
+// MergeText does a line based three-way merge of two texts derived from a common base.
+// Changes done on only one side are taken as-is, conflicting changes are wrapped with conflict markers
+// using the given labels. The returned flag is true when the merged text contains conflicts.
+func MergeText(base, ours, theirs string, oursLabel, theirsLabel string) (string, bool) {
+	baseLines, ourLines, theirLines := SplitLinesKeepEOL(base), SplitLinesKeepEOL(ours), SplitLinesKeepEOL(theirs)
+
+	merged := &strings.Builder{}
+	hasConflicts := false
+	iBase, iOurs, iTheirs := 0, 0, 0
+	for _, region := range findSyncRegions(baseLines, ourLines, theirLines) {
+		if region.oursStart > iOurs || region.theirsStart > iTheirs {
+			ourChunk := ourLines[iOurs:region.oursStart]
+			theirChunk := theirLines[iTheirs:region.theirsStart]
+			baseChunk := baseLines[iBase:region.baseStart]
+
+			switch {
+			case equalLines(ourChunk, theirChunk), equalLines(theirChunk, baseChunk):
+				writeLines(merged, ourChunk)
+			case equalLines(ourChunk, baseChunk):
+				writeLines(merged, theirChunk)
+			default:
+				hasConflicts = true
+				merged.WriteString(ConflictMarkerStart + " " + oursLabel + "\n")
+				writeLinesWithEOL(merged, ourChunk)
+				merged.WriteString(ConflictMarkerMiddle + "\n")
+				writeLinesWithEOL(merged, theirChunk)
+				merged.WriteString(ConflictMarkerEnd + " " + theirsLabel + "\n")
+			}
+			iOurs, iTheirs = region.oursStart, region.theirsStart
+		}
+		iBase = region.baseStart
+
+		// lines in sync on all three sides
+		if region.length > 0 {
+			writeLines(merged, baseLines[region.baseStart:region.baseStart+region.length])
+			iBase += region.length
+			iOurs = region.oursStart + region.length
+			iTheirs = region.theirsStart + region.length
+		}
+	}
+	return merged.String(), hasConflicts
+}
+
+// SplitLinesKeepEOL splits the text into lines, keeping the line endings
+func SplitLinesKeepEOL(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type syncRegion struct {
+	baseStart   int
+	oursStart   int
+	theirsStart int
+	length      int
+}
+
+// findSyncRegions returns the regions where base, ours and theirs all have the same lines,
+// ending with an empty region at the end of all three texts
+func findSyncRegions(baseLines, ourLines, theirLines []string) []syncRegion {
+	ourMatches := difflib.NewMatcherWithJunk(baseLines, ourLines, false, nil).GetMatchingBlocks()
+	theirMatches := difflib.NewMatcherWithJunk(baseLines, theirLines, false, nil).GetMatchingBlocks()
+
+	var regions []syncRegion
+	iOurs, iTheirs := 0, 0
+	for iOurs < len(ourMatches) && iTheirs < len(theirMatches) {
+		ourMatch, theirMatch := ourMatches[iOurs], theirMatches[iTheirs]
+
+		// intersection of the matching base ranges
+		start := maxInt(ourMatch.A, theirMatch.A)
+		end := minInt(ourMatch.A+ourMatch.Size, theirMatch.A+theirMatch.Size)
+		if start < end {
+			regions = append(regions, syncRegion{
+				baseStart:   start,
+				oursStart:   ourMatch.B + start - ourMatch.A,
+				theirsStart: theirMatch.B + start - theirMatch.A,
+				length:      end - start,
+			})
+		}
+
+		if ourMatch.A+ourMatch.Size < theirMatch.A+theirMatch.Size {
+			iOurs++
+		} else {
+			iTheirs++
+		}
+	}
+	return append(regions, syncRegion{baseStart: len(baseLines), oursStart: len(ourLines), theirsStart: len(theirLines)})
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
+
+// writeLinesWithEOL writes the lines making sure the last one is terminated, so that a conflict marker can follow
+func writeLinesWithEOL(sb *strings.Builder, lines []string) {
+	writeLines(sb, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		sb.WriteString("\n")
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
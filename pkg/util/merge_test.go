@@ -0,0 +1,74 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeText(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts bool
+	}{
+		{
+			"take changes from both sides when they do not overlap",
+			"a\nb\nc\nd\ne\n",
+			"a\nB\nc\nd\ne\n",
+			"a\nb\nc\nD\ne\nf\n",
+			"a\nB\nc\nD\ne\nf\n",
+			false,
+		},
+		{
+			"take the same change made on both sides once",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"a\nB\nc\n",
+			"a\nB\nc\n",
+			false,
+		},
+		{
+			"take lines removed on one side",
+			"a\nb\nc\n",
+			"a\nc\n",
+			"a\nb\nc\nd\n",
+			"a\nc\nd\n",
+			false,
+		},
+		{
+			"mark conflicting changes",
+			"a\nb\nc\n",
+			"a\nours\nc\n",
+			"a\ntheirs\nc\n",
+			"a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> blueprint\nc\n",
+			true,
+		},
+		{
+			"mark conflicts on the last line without line ending",
+			"a\nb",
+			"a\nours",
+			"a\ntheirs",
+			"a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> blueprint\n",
+			true,
+		},
+		{
+			"mark conflicts when there is no common base",
+			"",
+			"a\n",
+			"b\n",
+			"<<<<<<< current\na\n=======\nb\n>>>>>>> blueprint\n",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotConflicts := MergeText(tt.base, tt.ours, tt.theirs, "current", "blueprint")
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantConflicts, gotConflicts)
+		})
+	}
+}
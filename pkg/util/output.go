@@ -0,0 +1,70 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xebialabs/yaml"
+)
+
+// Output format constants used by commands printing structured data
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
+var OutputFormats = []string{OutputFormatTable, OutputFormatJSON, OutputFormatYAML}
+
+// ValidateOutputFormat checks if the given format is one of the supported output formats
+func ValidateOutputFormat(format string) error {
+	if !IsStringInSlice(strings.ToLower(format), OutputFormats) {
+		return fmt.Errorf("output format [%s] is not supported, supported formats are %v", format, OutputFormats)
+	}
+	return nil
+}
+
+// WriteFormatted writes data to the writer as JSON or YAML, or calls writeTable with a tab writer for table format
+func WriteFormatted(w io.Writer, format string, data interface{}, writeTable func(tw *tabwriter.Writer)) error {
+	switch strings.ToLower(format) {
+	case OutputFormatJSON:
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case OutputFormatYAML:
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	case OutputFormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		writeTable(tw)
+		return tw.Flush()
+	}
+	return ValidateOutputFormat(format)
+}
+
+// IsStructuredOutputFormat checks if the format is meant to be parsed by other tools
+func IsStructuredOutputFormat(format string) bool {
+	format = strings.ToLower(format)
+	return format == OutputFormatJSON || format == OutputFormatYAML
+}
+
+// TableCell flattens a value to be printed on a single table row, truncating it if it is longer than maxWidth (when set)
+func TableCell(val string, maxWidth int) string {
+	val = strings.Replace(val, "\r", "", -1)
+	val = strings.Replace(strings.TrimSpace(val), "\n", " ", -1)
+	// truncate on runes, cutting bytes would break multi-byte characters
+	if runes := []rune(val); maxWidth > 2 && len(runes) > maxWidth {
+		val = string(runes[:maxWidth-2]) + ".."
+	}
+	return val
+}
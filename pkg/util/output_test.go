@@ -0,0 +1,69 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outputTestItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+func TestWriteFormatted(t *testing.T) {
+	items := []outputTestItem{{"a", "1.0"}, {"b", "2.0"}}
+	writeTable := func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "NAME\tVERSION")
+		for _, item := range items {
+			fmt.Fprintf(tw, "%s\t%s\n", item.Name, item.Version)
+		}
+	}
+
+	t.Run("should write items as table", func(t *testing.T) {
+		var b bytes.Buffer
+		err := WriteFormatted(&b, OutputFormatTable, items, writeTable)
+		require.Nil(t, err)
+		assert.Equal(t, "NAME   VERSION\na      1.0\nb      2.0\n", b.String())
+	})
+
+	t.Run("should write items as JSON", func(t *testing.T) {
+		var b bytes.Buffer
+		err := WriteFormatted(&b, "JSON", items, writeTable)
+		require.Nil(t, err)
+		assert.JSONEq(t, `[{"name":"a","version":"1.0"},{"name":"b","version":"2.0"}]`, b.String())
+	})
+
+	t.Run("should write items as YAML", func(t *testing.T) {
+		var b bytes.Buffer
+		err := WriteFormatted(&b, OutputFormatYAML, items, writeTable)
+		require.Nil(t, err)
+		assert.Equal(t, "- name: a\n  version: \"1.0\"\n- name: b\n  version: \"2.0\"\n", b.String())
+	})
+
+	t.Run("should error on unknown format", func(t *testing.T) {
+		var b bytes.Buffer
+		err := WriteFormatted(&b, "xml", items, writeTable)
+		require.NotNil(t, err)
+		assert.Equal(t, "output format [xml] is not supported, supported formats are [table json yaml]", err.Error())
+	})
+}
+
+func TestTableCell(t *testing.T) {
+	t.Run("should flatten multi-line values", func(t *testing.T) {
+		assert.Equal(t, "line 1 line 2", TableCell(" line 1\r\nline 2\n", 0))
+		assert.Equal(t, "", TableCell("", 10))
+	})
+	t.Run("should truncate long values", func(t *testing.T) {
+		assert.Equal(t, "a long..", TableCell("a long description", 8))
+		assert.Equal(t, "short", TableCell("short", 8))
+	})
+	t.Run("should truncate multi-byte characters as a whole", func(t *testing.T) {
+		assert.Equal(t, "Ünïcö..", TableCell("Ünïcödé déscription", 7))
+		assert.Equal(t, "日本語", TableCell("日本語", 3))
+	})
+}